@@ -0,0 +1,144 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cmd contains the CLI commands for UDS.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/config/lang"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
+)
+
+var cachePruneOpts struct {
+	olderThan time.Duration
+	maxSizeMB int
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: lang.CmdCacheShort,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Args:    cobra.NoArgs,
+	Short:   lang.CmdCacheLsShort,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		layers, err := cache.List()
+		if err != nil {
+			return fmt.Errorf("failed to list the cache: %s", err.Error())
+		}
+		if len(layers) == 0 {
+			message.Warnf("No layers found in the cache at %s", config.CommonOptions.CachePath)
+			return nil
+		}
+		rows := make([][]string, 0, len(layers))
+		for _, layer := range layers {
+			rows = append(rows, []string{layer.Digest, zarfUtils.ByteFormat(float64(layer.Size), 2), layer.LastUsed.UTC().Format(time.RFC3339)})
+		}
+		// print to stdout to enable users to easily grab the output
+		pterm.SetDefaultOutput(os.Stdout)
+		defer pterm.SetDefaultOutput(os.Stderr)
+		message.Table([]string{"Digest", "Size", "Last Used"}, rows)
+		return nil
+	},
+}
+
+var cacheSizeCmd = &cobra.Command{
+	Use:   "size",
+	Args:  cobra.NoArgs,
+	Short: lang.CmdCacheSizeShort,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		count, size, err := cache.Size()
+		if err != nil {
+			return fmt.Errorf("failed to size the cache: %s", err.Error())
+		}
+		message.Infof("The cache at %s holds %d layers totaling %s", config.CommonOptions.CachePath, count, zarfUtils.ByteFormat(float64(size), 2))
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Args:  cobra.NoArgs,
+	Short: lang.CmdCachePruneShort,
+	Long:  lang.CmdCachePruneLong,
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		if cachePruneOpts.olderThan < 0 || cachePruneOpts.maxSizeMB < 0 {
+			return errors.New("--older-than and --max-size must not be negative")
+		}
+		if cachePruneOpts.olderThan == 0 && cachePruneOpts.maxSizeMB == 0 {
+			return errors.New("at least one of --older-than or --max-size is required")
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, _ []string) error {
+		pruned, err := cache.Prune(cachePruneOpts.olderThan, int64(cachePruneOpts.maxSizeMB)*config.MB)
+		if err != nil {
+			return fmt.Errorf("failed to prune the cache: %s", err.Error())
+		}
+		var size int64
+		for _, layer := range pruned {
+			size += layer.Size
+		}
+		message.Successf("Pruned %d layers totaling %s from the cache", len(pruned), zarfUtils.ByteFormat(float64(size), 2))
+		return nil
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Args:  cobra.NoArgs,
+	Short: lang.CmdCacheVerifyShort,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		spinner := message.NewProgressSpinner("Verifying the layers in the cache at %s", config.CommonOptions.CachePath)
+		defer spinner.Stop()
+		corrupt, err := cache.Verify()
+		if err != nil {
+			return fmt.Errorf("failed to verify the cache: %s", err.Error())
+		}
+		for _, layer := range corrupt {
+			message.Warnf("Removed corrupt layer %s", layer.Digest)
+		}
+		spinner.Successf("Verified the cache, removed %d corrupt layers", len(corrupt))
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Args:  cobra.NoArgs,
+	Short: lang.CmdCacheClearShort,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if err := cache.Clear(); err != nil {
+			return fmt.Errorf("failed to clear the cache: %s", err.Error())
+		}
+		message.Successf("Cleared the cache at %s", config.CommonOptions.CachePath)
+		return nil
+	},
+}
+
+func init() {
+	initViper()
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cacheSizeCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().DurationVar(&cachePruneOpts.olderThan, "older-than", 0, lang.CmdCachePruneFlagOlderThan)
+	cachePruneCmd.Flags().IntVar(&cachePruneOpts.maxSizeMB, "max-size", 0, lang.CmdCachePruneFlagMaxSize)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheClearCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdCacheClearFlagConfirm)
+	_ = cacheClearCmd.MarkFlagRequired("confirm")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,3 +51,93 @@ func TestSettingArchitecture(t *testing.T) {
 		})
 	}
 }
+
+func Test_setArchitecture(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     *cobra.Command
+		cliArch string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "no architecture",
+			cmd:     deployCmd,
+			cliArch: "",
+			want:    "",
+		},
+		{
+			name:    "single architecture",
+			cmd:     pullCmd,
+			cliArch: " arm64,",
+			want:    "arm64",
+		},
+		{
+			name:    "duplicate architectures",
+			cmd:     publishCmd,
+			cliArch: "amd64, amd64",
+			want:    "amd64",
+		},
+		{
+			name:    "multiple architectures",
+			cmd:     inspectCmd,
+			cliArch: "amd64,arm64",
+			wantErr: true,
+		},
+		{
+			name:    "multiple architectures for create",
+			cmd:     createCmd,
+			cliArch: "amd64,arm64",
+			want:    "amd64,arm64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliArch := config.CLIArch
+			t.Cleanup(func() { config.CLIArch = cliArch })
+			config.CLIArch = tt.cliArch
+
+			err := setArchitecture(tt.cmd)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, config.CLIArch)
+		})
+	}
+}
+
+func TestParseArchitectures(t *testing.T) {
+	tests := []struct {
+		name     string
+		archList string
+		want     []string
+	}{
+		{
+			name:     "no architecture",
+			archList: "",
+			want:     nil,
+		},
+		{
+			name:     "single architecture",
+			archList: "arm64",
+			want:     []string{"arm64"},
+		},
+		{
+			name:     "multiple architectures",
+			archList: "amd64,arm64",
+			want:     []string{"amd64", "arm64"},
+		},
+		{
+			name:     "whitespace and duplicates",
+			archList: " amd64, arm64,amd64,",
+			want:     []string{"amd64", "arm64"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, config.ParseArchitectures(tt.archList))
+		})
+	}
+}
@@ -32,6 +32,7 @@ const (
 	confirm        configOption = "confirm"
 	insecure       configOption = "insecure"
 	cachePath      configOption = "uds_cache"
+	cachePolicy    configOption = "cache_policy"
 	tempDirectory  configOption = "tmp_dir"
 	logLevelOption configOption = "log_level"
 	architecture   configOption = "architecture"
@@ -39,12 +40,14 @@ const (
 	noProgress     configOption = "no_progress"
 	noColor        configOption = "no_color"
 	ociConcurrency configOption = "oci_concurrency"
+	trustedKeys    configOption = "trusted_keys"
+	requireSigned  configOption = "require_signed"
 )
 
 // isValidConfigOption checks if a string is a valid config option
 func isValidConfigOption(str string) bool {
 	switch configOption(str) {
-	case confirm, insecure, cachePath, tempDirectory, logLevelOption, architecture, noLogFile, noProgress, noColor, ociConcurrency:
+	case confirm, insecure, cachePath, cachePolicy, tempDirectory, logLevelOption, architecture, noLogFile, noProgress, noColor, ociConcurrency, trustedKeys, requireSigned:
 		return true
 	default:
 		return false
@@ -145,6 +148,16 @@ func cliSetup(cmd *cobra.Command) error {
 		return err
 	}
 
+	switch config.CommonOptions.CachePolicy {
+	case config.CachePolicyAll, config.CachePolicyImages, config.CachePolicyNone:
+	default:
+		return fmt.Errorf("invalid cache policy %q, must be one of %s, %s or %s", config.CommonOptions.CachePolicy, config.CachePolicyAll, config.CachePolicyImages, config.CachePolicyNone)
+	}
+
+	if err := setArchitecture(cmd); err != nil {
+		return err
+	}
+
 	// This is using the same logger as Zarf, uds-cli could also make it's own logger
 	ctx = logger.WithContext(ctx, l)
 	// Sets the context on the command, to be inherited by other commands. We do this so that we can pass
@@ -163,6 +176,22 @@ func cliSetup(cmd *cobra.Command) error {
 	return nil
 }
 
+// setArchitecture validates the architecture from --architecture or uds-config for cmd. Only create accepts a
+// comma-separated list of architectures, for every other command it's trimmed to the single architecture that
+// config.GetArch returns
+func setArchitecture(cmd *cobra.Command) error {
+	archs := config.ParseArchitectures(config.CLIArch)
+	switch {
+	case len(archs) > 1 && cmd != createCmd:
+		return fmt.Errorf("%s accepts a single architecture, got %q; only uds create accepts a comma-separated list", cmd.CommandPath(), config.CLIArch)
+	case len(archs) == 1:
+		config.CLIArch = archs[0]
+	case len(archs) == 0:
+		config.CLIArch = ""
+	}
+	return nil
+}
+
 // stringToMessageLogLevel converts a string log level to message.LogLevel type
 func stringToMessageLogLevel(level string) message.LogLevel {
 	switch strings.ToLower(level) {
@@ -14,6 +14,7 @@ func TestValidConfigOptions(t *testing.T) {
 		"confirm",
 		"insecure",
 		"uds_cache",
+		"cache_policy",
 		"tmp_dir",
 		"log_level",
 		"architecture",
@@ -21,6 +22,8 @@ func TestValidConfigOptions(t *testing.T) {
 		"no_progress",
 		"oci_concurrency",
 		"no_color",
+		"trusted_keys",
+		"require_signed",
 	}
 
 	for _, option := range options {
@@ -14,6 +14,7 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
 
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -105,8 +106,12 @@ var devDeployCmd = &cobra.Command{
 	},
 }
 
-// isLocalBundle checks if the bundle source is a local bundle
+// isLocalBundle checks if the bundle source is a local bundle that needs to be created, pulled bundles saved as an OCI
+// layout dir are deployed as is
 func isLocalBundle(src string) bool {
+	if utils.IsValidBundleDir(src) {
+		return false
+	}
 	return helpers.IsDir(src) || strings.Contains(src, ".tar.zst")
 }
 
@@ -115,7 +120,7 @@ func validateDevDeployFlags(isLocalBundle bool) error {
 	if !isLocalBundle {
 		// Throw error if trying to run with --flavor or --force-create flag with remote bundle
 		if len(bundleCfg.DevDeployOpts.Flavor) > 0 || bundleCfg.DevDeployOpts.ForceCreate {
-			return errors.New("cannot use --flavor or --force-create flags with remote bundle")
+			return errors.New("cannot use --flavor or --force-create flags with a remote bundle or an OCI layout dir")
 		}
 	}
 	return nil
@@ -5,9 +5,12 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,6 +70,10 @@ func TestValidateDevDeployFlags(t *testing.T) {
 }
 
 func TestIsLocalBundle(t *testing.T) {
+	ociLayoutDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(ociLayoutDir, ocispec.ImageLayoutFile), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(ociLayoutDir, ocispec.ImageIndexFile), []byte(`{"schemaVersion":2}`), 0o600))
+
 	testCases := []struct {
 		name string
 		src  string
@@ -77,6 +84,11 @@ func TestIsLocalBundle(t *testing.T) {
 			src:  "../cmd/",
 			want: true,
 		},
+		{
+			name: "Test with OCI layout dir",
+			src:  ociLayoutDir,
+			want: false,
+		},
 		{
 			name: "Test with .tar.zst file",
 			src:  "/path/to/file.tar.zst",
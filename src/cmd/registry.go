@@ -0,0 +1,77 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cmd contains the CLI commands for UDS.
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/config/lang"
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
+	"github.com/spf13/cobra"
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: lang.CmdRegistryShort,
+}
+
+var registryLsCmd = &cobra.Command{
+	Use:     "ls [OCI_REF]",
+	Aliases: []string{"list"},
+	Args:    cobra.ExactArgs(1),
+	Short:   lang.CmdRegistryLsShort,
+	Long:    lang.CmdRegistryLsLong,
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.RegistryOpts.Source = args[0]
+		configureZarf()
+
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.RegistryList(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to list bundles: %s", err.Error())
+		}
+		return nil
+	},
+}
+
+var registryRmCmd = &cobra.Command{
+	Use:     "rm [OCI_REF]",
+	Aliases: []string{"remove"},
+	Args:    cobra.ExactArgs(1),
+	Short:   lang.CmdRegistryRmShort,
+	Long:    lang.CmdRegistryRmLong,
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.RegistryOpts.Source = args[0]
+		configureZarf()
+
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.RegistryRemove(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to remove bundle: %s", err.Error())
+		}
+		return nil
+	},
+}
+
+func init() {
+	initViper()
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryLsCmd)
+	registryCmd.AddCommand(registryRmCmd)
+	registryRmCmd.Flags().StringVar(&bundleCfg.RegistryOpts.Arch, "arch", "", lang.CmdRegistryRmFlagArch)
+	registryRmCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdRegistryRmFlagConfirm)
+	_ = registryRmCmd.MarkFlagRequired("confirm")
+}
@@ -10,15 +10,22 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
+	"github.com/defenseunicorns/uds-cli/src/pkg/scan"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/spf13/cobra"
 
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/message"
+	"oras.land/oras-go/v2/registry"
 )
 
 var createCmd = &cobra.Command{
@@ -31,6 +38,18 @@ var createCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if bundleCfg.CreateOpts.MaxPackageSizeMB < 0 {
+			return errors.New("--max-package-size must not be negative")
+		}
+		if bundleCfg.CreateOpts.MaxPackageSizeMB > 0 && utils.IsRegistryURL(bundleCfg.CreateOpts.Output) {
+			return errors.New("--max-package-size can only be used when creating a bundle tarball")
+		}
+		if bundleCfg.CreateOpts.Base != "" && utils.IsRegistryURL(bundleCfg.CreateOpts.Output) {
+			return errors.New("--base can only be used when creating a bundle tarball")
+		}
+		if bundleCfg.CreateOpts.DeltaFrom != "" && utils.IsRegistryURL(bundleCfg.CreateOpts.Output) {
+			return errors.New("--delta-from can only be used when creating a bundle tarball")
+		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -62,7 +81,7 @@ var createCmd = &cobra.Command{
 }
 
 var deployCmd = &cobra.Command{
-	Use:     "deploy [BUNDLE_TARBALL|OCI_REF]",
+	Use:     "deploy [BUNDLE_TARBALL|BUNDLE_DIR|OCI_REF]",
 	Aliases: []string{"d"},
 	Short:   lang.CmdBundleDeployShort,
 	Args:    cobra.MaximumNArgs(1),
@@ -95,7 +114,7 @@ var deployCmd = &cobra.Command{
 }
 
 var inspectCmd = &cobra.Command{
-	Use:     "inspect [BUNDLE_TARBALL|OCI_REF|BUNDLE_YAML_FILE]",
+	Use:     "inspect [BUNDLE_TARBALL|BUNDLE_DIR|OCI_REF|BUNDLE_YAML_FILE]",
 	Aliases: []string{"i"},
 	Short:   lang.CmdBundleInspectShort,
 	Args:    cobra.MaximumNArgs(1),
@@ -103,6 +122,33 @@ var inspectCmd = &cobra.Command{
 		if cmd.Flag("extract").Value.String() == "true" && cmd.Flag("sbom").Value.String() == "false" {
 			return errors.New("cannot use 'extract' flag without 'sbom' flag")
 		}
+		licenses := cmd.Flag("licenses").Value.String() == "true"
+		if licenses && cmd.Flag("sbom").Value.String() == "true" {
+			return errors.New("cannot use 'licenses' flag with 'sbom' flag")
+		}
+		if cmd.Flag("deny-license").Changed && !licenses {
+			return errors.New("cannot use 'deny-license' flag without 'licenses' flag")
+		}
+		if cmd.Flag("size").Value.String() == "true" && (licenses || cmd.Flag("sbom").Value.String() == "true") {
+			return errors.New("cannot use 'size' flag with 'licenses' or 'sbom' flag")
+		}
+		if format := cmd.Flag("format").Value.String(); format != "" {
+			if licenses {
+				if !slices.Contains(sbom.LicenseFormats, format) {
+					return fmt.Errorf("invalid license report format %q, must be one of %s", format, strings.Join(sbom.LicenseFormats, ", "))
+				}
+				return nil
+			}
+			if cmd.Flag("sbom").Value.String() == "false" {
+				return errors.New("cannot use 'format' flag without 'sbom' or 'licenses' flag")
+			}
+			if cmd.Flag("extract").Value.String() == "true" {
+				return errors.New("cannot use 'format' flag with 'extract' flag")
+			}
+			if !slices.Contains(sbom.Formats, format) {
+				return fmt.Errorf("invalid SBOM format %q, must be one of %s", format, strings.Join(sbom.Formats, ", "))
+			}
+		}
 		return nil
 	},
 	RunE: func(_ *cobra.Command, args []string) error {
@@ -128,7 +174,7 @@ var inspectCmd = &cobra.Command{
 }
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [BUNDLE_TARBALL|OCI_REF]",
+	Use:     "remove [BUNDLE_TARBALL|BUNDLE_DIR|OCI_REF]",
 	Aliases: []string{"r"},
 	Args:    cobra.ExactArgs(1),
 	Short:   lang.CmdBundleRemoveShort,
@@ -151,18 +197,23 @@ var removeCmd = &cobra.Command{
 }
 
 var publishCmd = &cobra.Command{
-	Use:     "publish [BUNDLE_TARBALL] [OCI_REF]",
+	Use:     "publish [BUNDLE_TARBALL|BUNDLE_DIR|OCI_REF] [OCI_REF]",
 	Aliases: []string{"p"},
 	Short:   lang.CmdPublishShort,
 	Args:    cobra.ExactArgs(2),
 	PreRunE: func(_ *cobra.Command, args []string) error {
-		if _, err := os.Stat(args[0]); err != nil {
-			return fmt.Errorf("first argument (%q) must be a valid local Bundle path: %s", args[0], err.Error())
+		if _, err := os.Stat(args[0]); err != nil && !helpers.IsOCIURL(args[0]) {
+			return fmt.Errorf("first argument (%q) must be a valid local Bundle path or an oci:// ref: %s", args[0], err.Error())
 		}
 
 		if bundleCfg.PublishOpts.Version != "" {
 			message.Warnf("the --version flag is deprecated and will be removed in a future version")
 		}
+		for _, tag := range bundleCfg.PublishOpts.Tags {
+			if err := (registry.Reference{Reference: tag}).ValidateReferenceAsTag(); err != nil {
+				return fmt.Errorf("invalid tag %q: %s", tag, err.Error())
+			}
+		}
 		return nil
 	},
 	RunE: func(_ *cobra.Command, args []string) error {
@@ -188,6 +239,19 @@ var pullCmd = &cobra.Command{
 	Aliases: []string{"p"},
 	Short:   lang.CmdBundlePullShort,
 	Args:    cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		formats := []string{config.BundleFormatTarball, config.BundleFormatOCIDir}
+		if !slices.Contains(formats, bundleCfg.PullOpts.Format) {
+			return fmt.Errorf("invalid format %q, must be one of %s", bundleCfg.PullOpts.Format, strings.Join(formats, ", "))
+		}
+		if bundleCfg.PullOpts.MaxPackageSizeMB < 0 {
+			return errors.New("--max-package-size must not be negative")
+		}
+		if bundleCfg.PullOpts.MaxPackageSizeMB > 0 && bundleCfg.PullOpts.Format != config.BundleFormatTarball {
+			return errors.New("--max-package-size can only be used when pulling a bundle tarball")
+		}
+		return nil
+	},
 	RunE: func(_ *cobra.Command, args []string) error {
 		bundleCfg.PullOpts.Source = args[0]
 		configureZarf()
@@ -205,6 +269,104 @@ var pullCmd = &cobra.Command{
 	},
 }
 
+var signCmd = &cobra.Command{
+	Use:   "sign [BUNDLE_TARBALL|OCI_REF]",
+	Short: lang.CmdBundleSignShort,
+	Long:  lang.CmdBundleSignLong,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.SignOpts.Source = args[0]
+		configureZarf()
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.Sign(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to sign bundle: %s", err.Error())
+		}
+		return nil
+	},
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [BUNDLE_TARBALL|OCI_REF]",
+	Short: lang.CmdBundleVerifyShort,
+	Long:  lang.CmdBundleVerifyLong,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.VerifyOpts.Source = args[0]
+		configureZarf()
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.Verify(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to verify bundle: %s", err.Error())
+		}
+		return nil
+	},
+}
+
+var applyDeltaCmd = &cobra.Command{
+	Use:   "apply-delta [OLD_BUNDLE] [DELTA_TARBALL]",
+	Short: lang.CmdBundleApplyDeltaShort,
+	Long:  lang.CmdBundleApplyDeltaLong,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.ApplyDeltaOpts.Base = args[0]
+		bundleCfg.ApplyDeltaOpts.Delta = args[1]
+		configureZarf()
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.ApplyDelta(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to apply delta bundle: %s", err.Error())
+		}
+		return nil
+	},
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [BUNDLE_TARBALL|OCI_REF]",
+	Short: lang.CmdBundleScanShort,
+	Long:  lang.CmdBundleScanLong,
+	Args:  cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		if failOn := bundleCfg.ScanOpts.FailOn; failOn != "" && !slices.Contains(scan.Severities, strings.ToLower(failOn)) {
+			return fmt.Errorf("invalid severity %q, must be one of %s", failOn, strings.Join(scan.Severities, ", "))
+		}
+		if format := bundleCfg.ScanOpts.Format; format != "" && format != scan.FormatJSON {
+			return fmt.Errorf("invalid format %q, must be %s", format, scan.FormatJSON)
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundleCfg.ScanOpts.Source = args[0]
+		configureZarf()
+		bndlClient, err := bundle.New(&bundleCfg)
+		if err != nil {
+			return err
+		}
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.Scan(); err != nil {
+			bndlClient.ClearPaths()
+			return fmt.Errorf("failed to scan bundle: %s", err.Error())
+		}
+		return nil
+	},
+}
+
 var logsCmd = &cobra.Command{
 	Use:     "logs",
 	Aliases: []string{"l"},
@@ -239,10 +401,13 @@ func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdBundleCreateFlagConfirm)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.Output, "output", "o", v.GetString(V_BNDL_CREATE_OUTPUT), lang.CmdBundleCreateFlagOutput)
-	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.SigningKeyPath, "signing-key", "k", v.GetString(V_BNDL_CREATE_SIGNING_KEY), lang.CmdBundleCreateFlagSigningKey)
+	createCmd.Flags().StringSliceVarP(&bundleCfg.CreateOpts.SigningKeyPaths, "signing-key", "k", v.GetStringSlice(V_BNDL_CREATE_SIGNING_KEY), lang.CmdBundleCreateFlagSigningKey)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.SigningKeyPassword, "signing-key-password", "p", v.GetString(V_BNDL_CREATE_SIGNING_KEY_PASSWORD), lang.CmdBundleCreateFlagSigningKeyPassword)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.Version, "version", "v", "", lang.CmdBundleCreateFlagVersion)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.Name, "name", "n", "", lang.CmdBundleCreateFlagName)
+	createCmd.Flags().IntVarP(&bundleCfg.CreateOpts.MaxPackageSizeMB, "max-package-size", "m", 0, lang.CmdBundleCreateFlagMaxPackageSize)
+	createCmd.Flags().StringVar(&bundleCfg.CreateOpts.Base, "base", "", lang.CmdBundleCreateFlagBase)
+	createCmd.Flags().StringVar(&bundleCfg.CreateOpts.DeltaFrom, "delta-from", "", lang.CmdBundleCreateFlagDeltaFrom)
 
 	// deploy cmd flags
 	rootCmd.AddCommand(deployCmd)
@@ -251,14 +416,22 @@ func init() {
 	deployCmd.Flags().StringArrayVarP(&bundleCfg.DeployOpts.Packages, "packages", "p", []string{}, lang.CmdBundleDeployFlagPackages)
 	deployCmd.Flags().BoolVarP(&bundleCfg.DeployOpts.Resume, "resume", "r", false, lang.CmdBundleDeployFlagResume)
 	deployCmd.Flags().IntVar(&bundleCfg.DeployOpts.Retries, "retries", 3, lang.CmdBundleDeployFlagRetries)
+	deployCmd.Flags().StringSliceVarP(&bundleCfg.DeployOpts.PublicKeyPaths, "key", "k", []string{}, lang.CmdBundleDeployFlagKey)
+	deployCmd.Flags().BoolVar(&bundleCfg.DeployOpts.RequireSignedPackages, "require-signed-packages", false, lang.CmdBundleDeployFlagRequireSignedPackages)
 
 	// inspect cmd flags
 	rootCmd.AddCommand(inspectCmd)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.IncludeSBOM, "sbom", "s", false, lang.CmdPackageInspectFlagSBOM)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.ExtractSBOM, "extract", "e", false, lang.CmdPackageInspectFlagExtractSBOM)
-	inspectCmd.Flags().StringVarP(&bundleCfg.InspectOpts.PublicKeyPath, "key", "k", v.GetString(V_BNDL_INSPECT_KEY), lang.CmdBundleInspectFlagKey)
+	inspectCmd.Flags().StringVar(&bundleCfg.InspectOpts.Format, "format", "", lang.CmdBundleInspectFlagFormat)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.Licenses, "licenses", false, lang.CmdBundleInspectFlagLicenses)
+	inspectCmd.Flags().StringSliceVar(&bundleCfg.InspectOpts.DenyLicenses, "deny-license", v.GetStringSlice(V_BNDL_INSPECT_DENY_LICENSES), lang.CmdBundleInspectFlagDenyLicense)
+	inspectCmd.Flags().StringSliceVarP(&bundleCfg.InspectOpts.PublicKeyPaths, "key", "k", v.GetStringSlice(V_BNDL_INSPECT_KEY), lang.CmdBundleInspectFlagKey)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.ListImages, "list-images", "i", false, lang.CmdBundleInspectFlagFindImages)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.ListVariables, "list-variables", "v", false, lang.CmdBundleInspectFlagListVariables)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.Provenance, "provenance", false, lang.CmdBundleInspectFlagProvenance)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.Size, "size", false, lang.CmdBundleInspectFlagSize)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.Uncompressed, "uncompressed", false, lang.CmdBundleInspectFlagUncompressed)
 
 	// remove cmd flags
 	rootCmd.AddCommand(removeCmd)
@@ -270,11 +443,39 @@ func init() {
 	// publish cmd flags
 	rootCmd.AddCommand(publishCmd)
 	publishCmd.Flags().StringVarP(&bundleCfg.PublishOpts.Version, "version", "v", "", lang.CmdPublishVersionFlag)
+	publishCmd.Flags().StringSliceVarP(&bundleCfg.PublishOpts.Tags, "tag", "t", []string{}, lang.CmdPublishTagFlag)
+	publishCmd.Flags().BoolVar(&bundleCfg.PublishOpts.NoOverwrite, "no-overwrite", false, lang.CmdPublishNoOverwriteFlag)
 
 	// pull cmd flags
 	rootCmd.AddCommand(pullCmd)
 	pullCmd.Flags().StringVarP(&bundleCfg.PullOpts.OutputDirectory, "output", "o", v.GetString(V_BNDL_PULL_OUTPUT), lang.CmdBundlePullFlagOutput)
-	pullCmd.Flags().StringVarP(&bundleCfg.PullOpts.PublicKeyPath, "key", "k", v.GetString(V_BNDL_PULL_KEY), lang.CmdBundlePullFlagKey)
+	pullCmd.Flags().StringSliceVarP(&bundleCfg.PullOpts.PublicKeyPaths, "key", "k", v.GetStringSlice(V_BNDL_PULL_KEY), lang.CmdBundlePullFlagKey)
+	pullCmd.Flags().BoolVar(&bundleCfg.PullOpts.Resume, "resume", false, lang.CmdBundlePullFlagResume)
+	pullCmd.Flags().StringArrayVarP(&bundleCfg.PullOpts.Packages, "packages", "p", []string{}, lang.CmdBundlePullFlagPackages)
+	pullCmd.Flags().IntVarP(&bundleCfg.PullOpts.MaxPackageSizeMB, "max-package-size", "m", 0, lang.CmdBundlePullFlagMaxPackageSize)
+	pullCmd.Flags().StringVar(&bundleCfg.PullOpts.Format, "format", config.BundleFormatTarball, lang.CmdBundlePullFlagFormat)
+
+	// sign cmd flags
+	rootCmd.AddCommand(signCmd)
+	signCmd.Flags().StringSliceVarP(&bundleCfg.SignOpts.SigningKeyPaths, "signing-key", "k", []string{}, lang.CmdBundleSignFlagSigningKey)
+	signCmd.Flags().StringVarP(&bundleCfg.SignOpts.SigningKeyPassword, "signing-key-password", "p", "", lang.CmdBundleSignFlagSigningKeyPassword)
+	_ = signCmd.MarkFlagRequired("signing-key")
+
+	// verify cmd flags
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringSliceVarP(&bundleCfg.VerifyOpts.PublicKeyPaths, "key", "k", []string{}, lang.CmdBundleVerifyFlagKey)
+
+	// apply-delta cmd flags
+	rootCmd.AddCommand(applyDeltaCmd)
+	applyDeltaCmd.Flags().StringVarP(&bundleCfg.ApplyDeltaOpts.Output, "output", "o", ".", lang.CmdBundleApplyDeltaFlagOutput)
+
+	// scan cmd flags
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().StringVar(&bundleCfg.ScanOpts.Scanner, "scanner", v.GetString(V_BNDL_SCAN_SCANNER), lang.CmdBundleScanFlagScanner)
+	scanCmd.Flags().StringVar(&bundleCfg.ScanOpts.ScannerPath, "scanner-path", v.GetString(V_BNDL_SCAN_SCANNER_PATH), lang.CmdBundleScanFlagScannerPath)
+	scanCmd.Flags().StringVar(&bundleCfg.ScanOpts.DBPath, "db", v.GetString(V_BNDL_SCAN_DB), lang.CmdBundleScanFlagDB)
+	scanCmd.Flags().StringVar(&bundleCfg.ScanOpts.FailOn, "fail-on", v.GetString(V_BNDL_SCAN_FAIL_ON), lang.CmdBundleScanFlagFailOn)
+	scanCmd.Flags().StringVar(&bundleCfg.ScanOpts.Format, "format", "", lang.CmdBundleScanFlagFormat)
 
 	// logs cmd
 	rootCmd.AddCommand(logsCmd)
@@ -21,10 +21,13 @@ const (
 	V_NO_LOG_FILE          = "options.no_log_file"
 	V_NO_PROGRESS          = "options.no_progress"
 	V_UDS_CACHE            = "options.uds_cache"
+	V_UDS_CACHE_POLICY     = "options.cache_policy"
 	V_TMP_DIR              = "options.tmp_dir"
 	V_INSECURE             = "options.insecure"
 	V_NO_COLOR             = "options.no_color"
 	V_BNDL_OCI_CONCURRENCY = "options.oci_concurrency"
+	V_TRUSTED_KEYS         = "options.trusted_keys"
+	V_REQUIRE_SIGNED       = "options.require_signed"
 
 	// Bundle create config keys
 	V_BNDL_CREATE_OUTPUT               = "create.output"
@@ -32,7 +35,14 @@ const (
 	V_BNDL_CREATE_SIGNING_KEY_PASSWORD = "create.signing-key-password"
 
 	// Bundle inspect config keys
-	V_BNDL_INSPECT_KEY = "bundle.inspect.key"
+	V_BNDL_INSPECT_KEY           = "bundle.inspect.key"
+	V_BNDL_INSPECT_DENY_LICENSES = "bundle.inspect.deny-licenses"
+
+	// Bundle scan config keys
+	V_BNDL_SCAN_SCANNER      = "bundle.scan.scanner"
+	V_BNDL_SCAN_SCANNER_PATH = "bundle.scan.scanner-path"
+	V_BNDL_SCAN_DB           = "bundle.scan.db"
+	V_BNDL_SCAN_FAIL_ON      = "bundle.scan.fail-on"
 
 	// Bundle pull config keys
 	V_BNDL_PULL_OUTPUT = "bundle.pull.output"
@@ -5,7 +5,10 @@
 package config
 
 import (
+	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -25,6 +28,24 @@ const (
 	// BundlePrefix is the prefix for compiled uds bundles
 	BundlePrefix = "uds-bundle-"
 
+	// BundleDeltaPrefix is the prefix for delta bundles, which hold only the blobs a bundle adds to an older bundle
+	BundleDeltaPrefix = "uds-delta-"
+
+	// BundleDeltaJSON is the file in a delta bundle describing the bundle it rebuilds
+	BundleDeltaJSON = "delta.json"
+
+	// BundleFormatTarball is the pull format writing a bundle as a .tar.zst
+	BundleFormatTarball = "tarball"
+
+	// BundleFormatOCIDir is the pull format writing a bundle as an unpacked OCI image layout dir
+	BundleFormatOCIDir = "oci-dir"
+
+	// MB is the number of bytes in a megabyte, as used by --max-package-size
+	MB = 1000 * 1000
+
+	// MultiArch is the architecture used in the filename of bundles containing more than one architecture
+	MultiArch = "multi"
+
 	// SBOMsTar is the sboms.tar file in a Zarf pkg
 	SBOMsTar = "sboms.tar"
 
@@ -34,9 +55,27 @@ const (
 	// BundleSBOM is the name of the untarred folder containing the bundle's SBOM
 	BundleSBOM = "bundle-sboms"
 
+	// ZarfYAMLSignature is the name of a Zarf package's metadata signature file
+	ZarfYAMLSignature = "zarf.yaml.sig"
+
 	// BundleYAMLSignature is the name of the bundle's metadata signature file
 	BundleYAMLSignature = "uds-bundle.yaml.sig"
 
+	// BundleSignatureArtifactType is the artifact type of detached bundle signatures attached as OCI referrers
+	BundleSignatureArtifactType = "application/vnd.uds.bundle.signature.v1"
+
+	// BundleSignatureMediaType is the media type of the layer holding a detached bundle signature
+	BundleSignatureMediaType = "application/vnd.uds.bundle.signature.v1+base64"
+
+	// BundleSignatureSidecarExt is the extension of detached signature files written next to bundle tarballs
+	BundleSignatureSidecarExt = ".sig"
+
+	// BundleProvenance is the name of the provenance statement generated when the bundle is created
+	BundleProvenance = "provenance.json"
+
+	// BundleProvenanceArtifactType is the artifact type of provenance statements attached to published bundles as OCI referrers
+	BundleProvenanceArtifactType = "application/vnd.in-toto+json"
+
 	// PublicKeyFile is the name of the public key file
 	PublicKeyFile = "public.key"
 
@@ -49,6 +88,21 @@ const (
 	// UDSCacheLayers is the directory in the cache containing cached bundle layers
 	UDSCacheLayers = "layers"
 
+	// CachePolicyAll caches every content-addressed layer of a bundle's packages
+	CachePolicyAll = "all"
+
+	// CachePolicyImages only caches image layers
+	CachePolicyImages = "images"
+
+	// CachePolicyNone disables the layer cache
+	CachePolicyNone = "none"
+
+	// UDSCacheLock is the file in the cache that CLI processes lock to share the cache safely
+	UDSCacheLock = "layers.lock"
+
+	// UDSCachePulls is the directory in the cache containing the staged blobs of bundles being pulled
+	UDSCachePulls = "pulls"
+
 	// EnvVarPrefix is the prefix for environment variables to override bundle helm variables
 	EnvVarPrefix = "UDS_"
 
@@ -122,6 +176,32 @@ func GetArch(archs ...string) string {
 	return runtime.GOARCH
 }
 
+// ParseArchitectures returns the deduplicated architectures of a comma-separated architecture list
+func ParseArchitectures(archList string) []string {
+	var archs []string
+	for _, arch := range strings.Split(archList, ",") {
+		arch = strings.TrimSpace(arch)
+		if arch != "" && !slices.Contains(archs, arch) {
+			archs = append(archs, arch)
+		}
+	}
+	return archs
+}
+
+// BundleSignatureName returns the name of the bundle's nth metadata signature file, the first signature keeps the
+// BundleYAMLSignature name so that it can be verified by older versions of UDS CLI
+func BundleSignatureName(n int) string {
+	if n == 0 {
+		return BundleYAMLSignature
+	}
+	return fmt.Sprintf("%s.%d", BundleYAMLSignature, n)
+}
+
+// IsBundleSignature returns whether the given name is one of the bundle's metadata signature files
+func IsBundleSignature(name string) bool {
+	return name == BundleYAMLSignature || strings.HasPrefix(name, BundleYAMLSignature+".")
+}
+
 var (
 	// BundleAlwaysPull is a list of paths that will always be pulled from the remote repository.
 	BundleAlwaysPull = []string{BundleYAML, BundleYAMLSignature}
@@ -10,12 +10,13 @@ const (
 	RootCmdFlagSkipLogFile    = "Disable log file creation"
 	RootCmdFlagNoProgress     = "Disable fancy UI progress bars, spinners, logos, etc"
 	RootCmdFlagCachePath      = "Specify the location of the UDS cache directory"
+	RootCmdFlagCachePolicy    = "Specify which layers are cached: all (every package layer), images (only image layers) or none"
 	RootCmdFlagTempDir        = "Specify the temporary directory to use for intermediate files"
 	RootCmdFlagInsecure       = "Allow access to insecure registries and disable other recommended security enforcements such as package checksum and signature validation. This flag should only be used if you have a specific reason and accept the reduced security posture."
 	RootCmdFlagNoColor        = "Disable color output"
 	RootCmdFlagLogLevel       = "Log level when running UDS-CLI. Valid options are: warn, info, debug, trace"
 	RootCmdErrInvalidLogLevel = "Invalid log level. Valid options are: warn, info, debug, trace."
-	RootCmdFlagArch           = "Architecture for UDS bundles and Zarf packages"
+	RootCmdFlagArch           = "Architecture for UDS bundles and Zarf packages, uds create accepts a comma-separated list to create a multi-arch bundle"
 
 	// completion
 	CompletionCmdShort          = "Generate the autocompletion script for the specified shell"
@@ -37,27 +38,38 @@ const (
 	CmdBundleCreateShort                  = "Create a bundle from a given directory or the current directory"
 	CmdBundleCreateFlagConfirm            = "Confirm bundle creation without prompting"
 	CmdBundleCreateFlagOutput             = "Specify the output (an oci:// URL) for the created bundle"
-	CmdBundleCreateFlagSigningKey         = "Path to private key file for signing bundles"
+	CmdBundleCreateFlagSigningKey         = "Path to private key file for signing bundles (can be repeated or comma-separated to sign the bundle with multiple keys)"
 	CmdBundleCreateFlagSigningKeyPassword = "Password to the private key file used for signing bundles"
 	CmdBundleCreateFlagVersion            = "Specify the version of the bundle"
 	CmdBundleCreateFlagName               = "Specify the name of the bundle"
+	CmdBundleCreateFlagMaxPackageSize     = "Specify the maximum size of the bundle tarball in megabytes, larger bundles are split into multiple parts to be loaded onto smaller media. Use 0 to disable splitting"
+	CmdBundleCreateFlagBase               = "A previously created bundle (tarball, OCI layout dir or OCI ref) to copy unchanged remote packages from instead of fetching them"
+	CmdBundleCreateFlagDeltaFrom          = "An older bundle (tarball or OCI layout dir) to also create a delta bundle against, holding only the blobs the new bundle adds. Use apply-delta to rebuild the new bundle from the older bundle and the delta"
 
 	// bundle deploy
-	CmdBundleDeployShort        = "Deploy a bundle from a local tarball or oci:// URL"
-	CmdBundleDeployFlagConfirm  = "Confirms bundle deployment without prompting. ONLY use with bundles you trust"
-	CmdBundleDeployFlagPackages = "Specify which zarf packages you would like to deploy from the bundle. By default all zarf packages in the bundle are deployed."
-	CmdBundleDeployFlagResume   = "Only deploys packages from the bundle which haven't already been deployed"
-	CmdBundleDeployFlagSet      = "Specify deployment variables to set on the command line (KEY=value)"
-	CmdBundleDeployFlagRetries  = "Specify the number of retries for package deployments (applies to all pkgs in a bundle)"
-	CmdBundleDeployFlagRef      = "Specify which zarf package ref you want to deploy. By default the ref set in the bundle yaml is used."
+	CmdBundleDeployShort                     = "Deploy a bundle from a local tarball or oci:// URL"
+	CmdBundleDeployFlagConfirm               = "Confirms bundle deployment without prompting. ONLY use with bundles you trust"
+	CmdBundleDeployFlagPackages              = "Specify which zarf packages you would like to deploy from the bundle. By default all zarf packages in the bundle are deployed."
+	CmdBundleDeployFlagResume                = "Only deploys packages from the bundle which haven't already been deployed"
+	CmdBundleDeployFlagSet                   = "Specify deployment variables to set on the command line (KEY=value)"
+	CmdBundleDeployFlagRetries               = "Specify the number of retries for package deployments (applies to all pkgs in a bundle)"
+	CmdBundleDeployFlagRef                   = "Specify which zarf package ref you want to deploy. By default the ref set in the bundle yaml is used."
+	CmdBundleDeployFlagKey                   = "Path to a public key file that will be used to validate a signed bundle (can be repeated or comma-separated; the bundle is valid if any key verifies it)"
+	CmdBundleDeployFlagRequireSignedPackages = "Fail the deployment if the bundle's signature isn't verified or the signature of any package in the bundle was not verified when the bundle was created"
 
 	// bundle inspect
 	CmdBundleInspectShort             = "Display the metadata of a bundle"
-	CmdBundleInspectFlagKey           = "Path to a public key file that will be used to validate a signed bundle"
+	CmdBundleInspectFlagKey           = "Path to a public key file that will be used to validate a signed bundle (can be repeated or comma-separated; the bundle is valid if any key verifies it)"
 	CmdPackageInspectFlagSBOM         = "Create a tarball of SBOMs contained in the bundle"
 	CmdPackageInspectFlagExtractSBOM  = "Create a folder of SBOMs contained in the bundle"
+	CmdBundleInspectFlagFormat        = "Output format: with --sbom merge the bundle's SBOMs into a single SBOM (cyclonedx or spdx), with --licenses print the license report as json or csv instead of YAML"
+	CmdBundleInspectFlagLicenses      = "Print a report of the licenses of the components in the SBOMs contained in the bundle, grouped by license, package and image"
+	CmdBundleInspectFlagDenyLicense   = "License ID or glob (e.g. 'GPL-*') to flag in the license report (can be repeated or comma-separated)"
 	CmdBundleInspectFlagFindImages    = "Derive images from a uds-bundle.yaml file and list them"
 	CmdBundleInspectFlagListVariables = "List all configurable variables in a bundle (including zarf variables)"
+	CmdBundleInspectFlagProvenance    = "Print the in-toto provenance statement recorded when the bundle was created"
+	CmdBundleInspectFlagSize          = "Print the compressed size of each package in the bundle, along with how much of it is unique to the package or shared with other packages"
+	CmdBundleInspectFlagUncompressed  = "With --size, also print the size of each package once decompressed. This reads every layer of the bundle, downloading them for remote bundles"
 
 	// bundle remove
 	CmdBundleRemoveShort        = "Remove a bundle that has been deployed already"
@@ -65,13 +77,65 @@ const (
 	CmdBundleRemoveFlagPackages = "Specify which zarf packages you would like to remove from the bundle. By default all zarf packages in the bundle are removed."
 
 	// bundle publish
-	CmdPublishShort       = "Publish a bundle from the local file system to a remote registry"
-	CmdPublishVersionFlag = "[Deprecated] Specify the version of the bundle to be published. This flag will be removed in a future version. Users should use the --version flag during creation to override the version defined in uds-bundle.yaml"
+	CmdPublishShort           = "Publish a bundle from the local file system or another registry to a remote registry"
+	CmdPublishTagFlag         = "Additional tag to apply to the published bundle (can be repeated or comma-separated)"
+	CmdPublishNoOverwriteFlag = "Fail instead of publishing if any of the bundle's tags already points to a different digest"
+	CmdPublishVersionFlag     = "[Deprecated] Specify the version of the bundle to be published. This flag will be removed in a future version. Users should use the --version flag during creation to override the version defined in uds-bundle.yaml"
 
 	// bundle pull
-	CmdBundlePullShort      = "Pull a bundle from a remote registry and save to the local file system"
-	CmdBundlePullFlagOutput = "Specify the output directory for the pulled bundle"
-	CmdBundlePullFlagKey    = "Path to a public key file that will be used to validate a signed bundle"
+	CmdBundlePullShort              = "Pull a bundle from a remote registry and save to the local file system"
+	CmdBundlePullFlagOutput         = "Specify the output directory for the pulled bundle"
+	CmdBundlePullFlagKey            = "Path to a public key file that will be used to validate a signed bundle (can be repeated or comma-separated; the bundle is valid if any key verifies it)"
+	CmdBundlePullFlagResume         = "Resume an interrupted pull of the bundle, reusing the blobs it already pulled"
+	CmdBundlePullFlagPackages       = "Specify which zarf packages you would like to pull from the bundle, writing a partial bundle derived from it. By default all zarf packages in the bundle are pulled."
+	CmdBundlePullFlagMaxPackageSize = "Specify the maximum size of the pulled bundle tarball in megabytes, larger bundles are split into multiple parts to be loaded onto smaller media. Use 0 to disable splitting"
+	CmdBundlePullFlagFormat         = "Format to save the pulled bundle in, either a tarball or an oci-dir holding the bundle as an unpacked OCI image layout"
+
+	// bundle sign
+	CmdBundleSignShort                  = "Sign an existing bundle without rebuilding it"
+	CmdBundleSignLong                   = "Sign an existing bundle with detached signatures. Signatures for published bundles are attached to the bundle as OCI referrers and signatures for bundle tarballs are written next to the tarball as <tarball>.sig files."
+	CmdBundleSignFlagSigningKey         = "Path to private key file for signing bundles (can be repeated or comma-separated to sign the bundle with multiple keys)"
+	CmdBundleSignFlagSigningKeyPassword = "Password to the private key file used for signing bundles"
+
+	// bundle verify
+	CmdBundleVerifyShort   = "Verify the integrity and signatures of a bundle without deploying it"
+	CmdBundleVerifyLong    = "Verify a bundle without deploying it. The digest of every blob in the bundle is recomputed, each Zarf package's checksums.txt and aggregate checksum are validated and any missing or corrupt layers are reported. If a public key is provided (or trusted keys are configured) the bundle's detached signatures (OCI referrers or <tarball>.sig files) and the signatures embedded in it at create time are also verified; the bundle is verified if any of its signatures is verified by any of the keys."
+	CmdBundleVerifyFlagKey = "Path to a public key file that will be used to verify the bundle's signatures (can be repeated or comma-separated; the bundle is valid if any key verifies it)"
+
+	// bundle apply-delta
+	CmdBundleApplyDeltaShort      = "Rebuild a bundle tarball from an older bundle and a delta bundle"
+	CmdBundleApplyDeltaLong       = "Rebuild a bundle tarball from the older bundle (tarball or OCI layout dir) a delta bundle was created against with create --delta-from. The rebuilt tarball is byte-identical to the bundle the delta was created alongside and is verified against its recorded sha256sum."
+	CmdBundleApplyDeltaFlagOutput = "Specify the directory to write the rebuilt bundle tarball to"
+
+	// bundle scan
+	CmdBundleScanShort           = "Scan the images of a bundle for vulnerabilities"
+	CmdBundleScanLong            = "Scan the SBOMs of a bundle's images and Zarf components for vulnerabilities and report them by package and image. Scanning works offline from a bundle tarball when given a local vulnerability database. Use --fail-on to exit with an error if any vulnerability is at or above a severity."
+	CmdBundleScanFlagScanner     = "Scanner to use (grype)"
+	CmdBundleScanFlagScannerPath = "Path to the scanner's executable, defaults to finding it on the PATH (any Grype-compatible executable can be used)"
+	CmdBundleScanFlagDB          = "Path to a local vulnerability database directory or archive, which also stops the scanner from updating its database"
+	CmdBundleScanFlagFailOn      = "Fail if any vulnerability is at or above this severity (negligible, low, medium, high or critical)"
+	CmdBundleScanFlagFormat      = "Print the report as json instead of tables"
+
+	// uds registry
+	CmdRegistryShort         = "Manage the bundles published to an OCI registry"
+	CmdRegistryLsShort       = "List the bundles published to an OCI repository"
+	CmdRegistryLsLong        = "List every tag of a bundle OCI repository along with its digest, architectures, version, creation time and total size. Tags that don't point to a bundle are skipped."
+	CmdRegistryRmShort       = "Remove a published bundle from an OCI repository"
+	CmdRegistryRmLong        = "Remove a single architecture from a multi-arch bundle's index, updating every tag that points to it, or remove the bundle's tag entirely. Registries delete manifests by digest, so removing a tag also removes any other tags that point to the same bundle."
+	CmdRegistryRmFlagArch    = "Only remove this architecture from the bundle's index, the tag is removed if it is the index's last architecture"
+	CmdRegistryRmFlagConfirm = "REQUIRED. Confirm the removal action to prevent accidental deletions"
+
+	// cache
+	CmdCacheShort              = "Manage the layers UDS CLI caches when creating and pulling bundles"
+	CmdCacheLsShort            = "List the cached layers with their size and when they were last used"
+	CmdCacheSizeShort          = "Show the number of cached layers and their total size"
+	CmdCachePruneShort         = "Remove cached layers that haven't been used recently"
+	CmdCachePruneLong          = "Remove the cached layers that haven't been used for longer than --older-than, then remove the least recently used layers until the cache is no larger than --max-size."
+	CmdCachePruneFlagOlderThan = "Remove layers that haven't been used for longer than this duration (e.g. 720h)"
+	CmdCachePruneFlagMaxSize   = "Remove the least recently used layers until the cache is no larger than this many megabytes"
+	CmdCacheVerifyShort        = "Verify each cached layer against its digest, removing corrupt layers"
+	CmdCacheClearShort         = "Remove every cached layer and the staged blobs of partially pulled bundles"
+	CmdCacheClearFlagConfirm   = "REQUIRED. Confirm clearing the cache to prevent accidental deletions"
 
 	// cmd viper setup
 	CmdViperErrLoadingConfigFile = "failed to load config file: %s"
@@ -104,7 +168,7 @@ const (
 	CmdDevShort                = "[beta] Commands useful for developing bundles"
 	CmdDevDeployShort          = "[beta] Creates and deploys a UDS bundle in dev mode"
 	CmdBundleCreateFlagFlavor  = "[beta] Specify which zarf package flavor you want to use."
-	CmdDevDeployLong           = "[beta] Creates and deploys a UDS bundle from a given directory or OCI repository in dev mode, setting package options like YOLO mode for faster iteration."
+	CmdDevDeployLong           = "[beta] Creates and deploys a UDS bundle from a given directory or OCI repository in dev mode, or deploys a bundle pulled as an OCI layout dir, setting package options like YOLO mode for faster iteration."
 	CmdBundleCreateForceCreate = "[beta] For local bundles with local packages, specify whether to create a zarf package even if it already exists."
 
 	// uds monitor
@@ -0,0 +1,110 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+// loadBase opens the bundle given to --base for each of the architectures being created, extracting it into dst if it
+// is a tarball, so that its unchanged packages can be copied into the new bundle
+func (b *Bundle) loadBase(ctx context.Context, archs []string, dst string) (*fetcher.Base, error) {
+	source, err := CheckOCISourcePath(b.cfg.CreateOpts.Base)
+	if err != nil {
+		return nil, fmt.Errorf("base bundle %s is either invalid or doesn't exist", b.cfg.CreateOpts.Base)
+	}
+
+	base := &fetcher.Base{Packages: make(map[string]ocispec.Descriptor)}
+	var provider Provider
+	for _, arch := range archs {
+		provider, err = newArchBundleProvider(source, dst, arch)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the %s base bundle: %w", arch, err)
+		}
+		filepaths, err := provider.LoadBundleMetadata()
+		if err != nil {
+			return nil, err
+		}
+		var baseBundle types.UDSBundle
+		if err := utils.ReadYAMLStrict(filepaths[config.BundleYAML], &baseBundle); err != nil {
+			return nil, err
+		}
+		rootManifest, err := provider.getBundleManifest()
+		if err != nil {
+			return nil, err
+		}
+		pkgManifests, err := basePackageManifests(baseBundle, rootManifest.Layers)
+		if err != nil {
+			return nil, err
+		}
+		for i, pkg := range baseBundle.Packages {
+			if key, ok := fetcher.PackageKey(pkg); ok {
+				base.Packages[key] = pkgManifests[i]
+			}
+		}
+	}
+
+	switch p := provider.(type) {
+	case *ociProvider:
+		base.Storage = p.Repo()
+	case *ociDirBundleProvider:
+		if base.Storage, err = ocistore.NewWithContext(ctx, p.src); err != nil {
+			return nil, err
+		}
+	case *tarballBundleProvider:
+		if base.Storage, err = extractBase(ctx, p.src, dst); err != nil {
+			return nil, err
+		}
+	}
+	message.Debugf("Loaded %d packages from base bundle %s", len(base.Packages), source)
+	return base, nil
+}
+
+// basePackageManifests returns the descriptors of a bundle's package manifests, which are the first layers of the
+// bundle's root manifest in the same order as the bundle's packages
+func basePackageManifests(bundle types.UDSBundle, layers []ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	if len(layers) < len(bundle.Packages) {
+		return nil, fmt.Errorf("base bundle root manifest has %d layers, expected at least one per package", len(layers))
+	}
+	for i, pkg := range bundle.Packages {
+		// package manifests are the only layers without a title annotation
+		if _, ok := layers[i].Annotations[ocispec.AnnotationTitle]; ok {
+			return nil, fmt.Errorf("base bundle root manifest has no package manifest for package %s", pkg.Name)
+		}
+	}
+	return layers[:len(bundle.Packages)], nil
+}
+
+// extractBase extracts a base bundle tarball into dst so that its blobs can be read without reading the whole tarball
+// for each one
+func extractBase(ctx context.Context, src, dst string) (content.ReadOnlyStorage, error) {
+	spinner := message.NewProgressSpinner("Extracting base bundle %s", src)
+	defer spinner.Stop()
+	bundleFile, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer bundleFile.Close()
+	if err := config.BundleArchiveFormat.Extract(ctx, bundleFile, utils.ExtractAllFiles(dst)); err != nil {
+		return nil, fmt.Errorf("unable to extract base bundle %s: %w", src, err)
+	}
+	store, err := ocistore.NewWithContext(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	spinner.Successf("Extracted base bundle %s", src)
+	return store, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+func TestLoadBase(t *testing.T) {
+	ctx := context.Background()
+	var pkgManifest ocispec.Descriptor
+	dir, _ := ocitest.NewOCIDir(t, ocitest.BundleOptions{BundleYAML: func(desc ocispec.Descriptor) []byte {
+		pkgManifest = desc
+		return fmt.Appendf(nil, "kind: UDSBundle\nmetadata:\n  name: example\n  version: 0.0.1\npackages:\n  - name: nginx\n    repository: ghcr.io/example/nginx\n    ref: 0.0.1@%s\n", desc.Digest)
+	}})
+	b := &Bundle{cfg: &types.BundleConfig{CreateOpts: types.BundleCreateOptions{Base: dir}}}
+	base, err := b.loadBase(ctx, []string{"amd64"}, t.TempDir())
+	require.NoError(t, err)
+
+	pkg := types.Package{Name: "nginx", Repository: "ghcr.io/example/nginx", Ref: "0.0.1@" + pkgManifest.Digest.String()}
+	tests := []struct {
+		name   string
+		pkg    types.Package
+		reused bool
+	}{
+		{name: "unchanged", pkg: pkg, reused: true},
+		{name: "retagged at the same digest", pkg: types.Package{Name: "nginx", Repository: pkg.Repository, Ref: "latest@" + pkgManifest.Digest.String()}, reused: true},
+		{name: "changed digest", pkg: types.Package{Name: "nginx", Repository: pkg.Repository, Ref: "0.0.2@sha256:" + fmt.Sprintf("%064d", 0)}},
+		{name: "different optional components", pkg: types.Package{Name: "nginx", Repository: pkg.Repository, Ref: pkg.Ref, OptionalComponents: []string{"debug"}}},
+		{name: "local package", pkg: types.Package{Name: "nginx", Path: "zarf-package-nginx-amd64-0.0.1.tar.zst", Ref: pkg.Ref}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, ok := base.Manifest(tt.pkg)
+			require.Equal(t, tt.reused, ok)
+			if tt.reused {
+				require.Equal(t, pkgManifest.Digest, desc.Digest)
+			}
+		})
+	}
+
+	t.Run("copy package", func(t *testing.T) {
+		store, err := ocistore.NewWithContext(ctx, t.TempDir())
+		require.NoError(t, err)
+		var rootManifest ocispec.Manifest
+		pkgFetcher, err := fetcher.NewPkgFetcher(pkg, fetcher.Config{Store: store, BundleRootManifest: &rootManifest, Base: base, Quiet: true})
+		require.NoError(t, err)
+		descs, err := pkgFetcher.Fetch()
+		require.NoError(t, err)
+
+		// the package's config, zarf.yaml, component layer and manifest are copied into the store
+		require.Len(t, descs, 4)
+		for _, desc := range descs {
+			exists, err := store.Exists(ctx, desc)
+			require.NoError(t, err)
+			require.True(t, exists)
+		}
+		require.Equal(t, []ocispec.Descriptor{pkgManifest}, rootManifest.Layers)
+	})
+}
@@ -5,11 +5,15 @@
 package bundle
 
 import (
+	"cmp"
 	"context"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,12 +23,14 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/pkgsignature"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/message"
 	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/errdef"
 )
 
 // Bundle handles bundler operations
@@ -74,7 +80,7 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 	bundle := &b.bundle
 	if bundle.Metadata.Architecture == "" {
 		// ValidateBundle was erroneously called before CalculateBuildInfo
-		if err := b.CalculateBuildInfo(); err != nil {
+		if err := b.CalculateBuildInfo(config.GetArch()); err != nil {
 			return err
 		}
 		if bundle.Metadata.Architecture == "" {
@@ -116,43 +122,31 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 		}
 		var zarfYAML v1alpha1.ZarfPackage
 		var url string
+		arch := pkgArch(pkg, bundle.Metadata.Architecture)
 		ctx := context.TODO()
 		// if using a remote repository
 		// todo: refactor these hash checks using the fetcher
 		if pkg.Repository != "" {
-			url = fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
-			if strings.Contains(pkg.Ref, "@sha256:") {
-				url = fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
-			}
-
-			platform := ocispec.Platform{
-				Architecture: config.GetArch(),
-				OS:           oci.MultiOS,
-			}
-			remote, err := zoci.NewRemote(ctx, url, platform)
+			// todo: don't do this here, a "validate" fn shouldn't be modifying the bundle
+			ref, err := resolveRemotePkgRef(ctx, pkg.Repository, pkg.Ref, pkg.Flavor, arch)
 			if err != nil {
 				return err
 			}
-			if err := remote.Repo().Reference.ValidateReferenceAsDigest(); err != nil {
-				manifestDesc, err := remote.ResolveRoot(ctx)
-				if err != nil {
-					return err
-				}
-				// todo: don't do this here, a "validate" fn shouldn't be modifying the bundle
-				bundle.Packages[idx].Ref = pkg.Ref + "@sha256:" + manifestDesc.Digest.Encoded()
-			}
+			url = fmt.Sprintf("%s:%s", pkg.Repository, ref)
+			bundle.Packages[idx].Ref = ref
+			pkg.Ref = ref
 		} else {
 			// atm we don't support outputting a bundle with local pkgs outputting to OCI
 			if utils.IsRegistryURL(b.cfg.CreateOpts.Output) {
 				return fmt.Errorf("detected local Zarf package: %s, outputting to an OCI registry is not supported when using local Zarf packages", pkg.Name)
 			}
-			path := getPkgPath(pkg, bundle.Metadata.Architecture, b.cfg.CreateOpts.SourceDirectory)
+			path := getPkgPath(pkg, arch, b.cfg.CreateOpts.SourceDirectory)
 			bundle.Packages[idx].Path = path
 		}
 
 		// grab the Zarf pkg metadata
 		f, err := fetcher.NewPkgFetcher(pkg, fetcher.Config{
-			PkgIter: idx, Bundle: bundle,
+			PkgIter: idx, Bundle: bundle, Arch: arch,
 		})
 		if err != nil {
 			return err
@@ -169,13 +163,25 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 		}
 		message.Debug("Validating package:", jsonValue)
 
-		// todo: need to packager.ValidatePackageSignature (or come up with a bundle-level signature scheme)
-		publicKeyPath := filepath.Join(b.tmp, config.PublicKeyFile)
-		if pkg.PublicKey != "" {
-			if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
-				return err
-			}
-			defer os.Remove(publicKeyPath)
+		// verify the package's signature and record the result in the bundle
+		spinner.Updatef("Verifying signature of package: %s", pkg.Name)
+		status, err := b.verifyPkgSignature(ctx, bundle.Packages[idx])
+		if err != nil {
+			return fmt.Errorf("unable to verify signature of package %s: %w", pkg.Name, err)
+		}
+		if bundle.Build.PackageSignatures == nil {
+			bundle.Build.PackageSignatures = make(map[string]pkgsignature.Status)
+		}
+		bundle.Build.PackageSignatures[pkg.Name] = status
+
+		// make sure the package was built with the flavor the bundle expects
+		if pkg.Flavor != "" && zarfYAML.Build.Flavor != pkg.Flavor {
+			return fmt.Errorf("%s .packages[%s] expects flavor %s, but the package was built with flavor %q", config.BundleYAML, pkg.Name, pkg.Flavor, zarfYAML.Build.Flavor)
+		}
+
+		// make sure a package with an explicit architecture was built for it
+		if pkg.Architecture != "" && zarfYAML.Metadata.Architecture != pkg.Architecture {
+			return fmt.Errorf("%s .packages[%s] expects architecture %s, but the package was built for %q", config.BundleYAML, pkg.Name, pkg.Architecture, zarfYAML.Metadata.Architecture)
 		}
 
 		if len(pkg.OptionalComponents) > 0 {
@@ -203,6 +209,11 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 	return nil
 }
 
+// pkgArch returns the architecture of the Zarf package to use, falling back to the bundle's architecture
+func pkgArch(pkg types.Package, bundleArch string) string {
+	return cmp.Or(pkg.Architecture, bundleArch)
+}
+
 func getPkgPath(pkg types.Package, arch string, srcDir string) string {
 	var fullPkgName string
 	var path string
@@ -225,8 +236,8 @@ func getPkgPath(pkg types.Package, arch string, srcDir string) string {
 	return path
 }
 
-// CalculateBuildInfo calculates the build info for the bundle
-func (b *Bundle) CalculateBuildInfo() error {
+// CalculateBuildInfo calculates the build info for the bundle, arch is the architecture given to --architecture
+func (b *Bundle) CalculateBuildInfo(arch string) error {
 	now := time.Now()
 	b.bundle.Build.User = os.Getenv("USER")
 
@@ -237,7 +248,7 @@ func (b *Bundle) CalculateBuildInfo() error {
 	b.bundle.Build.Terminal = hostname
 
 	// --architecture flag > metadata.arch > build.arch > runtime.GOARCH (default)
-	b.bundle.Build.Architecture = config.GetArch(b.bundle.Metadata.Architecture, b.bundle.Build.Architecture)
+	b.bundle.Build.Architecture = cmp.Or(arch, b.bundle.Metadata.Architecture, b.bundle.Build.Architecture, runtime.GOARCH)
 	b.bundle.Metadata.Architecture = b.bundle.Build.Architecture
 
 	b.bundle.Build.Timestamp = now.Format(time.RFC1123Z)
@@ -247,8 +258,9 @@ func (b *Bundle) CalculateBuildInfo() error {
 	return nil
 }
 
-// ValidateBundleSignature validates the bundle signature
-func ValidateBundleSignature(bundleYAMLPath, signaturePath, publicKeyPath string) error {
+// ValidateBundleSignature validates the bundle signatures, the bundle is valid if any of its signatures is verified by
+// any of the public keys
+func ValidateBundleSignature(bundleYAMLPath string, signaturePaths, publicKeyPaths []string) error {
 	if helpers.InvalidPath(bundleYAMLPath) {
 		if bundleYAMLPath == "" {
 			return fmt.Errorf("path for %s is empty", config.BundleYAML)
@@ -256,20 +268,305 @@ func ValidateBundleSignature(bundleYAMLPath, signaturePath, publicKeyPath string
 		return fmt.Errorf("path for %s at %s does not exist", config.BundleYAML, bundleYAMLPath)
 	}
 	// The package is not signed, and no public key was provided
-	if signaturePath == "" && publicKeyPath == "" {
+	if len(signaturePaths) == 0 && len(publicKeyPaths) == 0 {
 		return nil
 	}
 	// The package is not signed, but a public key was provided
-	if helpers.InvalidPath(signaturePath) && !helpers.InvalidPath(publicKeyPath) {
+	if len(signaturePaths) == 0 {
 		return errors.New("package is not signed, but a public key was provided")
 	}
 	// The package is signed, but no public key was provided
-	if !helpers.InvalidPath(signaturePath) && helpers.InvalidPath(publicKeyPath) {
+	if len(publicKeyPaths) == 0 {
 		return errors.New("package is signed, but no public key was provided")
 	}
 
 	// The package is signed, and a public key was provided
-	return zarfUtils.CosignVerifyBlob(context.TODO(), bundleYAMLPath, signaturePath, publicKeyPath)
+	var errs []error
+	for _, publicKeyPath := range publicKeyPaths {
+		for _, signaturePath := range signaturePaths {
+			err := zarfUtils.CosignVerifyBlob(context.TODO(), bundleYAMLPath, signaturePath, publicKeyPath)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(signaturePath), err))
+		}
+	}
+	return fmt.Errorf("none of the bundle's signatures could be verified by the provided public keys: %w", errors.Join(errs...))
+}
+
+// validateBundleSignatures validates a bundle's embedded and detached signatures against the given public keys and any
+// trusted keys from the UDS config, returning whether one of the signatures was verified. Unsigned bundles are only
+// rejected because of the trusted keys if require_signed is set in the UDS config
+func validateBundleSignatures(ctx context.Context, provider Provider, source string, filepaths types.PathMap, keys []string, tmpDir string) (bool, error) {
+	signatures, err := loadBundleSignatures(ctx, provider, source, filepaths, tmpDir)
+	if err != nil {
+		return false, err
+	}
+	if !signatures.signed() {
+		if config.CommonOptions.RequireSigned {
+			return false, errors.New("bundle is not signed, but require_signed is set")
+		}
+		if len(keys) == 0 && len(config.CommonOptions.TrustedKeys) > 0 {
+			message.Warn("Bundle is not signed, skipping verification against the trusted keys (set require_signed to reject unsigned bundles)")
+			return false, nil
+		}
+	}
+	publicKeyPaths, err := trustedPublicKeys(append(slices.Clone(keys), config.CommonOptions.TrustedKeys...), tmpDir)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case !signatures.signed() && len(publicKeyPaths) == 0:
+		return false, nil
+	case !signatures.signed():
+		return false, errors.New("bundle is not signed, but a public key was provided")
+	case len(publicKeyPaths) == 0:
+		return false, errors.New("bundle is signed, but no public key was provided")
+	}
+	if _, err := signatures.verify(publicKeyPaths); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// bundleMetadataPaths returns the paths to pull when loading a bundle's metadata, including all of the bundle's
+// metadata signatures and its provenance statement
+func bundleMetadataPaths(rootManifest *oci.Manifest) []string {
+	paths := slices.Clone(config.BundleAlwaysPull)
+	for _, layer := range rootManifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		if (config.IsBundleSignature(title) || title == config.BundleProvenance) && !slices.Contains(paths, title) {
+			paths = append(paths, title)
+		}
+	}
+	return paths
+}
+
+// bundleSignaturePaths returns the paths of all of the bundle's metadata signatures in a bundle's metadata
+func bundleSignaturePaths(filepaths types.PathMap) []string {
+	var names []string
+	for name := range filepaths {
+		if config.IsBundleSignature(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	signaturePaths := make([]string, 0, len(names))
+	for _, name := range names {
+		signaturePaths = append(signaturePaths, filepaths[name])
+	}
+	return signaturePaths
+}
+
+// trustedPublicKeys expands the given keys into individual public key files, directories are expanded to the keys they
+// contain and keyrings containing multiple PEM encoded keys are split into separate files in tmpDir
+func trustedPublicKeys(keys []string, tmpDir string) ([]string, error) {
+	var publicKeyPaths []string
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		info, err := os.Stat(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read public key %s: %w", key, err)
+		}
+		keyPaths := []string{key}
+		if info.IsDir() {
+			entries, err := os.ReadDir(key)
+			if err != nil {
+				return nil, err
+			}
+			keyPaths = nil
+			for _, entry := range entries {
+				if entry.Type().IsRegular() {
+					keyPaths = append(keyPaths, filepath.Join(key, entry.Name()))
+				}
+			}
+		}
+		for _, keyPath := range keyPaths {
+			split, err := splitKeyring(keyPath, tmpDir)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range split {
+				if !slices.Contains(publicKeyPaths, p) {
+					publicKeyPaths = append(publicKeyPaths, p)
+				}
+			}
+		}
+	}
+	return publicKeyPaths, nil
+}
+
+// splitKeyring writes each PEM encoded key in a keyring to its own file in tmpDir, files with a single key are
+// returned as is
+func splitKeyring(keyPath, tmpDir string) ([]string, error) {
+	keyring, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*pem.Block
+	for rest := keyring; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) <= 1 {
+		return []string{keyPath}, nil
+	}
+
+	keyPaths := make([]string, 0, len(blocks))
+	for i, block := range blocks {
+		p := filepath.Join(tmpDir, fmt.Sprintf("%s.%d", filepath.Base(keyPath), i))
+		if err := os.WriteFile(p, pem.EncodeToMemory(block), helpers.ReadWriteUser); err != nil {
+			return nil, err
+		}
+		keyPaths = append(keyPaths, p)
+	}
+	return keyPaths, nil
+}
+
+// verifyPkgSignature verifies a Zarf package's signature against the package's public key or, if it doesn't have one,
+// against the trusted keys from the UDS config
+func (b *Bundle) verifyPkgSignature(ctx context.Context, pkg types.Package) (pkgsignature.Status, error) {
+	pkgTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(pkgTmp)
+
+	zarfYAMLPath, signaturePath, err := fetchPkgSignature(ctx, pkg, pkgArch(pkg, b.bundle.Metadata.Architecture), pkgTmp)
+	if err != nil {
+		return "", err
+	}
+
+	publicKeyPath := ""
+	if pkg.PublicKey != "" {
+		publicKeyPath = filepath.Join(pkgTmp, config.PublicKeyFile)
+		if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
+			return "", err
+		}
+	}
+	trustedKeyPaths, err := trustedPublicKeys(config.CommonOptions.TrustedKeys, pkgTmp)
+	if err != nil {
+		return "", err
+	}
+	return checkPkgSignature(ctx, zarfYAMLPath, signaturePath, publicKeyPath, trustedKeyPaths)
+}
+
+// checkPkgSignature checks a package's signature against an optional public key, a package's own public key must
+// verify its signature while the trusted keys only mark the package as verified if one of them does
+func checkPkgSignature(ctx context.Context, zarfYAMLPath, signaturePath, publicKeyPath string, trustedKeyPaths []string) (pkgsignature.Status, error) {
+	switch {
+	case signaturePath == "" && publicKeyPath != "":
+		return "", errors.New("a public key was provided but the package is not signed")
+	case signaturePath == "":
+		return pkgsignature.Unsigned, nil
+	case publicKeyPath != "":
+		if err := zarfUtils.CosignVerifyBlob(ctx, zarfYAMLPath, signaturePath, publicKeyPath); err != nil {
+			return "", fmt.Errorf("package signature did not match the provided public key: %w", err)
+		}
+		return pkgsignature.Verified, nil
+	}
+	for _, keyPath := range trustedKeyPaths {
+		if err := zarfUtils.CosignVerifyBlob(ctx, zarfYAMLPath, signaturePath, keyPath); err == nil {
+			return pkgsignature.Verified, nil
+		}
+		message.Debugf("package signature was not verified by trusted key %s", keyPath)
+	}
+	return pkgsignature.Unverified, nil
+}
+
+// fetchPkgSignature pulls the arch's Zarf package zarf.yaml and signature into dst, the signature path is empty if
+// the package isn't signed
+func fetchPkgSignature(ctx context.Context, pkg types.Package, arch, dst string) (string, string, error) {
+	paths := []string{config.ZarfYAML, config.ZarfYAMLSignature}
+	if pkg.Repository != "" {
+		platform := ocispec.Platform{
+			Architecture: arch,
+			OS:           oci.MultiOS,
+		}
+		remote, err := zoci.NewRemote(ctx, fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref), platform)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := remote.PullPaths(ctx, dst, paths); err != nil {
+			return "", "", err
+		}
+	} else {
+		for _, path := range paths {
+			zarfTarball, err := os.Open(pkg.Path)
+			if err != nil {
+				return "", "", err
+			}
+			err = config.BundleArchiveFormat.Extract(ctx, zarfTarball, utils.ExtractFile(path, dst))
+			zarfTarball.Close()
+			if err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	zarfYAMLPath := filepath.Join(dst, config.ZarfYAML)
+	if helpers.InvalidPath(zarfYAMLPath) {
+		return "", "", fmt.Errorf("%s not found in package %s", config.ZarfYAML, pkg.Name)
+	}
+	signaturePath := filepath.Join(dst, config.ZarfYAMLSignature)
+	if helpers.InvalidPath(signaturePath) {
+		signaturePath = ""
+	}
+	return zarfYAMLPath, signaturePath, nil
+}
+
+// validateSignedPackages ensures every package's signature was verified when the bundle was created
+func validateSignedPackages(bundle types.UDSBundle, packages []types.Package) error {
+	for _, pkg := range packages {
+		switch bundle.Build.PackageSignatures[pkg.Name] {
+		case pkgsignature.Verified:
+			continue
+		case pkgsignature.Unverified:
+			return fmt.Errorf("package %s is signed, but its signature was not verified by its publicKey or a trusted key", pkg.Name)
+		case pkgsignature.Unsigned:
+			return fmt.Errorf("package %s is not signed", pkg.Name)
+		default:
+			return fmt.Errorf("package %s has no recorded signature, recreate the bundle to verify its packages", pkg.Name)
+		}
+	}
+	return nil
+}
+
+// selectPackages returns the bundle's packages named by the values of --packages, in bundle order. Each value can be a
+// comma separated list of names, and names can be repeated
+func selectPackages(packages []types.Package, values []string) ([]types.Package, error) {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var selected []types.Package
+	for _, pkg := range packages {
+		if slices.Contains(names, pkg.Name) {
+			selected = append(selected, pkg)
+		}
+	}
+	var invalid []string
+	for _, name := range names {
+		if !slices.ContainsFunc(selected, func(pkg types.Package) bool { return pkg.Name == name }) {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid zarf packages specified by --packages: %s", strings.Join(invalid, ", "))
+	}
+	return selected, nil
 }
 
 // validateOverrides ensures that the overrides have matching components and charts in the zarf package
@@ -330,6 +627,49 @@ func validateBundleVars(packages []types.Package) error {
 	return nil
 }
 
+// resolveRemotePkgRef resolves a remote package's ref to the digest of its arch manifest; when a flavor is set the
+// <ref>-<flavor> tag that Zarf publishes flavored packages to is preferred, falling back to the ref itself
+func resolveRemotePkgRef(ctx context.Context, repository, ref, flavor, arch string) (string, error) {
+	refs := []string{ref}
+	if flavored := flavoredRef(ref, flavor); flavored != ref {
+		refs = []string{flavored, ref}
+	}
+
+	platform := ocispec.Platform{
+		Architecture: arch,
+		OS:           oci.MultiOS,
+	}
+	for i, r := range refs {
+		remote, err := zoci.NewRemote(ctx, fmt.Sprintf("%s:%s", repository, r), platform)
+		if err != nil {
+			return "", err
+		}
+		if err := remote.Repo().Reference.ValidateReferenceAsDigest(); err == nil {
+			return r, nil
+		}
+		manifestDesc, err := remote.ResolveRoot(ctx)
+		if err != nil {
+			// try the next ref if the flavored tag doesn't exist
+			if errors.Is(err, errdef.ErrNotFound) && i < len(refs)-1 {
+				message.Debugf("%s:%s not found, trying %s:%s", repository, r, repository, refs[i+1])
+				continue
+			}
+			return "", err
+		}
+		return r + "@sha256:" + manifestDesc.Digest.Encoded(), nil
+	}
+	return "", fmt.Errorf("unable to resolve %s:%s", repository, ref)
+}
+
+// flavoredRef returns the tag Zarf publishes a package with the given flavor to, refs that are already flavored or
+// pinned to a digest are returned as is
+func flavoredRef(ref, flavor string) string {
+	if flavor == "" || strings.Contains(ref, "@sha256:") || strings.HasSuffix(ref, "-"+flavor) {
+		return ref
+	}
+	return ref + "-" + flavor
+}
+
 // setPackageRef sets the package reference
 func (b *Bundle) setPackageRef(pkg types.Package) (types.Package, error) {
 	ctx := context.TODO()
@@ -341,26 +681,17 @@ func (b *Bundle) setPackageRef(pkg types.Package) (types.Package, error) {
 
 		errMsg := fmt.Sprintf("Unable to access %s:%s", pkg.Repository, ref)
 
-		// Get SHA from registry
-		url := fmt.Sprintf("%s:%s", pkg.Repository, ref)
-
-		platform := ocispec.Platform{
-			Architecture: config.GetArch(),
-			OS:           oci.MultiOS,
-		}
-		remote, err := zoci.NewRemote(ctx, url, platform)
-		if err != nil {
+		// refs that are already pinned to a digest can't be set
+		if strings.Contains(ref, "@sha256:") {
 			return pkg, errors.New(errMsg)
 		}
-		if err := remote.Repo().Reference.ValidateReferenceAsDigest(); err != nil {
-			manifestDesc, err := remote.ResolveRoot(ctx)
-			if err != nil {
-				return pkg, errors.New(errMsg)
-			}
-			pkg.Ref = ref + "@sha256:" + manifestDesc.Digest.Encoded()
-		} else {
+
+		// Get SHA from registry
+		resolvedRef, err := resolveRemotePkgRef(ctx, pkg.Repository, ref, pkg.Flavor, pkgArch(pkg, config.GetArch(b.bundle.Metadata.Architecture)))
+		if err != nil {
 			return pkg, errors.New(errMsg)
 		}
+		pkg.Ref = resolvedRef
 	}
 	return pkg, nil
 }
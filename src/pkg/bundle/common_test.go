@@ -5,11 +5,26 @@
 package bundle
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/pkgsignature"
 	"github.com/stretchr/testify/require"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	zarfConfig "github.com/zarf-dev/zarf/src/config"
 )
 
 func Test_validateBundleVars(t *testing.T) {
@@ -190,3 +205,370 @@ func Test_getPkgPath(t *testing.T) {
 		})
 	}
 }
+
+func Test_CalculateBuildInfo(t *testing.T) {
+	cliArch := config.CLIArch
+	t.Cleanup(func() { config.CLIArch = cliArch })
+	// a multi-arch --architecture is never read as a single arch
+	config.CLIArch = "amd64,arm64"
+
+	tests := []struct {
+		name         string
+		arch         string
+		metadataArch string
+		want         string
+	}{
+		{name: "ArchOverridesMetadata", arch: "arm64", metadataArch: "amd64", want: "arm64"},
+		{name: "MetadataArch", metadataArch: "amd64", want: "amd64"},
+		{name: "RuntimeArch", want: runtime.GOARCH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bundle{bundle: types.UDSBundle{Metadata: types.UDSMetadata{Architecture: tt.metadataArch}}}
+			require.NoError(t, b.CalculateBuildInfo(tt.arch))
+			require.Equal(t, tt.want, b.bundle.Metadata.Architecture)
+			require.Equal(t, tt.want, b.bundle.Build.Architecture)
+		})
+	}
+	require.Equal(t, "amd64,arm64", config.CLIArch)
+}
+
+func Test_flavoredRef(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		flavor string
+		want   string
+	}{
+		{name: "no flavor", ref: "0.0.1", flavor: "", want: "0.0.1"},
+		{name: "flavor", ref: "0.0.1", flavor: "upstream", want: "0.0.1-upstream"},
+		{name: "already flavored", ref: "0.0.1-upstream", flavor: "upstream", want: "0.0.1-upstream"},
+		{name: "digest", ref: "0.0.1@sha256:3f1e0d8a", flavor: "upstream", want: "0.0.1@sha256:3f1e0d8a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, flavoredRef(tt.ref, tt.flavor))
+		})
+	}
+}
+
+func Test_selectPackages(t *testing.T) {
+	packages := []types.Package{{Name: "init"}, {Name: "podinfo"}, {Name: "nginx"}}
+	tests := []struct {
+		name    string
+		values  []string
+		want    []string
+		wantErr string
+	}{
+		{name: "single value", values: []string{"nginx,init"}, want: []string{"init", "nginx"}},
+		{name: "repeated flag", values: []string{"nginx", "podinfo"}, want: []string{"podinfo", "nginx"}},
+		{name: "spaces and duplicates", values: []string{"nginx, init", "nginx"}, want: []string{"init", "nginx"}},
+		{name: "invalid package", values: []string{"nginx", "missing"}, wantErr: "invalid zarf packages specified by --packages: missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectPackages(packages, tt.values)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, pkg := range selected {
+				names = append(names, pkg.Name)
+			}
+			require.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func Test_pkgArch(t *testing.T) {
+	require.Equal(t, "arm64", pkgArch(types.Package{Name: "podinfo"}, "arm64"))
+	require.Equal(t, "amd64", pkgArch(types.Package{Name: "podinfo", Architecture: "amd64"}, "arm64"))
+}
+
+func Test_resolveRemotePkgRef(t *testing.T) {
+	plainHTTP := zarfConfig.CommonOptions.PlainHTTP
+	zarfConfig.CommonOptions.PlainHTTP = true
+	t.Cleanup(func() { zarfConfig.CommonOptions.PlainHTTP = plainHTTP })
+
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+	amd64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "amd64"})
+	arm64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "arm64"})
+	ocitest.PushIndex(t, repo, "0.0.1", amd64.Root, arm64.Root)
+	ocitest.PushIndex(t, repo, "0.0.1-registry1", arm64.Root)
+	repository := repo.Reference.Registry + "/" + repo.Reference.Repository
+
+	tests := []struct {
+		name   string
+		pkg    types.Package
+		want   string
+		errMsg string
+	}{
+		{name: "bundle arch", pkg: types.Package{Ref: "0.0.1"}, want: "0.0.1@" + arm64.Root.Digest.String()},
+		{name: "package arch", pkg: types.Package{Ref: "0.0.1", Architecture: "amd64"}, want: "0.0.1@" + amd64.Root.Digest.String()},
+		{name: "flavored tag", pkg: types.Package{Ref: "0.0.1", Flavor: "registry1"}, want: "0.0.1-registry1@" + arm64.Root.Digest.String()},
+		{name: "missing flavored tag falls back to ref", pkg: types.Package{Ref: "0.0.1", Flavor: "upstream", Architecture: "amd64"}, want: "0.0.1@" + amd64.Root.Digest.String()},
+		{name: "missing arch", pkg: types.Package{Ref: "0.0.1-registry1", Architecture: "amd64"}, errMsg: "no matching manifest"},
+		{name: "pinned digest", pkg: types.Package{Ref: "0.0.1@" + amd64.Root.Digest.String()}, want: "0.0.1@" + amd64.Root.Digest.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := resolveRemotePkgRef(context.Background(), repository, tt.pkg.Ref, tt.pkg.Flavor, pkgArch(tt.pkg, "arm64"))
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ref)
+		})
+	}
+}
+
+func Test_checkPkgSignature(t *testing.T) {
+	tmp := t.TempDir()
+	zarfYAMLPath := filepath.Join(tmp, "zarf.yaml")
+	zarfYAML := []byte("kind: ZarfPackageConfig\n")
+	require.NoError(t, os.WriteFile(zarfYAMLPath, zarfYAML, 0o600))
+	signaturePath := filepath.Join(tmp, "zarf.yaml.sig")
+	key, sign := writeSigningKey(t, tmp, "key.pub")
+	otherKey, _ := writeSigningKey(t, tmp, "other.pub")
+	sign(zarfYAML, signaturePath)
+
+	tests := []struct {
+		name            string
+		signaturePath   string
+		publicKeyPath   string
+		trustedKeyPaths []string
+		want            pkgsignature.Status
+		wantErr         bool
+	}{
+		{name: "unsigned", want: pkgsignature.Unsigned},
+		{name: "unsigned with trusted keys", trustedKeyPaths: []string{key}, want: pkgsignature.Unsigned},
+		{name: "signed without key", signaturePath: signaturePath, want: pkgsignature.Unverified},
+		{name: "key without signature", publicKeyPath: key, wantErr: true},
+		{name: "verified by public key", signaturePath: signaturePath, publicKeyPath: key, want: pkgsignature.Verified},
+		{name: "wrong public key", signaturePath: signaturePath, publicKeyPath: otherKey, trustedKeyPaths: []string{key}, wantErr: true},
+		{name: "verified by trusted key", signaturePath: signaturePath, trustedKeyPaths: []string{otherKey, key}, want: pkgsignature.Verified},
+		{name: "not verified by trusted keys", signaturePath: signaturePath, trustedKeyPaths: []string{otherKey}, want: pkgsignature.Unverified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := checkPkgSignature(context.Background(), zarfYAMLPath, tt.signaturePath, tt.publicKeyPath, tt.trustedKeyPaths)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func Test_validateSignedPackages(t *testing.T) {
+	bundle := types.UDSBundle{
+		Build: types.UDSBuildData{
+			PackageSignatures: map[string]pkgsignature.Status{
+				"verified":   pkgsignature.Verified,
+				"unverified": pkgsignature.Unverified,
+				"unsigned":   pkgsignature.Unsigned,
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		pkgs    []types.Package
+		wantErr bool
+	}{
+		{name: "verified packages", pkgs: []types.Package{{Name: "verified"}}},
+		{name: "unverified package", pkgs: []types.Package{{Name: "verified"}, {Name: "unverified"}}, wantErr: true},
+		{name: "unsigned package", pkgs: []types.Package{{Name: "verified"}, {Name: "unsigned"}}, wantErr: true},
+		{name: "unrecorded package", pkgs: []types.Package{{Name: "unknown"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSignedPackages(bundle, tt.pkgs)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// writeSigningKey generates an ECDSA key, writes its PEM encoded public key to dir and returns a function that writes a
+// cosign compatible signature of a blob
+func writeSigningKey(t *testing.T, dir, name string) (string, func(blob []byte, sigPath string)) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	sign := func(blob []byte, sigPath string) {
+		digest := sha256.Sum256(blob)
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(sig)), 0o600))
+	}
+	return keyPath, sign
+}
+
+func Test_ValidateBundleSignature(t *testing.T) {
+	tmp := t.TempDir()
+	bundleYAMLPath := filepath.Join(tmp, "uds-bundle.yaml")
+	bundleYAML := []byte("kind: UDSBundle\n")
+	require.NoError(t, os.WriteFile(bundleYAMLPath, bundleYAML, 0o600))
+
+	oldKey, signOld := writeSigningKey(t, tmp, "old.pub")
+	newKey, signNew := writeSigningKey(t, tmp, "new.pub")
+	otherKey, _ := writeSigningKey(t, tmp, "other.pub")
+	oldSig := filepath.Join(tmp, "old.sig")
+	signOld(bundleYAML, oldSig)
+	newSig := filepath.Join(tmp, "new.sig")
+	signNew(bundleYAML, newSig)
+
+	tests := []struct {
+		name           string
+		signaturePaths []string
+		publicKeyPaths []string
+		wantErr        bool
+	}{
+		{name: "unsigned without keys"},
+		{name: "unsigned with key", publicKeyPaths: []string{oldKey}, wantErr: true},
+		{name: "signed without key", signaturePaths: []string{oldSig}, wantErr: true},
+		{name: "signed with matching key", signaturePaths: []string{oldSig}, publicKeyPaths: []string{oldKey}},
+		{name: "signed with wrong key", signaturePaths: []string{oldSig}, publicKeyPaths: []string{otherKey}, wantErr: true},
+		{name: "old signature with rotated keys", signaturePaths: []string{oldSig}, publicKeyPaths: []string{newKey, oldKey}},
+		{name: "both signatures with new key", signaturePaths: []string{oldSig, newSig}, publicKeyPaths: []string{newKey}},
+		{name: "both signatures with other key", signaturePaths: []string{oldSig, newSig}, publicKeyPaths: []string{otherKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBundleSignature(bundleYAMLPath, tt.signaturePaths, tt.publicKeyPaths)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_validateBundleSignatures(t *testing.T) {
+	keysDir := t.TempDir()
+	key, sign := writeSigningKey(t, keysDir, "key.pub")
+	otherKey, _ := writeSigningKey(t, keysDir, "other.pub")
+
+	unsignedDir, _ := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+	sidecarDir, sidecarBundle := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+	sign([]byte(sidecarBundle.Root.Digest.String()), sidecarDir+config.BundleSignatureSidecarExt)
+
+	tests := []struct {
+		name          string
+		source        string
+		embedded      bool
+		keys          []string
+		trustedKeys   []string
+		requireSigned bool
+		wantVerified  bool
+		wantErr       bool
+	}{
+		{name: "unsigned without keys", source: unsignedDir},
+		{name: "unsigned with trusted keys", source: unsignedDir, trustedKeys: []string{key}},
+		{name: "unsigned with trusted keys and require signed", source: unsignedDir, trustedKeys: []string{key}, requireSigned: true, wantErr: true},
+		{name: "unsigned with require signed", source: unsignedDir, requireSigned: true, wantErr: true},
+		{name: "unsigned with key", source: unsignedDir, keys: []string{key}, trustedKeys: []string{key}, wantErr: true},
+		{name: "sidecar signature with key", source: sidecarDir, keys: []string{key}, wantVerified: true},
+		{name: "sidecar signature with trusted key", source: sidecarDir, trustedKeys: []string{otherKey, key}, requireSigned: true, wantVerified: true},
+		{name: "sidecar signature with wrong key", source: sidecarDir, keys: []string{otherKey}, wantErr: true},
+		{name: "sidecar signature without keys", source: sidecarDir, wantErr: true},
+		{name: "embedded signature with trusted key and require signed", source: unsignedDir, embedded: true, trustedKeys: []string{key}, requireSigned: true, wantVerified: true},
+		{name: "embedded signature with wrong key", source: unsignedDir, embedded: true, keys: []string{otherKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trustedKeys, requireSigned := config.CommonOptions.TrustedKeys, config.CommonOptions.RequireSigned
+			config.CommonOptions.TrustedKeys, config.CommonOptions.RequireSigned = tt.trustedKeys, tt.requireSigned
+			t.Cleanup(func() {
+				config.CommonOptions.TrustedKeys, config.CommonOptions.RequireSigned = trustedKeys, requireSigned
+			})
+
+			tmp := t.TempDir()
+			provider, err := NewBundleProvider(tt.source, tmp)
+			require.NoError(t, err)
+			filepaths, err := provider.LoadBundleMetadata()
+			require.NoError(t, err)
+			if tt.embedded {
+				bundleYAML, err := os.ReadFile(filepaths[config.BundleYAML])
+				require.NoError(t, err)
+				filepaths[config.BundleYAMLSignature] = filepath.Join(tmp, config.BundleYAMLSignature)
+				sign(bundleYAML, filepaths[config.BundleYAMLSignature])
+			}
+
+			verified, err := validateBundleSignatures(context.Background(), provider, tt.source, filepaths, tt.keys, tmp)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVerified, verified)
+		})
+	}
+}
+
+func Test_bundleSignaturePaths(t *testing.T) {
+	filepaths := types.PathMap{
+		"uds-bundle.yaml":       "/tmp/blobs/sha256/a",
+		"uds-bundle.yaml.sig.1": "/tmp/blobs/sha256/c",
+		"uds-bundle.yaml.sig":   "/tmp/blobs/sha256/b",
+	}
+	require.Equal(t, []string{"/tmp/blobs/sha256/b", "/tmp/blobs/sha256/c"}, bundleSignaturePaths(filepaths))
+	require.Empty(t, bundleSignaturePaths(types.PathMap{"uds-bundle.yaml": "/tmp/blobs/sha256/a"}))
+}
+
+func Test_trustedPublicKeys(t *testing.T) {
+	keysDir := t.TempDir()
+	keyA, _ := writeSigningKey(t, keysDir, "a.pub")
+	keyB, _ := writeSigningKey(t, keysDir, "b.pub")
+
+	// a keyring containing both keys
+	keyringDir := t.TempDir()
+	keyring := filepath.Join(keyringDir, "keyring.pem")
+	keyABytes, err := os.ReadFile(keyA)
+	require.NoError(t, err)
+	keyBBytes, err := os.ReadFile(keyB)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyring, append(keyABytes, keyBBytes...), 0o600))
+
+	t.Run("key files", func(t *testing.T) {
+		keys, err := trustedPublicKeys([]string{keyA, "", keyB, keyA}, t.TempDir())
+		require.NoError(t, err)
+		require.Equal(t, []string{keyA, keyB}, keys)
+	})
+
+	t.Run("directory of keys", func(t *testing.T) {
+		keys, err := trustedPublicKeys([]string{keysDir}, t.TempDir())
+		require.NoError(t, err)
+		require.Equal(t, []string{keyA, keyB}, keys)
+	})
+
+	t.Run("keyring", func(t *testing.T) {
+		keys, err := trustedPublicKeys([]string{keyring}, t.TempDir())
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		for i, want := range [][]byte{keyABytes, keyBBytes} {
+			got, err := os.ReadFile(keys[i])
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := trustedPublicKeys([]string{filepath.Join(keysDir, "missing.pub")}, t.TempDir())
+		require.Error(t, err)
+	})
+}
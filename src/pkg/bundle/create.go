@@ -7,13 +7,17 @@ package bundle
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler"
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
 	"github.com/pterm/pterm"
 	zarfConfig "github.com/zarf-dev/zarf/src/config"
 	"github.com/zarf-dev/zarf/src/pkg/interactive"
@@ -24,6 +28,133 @@ import (
 
 // Create creates a bundle
 func (b *Bundle) Create(ctx context.Context) error {
+	if err := b.loadBundleDefinition(); err != nil {
+		return err
+	}
+
+	// confirm creation
+	if ok := b.confirmBundleCreation(); !ok {
+		return errors.New("bundle creation cancelled")
+	}
+
+	// a comma-separated --architecture creates a single multi-arch bundle
+	archs := config.ParseArchitectures(config.CLIArch)
+	if len(archs) > 1 {
+		return b.createMultiArch(ctx, archs)
+	}
+	arch := ""
+	if len(archs) == 1 {
+		arch = archs[0]
+	}
+
+	signatures, err := b.prepareBundle(arch)
+	if err != nil {
+		return err
+	}
+	statement, err := b.newProvenance()
+	if err != nil {
+		return err
+	}
+	base, cleanupBase, err := b.createBase(ctx, []string{b.bundle.Metadata.Architecture})
+	if err != nil {
+		return err
+	}
+	defer cleanupBase()
+	deltaFrom, cleanupDeltaFrom, err := b.createDeltaBase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanupDeltaFrom()
+
+	opts := bundler.Options{
+		Bundle:     &b.bundle,
+		Signatures: map[string][][]byte{b.bundle.Metadata.Architecture: signatures},
+		Provenance: map[string]*provenance.Statement{b.bundle.Metadata.Architecture: statement},
+		Output:     b.cfg.CreateOpts.Output,
+		TmpDstDir:  b.tmp,
+		SourceDir:  b.cfg.CreateOpts.SourceDirectory,
+
+		MaxPackageSizeMB: b.cfg.CreateOpts.MaxPackageSizeMB,
+		Base:             base,
+		DeltaFrom:        deltaFrom,
+	}
+	bundlerClient := bundler.NewBundler(&opts)
+
+	return bundlerClient.Create(ctx)
+}
+
+// createMultiArch creates a single bundle containing a bundle root manifest for each of the given architectures
+func (b *Bundle) createMultiArch(ctx context.Context, archs []string) error {
+	bundles := make([]*types.UDSBundle, 0, len(archs))
+	archSignatures := make(map[string][][]byte, len(archs))
+	archProvenance := make(map[string]*provenance.Statement, len(archs))
+	for _, arch := range archs {
+		// reload the bundle so that each arch resolves its own package refs and paths
+		if err := b.loadBundleDefinition(); err != nil {
+			return err
+		}
+		signatures, err := b.prepareBundle(arch)
+		if err != nil {
+			return err
+		}
+		archSignatures[arch] = signatures
+		if archProvenance[arch], err = b.newProvenance(); err != nil {
+			return err
+		}
+		archBundle := b.bundle
+		bundles = append(bundles, &archBundle)
+	}
+	base, cleanupBase, err := b.createBase(ctx, archs)
+	if err != nil {
+		return err
+	}
+	defer cleanupBase()
+	deltaFrom, cleanupDeltaFrom, err := b.createDeltaBase(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanupDeltaFrom()
+
+	opts := bundler.Options{
+		Bundles:    bundles,
+		Signatures: archSignatures,
+		Provenance: archProvenance,
+		Output:     b.cfg.CreateOpts.Output,
+		TmpDstDir:  b.tmp,
+		SourceDir:  b.cfg.CreateOpts.SourceDirectory,
+
+		MaxPackageSizeMB: b.cfg.CreateOpts.MaxPackageSizeMB,
+		Base:             base,
+		DeltaFrom:        deltaFrom,
+	}
+	bundlerClient := bundler.NewBundler(&opts)
+
+	return bundlerClient.CreateMultiArch(ctx)
+}
+
+// createBase loads the bundle given to --base, if any, into a temp dir, returning a func that removes the temp dir once
+// the bundle is created
+func (b *Bundle) createBase(ctx context.Context, archs []string) (*fetcher.Base, func(), error) {
+	if b.cfg.CreateOpts.Base == "" {
+		return nil, func() {}, nil
+	}
+	baseTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(baseTmp) }
+	base, err := b.loadBase(ctx, archs, baseTmp)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return base, cleanup, nil
+}
+
+// loadBundleDefinition reads the uds-bundle.yaml into memory and applies the create flags and values files
+func (b *Bundle) loadBundleDefinition() error {
+	b.bundle = types.UDSBundle{}
+
 	// read the bundle's metadata into memory
 	if err := utils.ReadYAMLStrict(filepath.Join(b.cfg.CreateOpts.SourceDirectory, b.cfg.CreateOpts.BundleFile), &b.bundle); err != nil {
 		return err
@@ -38,18 +169,24 @@ func (b *Bundle) Create(ctx context.Context) error {
 	}
 
 	// Populate values from valuesFiles if provided
-	if err := b.processValuesFiles(); err != nil {
-		return err
-	}
+	return b.processValuesFiles()
+}
 
-	// confirm creation
-	if ok := b.confirmBundleCreation(); !ok {
-		return errors.New("bundle creation cancelled")
+// prepareBundle calculates the bundle's build info, validates its resources and signs it for arch, returning the
+// bundle's signatures. An empty arch falls back to the bundle's metadata.architecture
+func (b *Bundle) prepareBundle(arch string) ([][]byte, error) {
+	// for dev mode apply the --flavor flag to remote packages so their refs resolve to the flavored package
+	if config.Dev && len(b.cfg.DevDeployOpts.Flavor) != 0 {
+		for i, pkg := range b.bundle.Packages {
+			if pkg.Repository != "" {
+				b.bundle.Packages[i] = b.setPackageFlavor(pkg)
+			}
+		}
 	}
 
 	// make the bundle's build information
-	if err := b.CalculateBuildInfo(); err != nil {
-		return err
+	if err := b.CalculateBuildInfo(arch); err != nil {
+		return nil, err
 	}
 
 	// populate Zarf config
@@ -61,34 +198,12 @@ func (b *Bundle) Create(ctx context.Context) error {
 
 	// validate bundle / verify access to all repositories
 	if err := b.ValidateBundleResources(validateSpinner); err != nil {
-		return err
+		return nil, err
 	}
 
 	validateSpinner.Successf("Bundle Validated")
 	pterm.Print()
 
-	// sign the bundle if a signing key was provided
-	if b.cfg.CreateOpts.SigningKeyPath != "" {
-		// write the bundle to disk so we can sign it
-		bundlePath := filepath.Join(b.tmp, config.BundleYAML)
-		if err := zarfUtils.WriteYaml(bundlePath, &b.bundle, 0o600); err != nil {
-			return err
-		}
-
-		getSigCreatePassword := func(_ bool) ([]byte, error) {
-			if b.cfg.CreateOpts.SigningKeyPassword != "" {
-				return []byte(b.cfg.CreateOpts.SigningKeyPassword), nil
-			}
-			return interactive.PromptSigPassword()
-		}
-		// sign the bundle
-		signaturePath := filepath.Join(b.tmp, config.BundleYAMLSignature)
-		_, err := zarfUtils.CosignSignBlob(bundlePath, signaturePath, b.cfg.CreateOpts.SigningKeyPath, getSigCreatePassword)
-		if err != nil {
-			return err
-		}
-	}
-
 	// for dev mode update package ref for local bundles, refs for remote bundles updated on deploy
 	if config.Dev && len(b.cfg.DevDeployOpts.Ref) != 0 {
 		for i, pkg := range b.bundle.Packages {
@@ -97,15 +212,40 @@ func (b *Bundle) Create(ctx context.Context) error {
 		}
 	}
 
-	opts := bundler.Options{
-		Bundle:    &b.bundle,
-		Output:    b.cfg.CreateOpts.Output,
-		TmpDstDir: b.tmp,
-		SourceDir: b.cfg.CreateOpts.SourceDirectory,
+	// sign the bundle if signing keys were provided
+	return b.signBundle()
+}
+
+// signBundle signs the bundle's metadata with each of the signing keys, signing with both the old and new keys allows
+// deployers to rotate the keys they trust
+func (b *Bundle) signBundle() ([][]byte, error) {
+	if len(b.cfg.CreateOpts.SigningKeyPaths) == 0 {
+		return nil, nil
 	}
-	bundlerClient := bundler.NewBundler(&opts)
 
-	return bundlerClient.Create(ctx)
+	// write the bundle to disk so we can sign it
+	bundlePath := filepath.Join(b.tmp, config.BundleYAML)
+	if err := zarfUtils.WriteYaml(bundlePath, &b.bundle, 0o600); err != nil {
+		return nil, err
+	}
+
+	getSigCreatePassword := func(_ bool) ([]byte, error) {
+		if b.cfg.CreateOpts.SigningKeyPassword != "" {
+			return []byte(b.cfg.CreateOpts.SigningKeyPassword), nil
+		}
+		return interactive.PromptSigPassword()
+	}
+
+	signatures := make([][]byte, 0, len(b.cfg.CreateOpts.SigningKeyPaths))
+	for i, signingKeyPath := range b.cfg.CreateOpts.SigningKeyPaths {
+		signaturePath := filepath.Join(b.tmp, config.BundleSignatureName(i))
+		signature, err := zarfUtils.CosignSignBlob(bundlePath, signaturePath, signingKeyPath, getSigCreatePassword)
+		if err != nil {
+			return nil, fmt.Errorf("unable to sign bundle with %s: %w", signingKeyPath, err)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
 }
 
 // confirmBundleCreation prompts the user to confirm bundle creation
@@ -0,0 +1,240 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundler"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+)
+
+// errDeltaSource is returned when the bundle a delta bundle is created against or applied to isn't a local bundle
+var errDeltaSource = errors.New("must be a local bundle tarball or OCI directory")
+
+// localBundlePath returns the path of a local bundle tarball or OCI directory, reassembling split tarballs into dst
+func localBundlePath(source, dst string) (string, error) {
+	if utils.IsValidBundleDir(source) {
+		return source, nil
+	}
+	if !utils.IsValidTarballPath(source) {
+		return "", fmt.Errorf("%s %w", source, errDeltaSource)
+	}
+	if utils.IsSplitFile(source) {
+		return utils.ReassembleSplitFile(source, dst)
+	}
+	return source, nil
+}
+
+// loadDeltaBase lists the blobs of the older bundle given to --delta-from
+func loadDeltaBase(ctx context.Context, source, dst string) (*bundler.DeltaBase, error) {
+	src, err := localBundlePath(source, dst)
+	if err != nil {
+		return nil, err
+	}
+	base := &bundler.DeltaBase{Source: source, Blobs: make(map[string]bool)}
+
+	if helpers.IsDir(src) {
+		entries, err := os.ReadDir(filepath.Join(src, config.BlobsDir))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				base.Blobs[entry.Name()] = true
+			}
+		}
+		return base, nil
+	}
+
+	bundleFile, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer bundleFile.Close()
+	err = config.BundleArchiveFormat.Extract(ctx, bundleFile, func(_ context.Context, file archives.FileInfo) error {
+		if digest, ok := strings.CutPrefix(file.NameInArchive, config.BlobsDir+"/"); ok && !file.IsDir() {
+			base.Blobs[digest] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", source, err)
+	}
+	return base, nil
+}
+
+// createDeltaBase loads the bundle given to --delta-from, if any, returning a func that removes the temp dir a split
+// tarball is reassembled into once the bundle is created
+func (b *Bundle) createDeltaBase(ctx context.Context) (*bundler.DeltaBase, func(), error) {
+	if b.cfg.CreateOpts.DeltaFrom == "" {
+		return nil, func() {}, nil
+	}
+	deltaTmp, err := os.MkdirTemp(b.tmp, "delta-from")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(deltaTmp) }
+	base, err := loadDeltaBase(ctx, b.cfg.CreateOpts.DeltaFrom, deltaTmp)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("unable to load --delta-from bundle: %w", err)
+	}
+	message.Debugf("Loaded %d blobs from delta base bundle %s", len(base.Blobs), b.cfg.CreateOpts.DeltaFrom)
+	return base, cleanup, nil
+}
+
+// ApplyDelta rebuilds a bundle tarball from a delta bundle and the older bundle it was created against, verifying the
+// rebuilt tarball against the digest recorded in the delta bundle
+func (b *Bundle) ApplyDelta() error {
+	ctx := context.TODO()
+	opts := b.cfg.ApplyDeltaOpts
+
+	deltaDir := filepath.Join(b.tmp, "delta")
+	spinner := message.NewProgressSpinner("Reading delta bundle %s", opts.Delta)
+	defer spinner.Stop()
+	deltaFile, err := os.Open(opts.Delta)
+	if err != nil {
+		return err
+	}
+	defer deltaFile.Close()
+	if err := config.BundleArchiveFormat.Extract(ctx, deltaFile, utils.ExtractAllFiles(deltaDir)); err != nil {
+		return fmt.Errorf("unable to extract delta bundle %s: %w", opts.Delta, err)
+	}
+	deltaJSON, err := os.ReadFile(filepath.Join(deltaDir, config.BundleDeltaJSON))
+	if err != nil {
+		return fmt.Errorf("%s is not a delta bundle: %w", opts.Delta, err)
+	}
+	var delta types.BundleDelta
+	if err := json.Unmarshal(deltaJSON, &delta); err != nil {
+		return fmt.Errorf("unable to read %s from %s: %w", config.BundleDeltaJSON, opts.Delta, err)
+	}
+	spinner.Successf("Read delta bundle %s for %s", opts.Delta, delta.Bundle)
+
+	pathMap, err := deltaPathMap(deltaDir)
+	if err != nil {
+		return err
+	}
+	baseBlobs, err := b.extractDeltaBaseBlobs(ctx, opts.Base, delta)
+	if err != nil {
+		return err
+	}
+	for digest, path := range baseBlobs {
+		pathMap[path] = filepath.Join(config.BlobsDir, digest)
+	}
+
+	if err := os.MkdirAll(opts.Output, 0o755); err != nil {
+		return err
+	}
+	dst := filepath.Join(opts.Output, delta.Bundle)
+	if err := utils.WriteBundleArchive(dst, pathMap); err != nil {
+		return err
+	}
+	if err := verifyDeltaBundle(dst, delta); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	message.Successf("Rebuilt %s and verified its sha256sum %s", dst, delta.Sha256Sum)
+	return nil
+}
+
+// deltaPathMap maps the files extracted from a delta bundle to their paths in the rebuilt bundle tarball
+func deltaPathMap(deltaDir string) (types.PathMap, error) {
+	pathMap := types.PathMap{
+		filepath.Join(deltaDir, ocispec.ImageIndexFile):  ocispec.ImageIndexFile,
+		filepath.Join(deltaDir, ocispec.ImageLayoutFile): ocispec.ImageLayoutFile,
+	}
+	entries, err := os.ReadDir(filepath.Join(deltaDir, config.BlobsDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		pathMap[filepath.Join(deltaDir, config.BlobsDir, entry.Name())] = filepath.Join(config.BlobsDir, entry.Name())
+	}
+	return pathMap, nil
+}
+
+// extractDeltaBaseBlobs returns the paths of the blobs a delta bundle reads from the older bundle, extracting them when
+// the older bundle is a tarball. Each blob is verified against its digest
+func (b *Bundle) extractDeltaBaseBlobs(ctx context.Context, source string, delta types.BundleDelta) (map[string]string, error) {
+	baseDir := filepath.Join(b.tmp, "base")
+	if err := os.MkdirAll(baseDir, 0o700); err != nil {
+		return nil, err
+	}
+	src, err := localBundlePath(source, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make(map[string]string, len(delta.BaseBlobs))
+	if helpers.IsDir(src) {
+		for _, digest := range delta.BaseBlobs {
+			blobs[digest] = filepath.Join(src, config.BlobsDir, digest)
+		}
+	} else {
+		spinner := message.NewProgressSpinner("Extracting %d blobs from %s", len(delta.BaseBlobs), source)
+		defer spinner.Stop()
+		wanted := make(map[string]bool, len(delta.BaseBlobs))
+		for _, digest := range delta.BaseBlobs {
+			wanted[filepath.Join(config.BlobsDir, digest)] = true
+		}
+		bundleFile, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer bundleFile.Close()
+		err = config.BundleArchiveFormat.Extract(ctx, bundleFile, func(ctx context.Context, file archives.FileInfo) error {
+			if !wanted[file.NameInArchive] {
+				return nil
+			}
+			return utils.ExtractFile(file.NameInArchive, baseDir)(ctx, file)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract blobs from %s: %w", source, err)
+		}
+		for _, digest := range delta.BaseBlobs {
+			blobs[digest] = filepath.Join(baseDir, config.BlobsDir, digest)
+		}
+		spinner.Successf("Extracted %d blobs from %s", len(delta.BaseBlobs), source)
+	}
+
+	for digest, path := range blobs {
+		if helpers.InvalidPath(path) {
+			return nil, fmt.Errorf("%s is missing blob %s, the delta bundle was created against %s", source, digest, delta.From)
+		}
+		if err := helpers.SHAsMatch(path, digest); err != nil {
+			return nil, fmt.Errorf("blob %s in %s is invalid: %w", digest, source, err)
+		}
+	}
+	return blobs, nil
+}
+
+// verifyDeltaBundle checks that a bundle tarball rebuilt from a delta bundle is the bundle tarball the delta bundle
+// was created from
+func verifyDeltaBundle(path string, delta types.BundleDelta) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != delta.Bytes {
+		return fmt.Errorf("rebuilt bundle %s is %d bytes, expected %d", path, info.Size(), delta.Bytes)
+	}
+	if err := helpers.SHAsMatch(path, delta.Sha256Sum); err != nil {
+		return fmt.Errorf("rebuilt bundle %s does not match the delta bundle: %w", path, err)
+	}
+	return nil
+}
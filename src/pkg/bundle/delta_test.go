@@ -0,0 +1,100 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestArchive writes files to dir and archives them at dst, returning the path map of the written files
+func writeTestArchive(t *testing.T, dir, dst string, files map[string]string) types.PathMap {
+	t.Helper()
+	pathMap := make(types.PathMap)
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+		pathMap[path] = name
+	}
+	require.NoError(t, utils.WriteBundleArchive(dst, pathMap))
+	return pathMap
+}
+
+func TestApplyDelta(t *testing.T) {
+	blob := func(contents string) (string, string) {
+		return filepath.Join(config.BlobsDir, fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))), contents
+	}
+	sharedPath, shared := blob("shared blob")
+	oldPath, old := blob("old blob")
+	newPath, added := blob("new blob")
+	layout := `{"imageLayoutVersion":"1.0.0"}`
+
+	dir := t.TempDir()
+	oldBundle := filepath.Join(dir, "uds-bundle-example-amd64-0.0.1.tar.zst")
+	oldFiles := map[string]string{"index.json": `{"old":true}`, "oci-layout": layout, sharedPath: shared, oldPath: old}
+	writeTestArchive(t, filepath.Join(dir, "old"), oldBundle, oldFiles)
+	oldDir := filepath.Join(dir, "old")
+
+	newBundle := filepath.Join(dir, "new", "uds-bundle-example-amd64-0.0.2.tar.zst")
+	require.NoError(t, os.MkdirAll(filepath.Dir(newBundle), 0o700))
+	writeTestArchive(t, filepath.Join(dir, "new-src"), newBundle, map[string]string{"index.json": `{"new":true}`, "oci-layout": layout, sharedPath: shared, newPath: added})
+	sum, err := helpers.GetSHA256OfFile(newBundle)
+	require.NoError(t, err)
+	info, err := os.Stat(newBundle)
+	require.NoError(t, err)
+
+	deltaJSON, err := utils.JSONValue(types.BundleDelta{
+		Bundle:    filepath.Base(newBundle),
+		Sha256Sum: sum,
+		Bytes:     info.Size(),
+		From:      filepath.Base(oldBundle),
+		BaseBlobs: []string{filepath.Base(sharedPath)},
+	})
+	require.NoError(t, err)
+	delta := filepath.Join(dir, "uds-delta-example-amd64-0.0.2.tar.zst")
+	writeTestArchive(t, filepath.Join(dir, "delta-src"), delta, map[string]string{config.BundleDeltaJSON: deltaJSON, "index.json": `{"new":true}`, "oci-layout": layout, newPath: added})
+
+	tamperedBundle := filepath.Join(dir, "tampered", "uds-bundle-example-amd64-0.0.1.tar.zst")
+	require.NoError(t, os.MkdirAll(filepath.Dir(tamperedBundle), 0o700))
+	writeTestArchive(t, filepath.Join(dir, "tampered-src"), tamperedBundle, map[string]string{"index.json": `{"old":true}`, "oci-layout": layout, sharedPath: "tampered blob"})
+
+	tests := []struct {
+		name    string
+		base    string
+		wantErr string
+	}{
+		{name: "older bundle tarball", base: oldBundle},
+		{name: "older bundle OCI dir", base: oldDir},
+		{name: "tampered blob in the older bundle", base: tamperedBundle, wantErr: "is invalid"},
+		{name: "blob missing from the older bundle", base: filepath.Join(dir, "delta-src"), wantErr: "is missing blob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := t.TempDir()
+			b := &Bundle{
+				cfg: &types.BundleConfig{ApplyDeltaOpts: types.BundleApplyDeltaOptions{Base: tt.base, Delta: delta, Output: output}},
+				tmp: t.TempDir(),
+			}
+			err := b.ApplyDelta()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.NoFileExists(t, filepath.Join(output, filepath.Base(newBundle)))
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, helpers.SHAsMatch(filepath.Join(output, filepath.Base(newBundle)), sum))
+		})
+	}
+}
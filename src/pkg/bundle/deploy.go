@@ -17,6 +17,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/uds-cli/src/types/chartvariable"
 	"github.com/defenseunicorns/uds-cli/src/types/valuesources"
@@ -40,20 +41,11 @@ func (b *Bundle) Deploy(ctx context.Context) error {
 
 	// Check if --packages flag is set and zarf packages have been specified
 	if len(b.cfg.DeployOpts.Packages) != 0 {
-		userSpecifiedPackages := strings.Split(strings.ReplaceAll(b.cfg.DeployOpts.Packages[0], " ", ""), ",")
-		var selectedPackages []types.Package
-		for _, pkg := range b.bundle.Packages {
-			if slices.Contains(userSpecifiedPackages, pkg.Name) {
-				selectedPackages = append(selectedPackages, pkg)
-			}
+		selectedPackages, err := selectPackages(b.bundle.Packages, b.cfg.DeployOpts.Packages)
+		if err != nil {
+			return err
 		}
-
 		packagesToDeploy = selectedPackages
-
-		// Check if invalid packages were specified
-		if len(userSpecifiedPackages) != len(packagesToDeploy) {
-			return errors.New("invalid zarf packages specified by --packages")
-		}
 	}
 
 	// if resume, filter for packages not yet deployed
@@ -69,6 +61,12 @@ func (b *Bundle) Deploy(ctx context.Context) error {
 		}
 	}
 
+	if b.cfg.DeployOpts.RequireSignedPackages {
+		if err := validateSignedPackages(b.bundle, packagesToDeploy); err != nil {
+			return fmt.Errorf("--require-signed-packages: %w", err)
+		}
+	}
+
 	return deployPackages(ctx, packagesToDeploy, b)
 }
 
@@ -79,7 +77,7 @@ func deployPackages(ctx context.Context, packagesToDeploy []types.Package, b *Bu
 	// setup each package client and deploy
 	for i, pkg := range packagesToDeploy {
 		// for dev mode update package ref for remote bundles, refs for local bundles updated on create
-		if config.Dev && !strings.Contains(b.cfg.DeployOpts.Source, "tar.zst") {
+		if config.Dev && !strings.Contains(b.cfg.DeployOpts.Source, "tar.zst") && !utils.IsValidBundleDir(b.cfg.DeployOpts.Source) {
 			pkg, err := b.setPackageRef(pkg)
 			if err != nil {
 				return err
@@ -248,6 +246,7 @@ func (b *Bundle) PreDeployValidation() (string, string, string, error) {
 	if err != nil {
 		return "", "", "", err
 	}
+	b.cfg.DeployOpts.Source = localBundleSource(provider, b.cfg.DeployOpts.Source)
 
 	// pull the bundle's metadata + sig
 	filepaths, err := provider.LoadBundleMetadata()
@@ -256,10 +255,17 @@ func (b *Bundle) PreDeployValidation() (string, string, string, error) {
 	}
 
 	// validate the sig (if present)
-	if err := ValidateBundleSignature(filepaths[config.BundleYAML], filepaths[config.BundleYAMLSignature], b.cfg.DeployOpts.PublicKeyPath); err != nil {
+	verified, err := validateBundleSignatures(context.TODO(), provider, source, filepaths, b.cfg.DeployOpts.PublicKeyPaths, b.tmp)
+	if err != nil {
 		return "", "", "", err
 	}
 
+	// anyone can edit the package signatures recorded in an unsigned uds-bundle.yaml, so they're only trusted once the
+	// bundle's own signature is verified
+	if b.cfg.DeployOpts.RequireSignedPackages && !verified {
+		return "", "", "", errors.New("--require-signed-packages: the bundle's signature must be verified with --key or trusted_keys before the package signatures recorded in it can be trusted")
+	}
+
 	// read in file at config.BundleYAML
 	message.Debugf("Reading YAML at %s", filepaths[config.BundleYAML])
 	bundleYAML, err := os.ReadFile(filepaths[config.BundleYAML])
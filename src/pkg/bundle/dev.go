@@ -28,18 +28,9 @@ func (b *Bundle) CreateZarfPkgs() error {
 
 	zarfPackagePattern := `^zarf-.*\.tar\.zst$`
 	for _, pkg := range b.bundle.Packages {
-		// Can only set flavors for local packages
-		if pkg.Path == "" {
-			// check if attempting to apply flavor to remote package
-			if (len(b.cfg.DevDeployOpts.Flavor) == 1 && b.cfg.DevDeployOpts.Flavor[""] != "") ||
-				(b.cfg.DevDeployOpts.Flavor[pkg.Name] != "") {
-				return fmt.Errorf("cannot set flavor for remote packages: %s", pkg.Name)
-			}
-		}
-
 		// if pkg is a local zarf package, attempt to create it if it doesn't exist
 		if pkg.Path != "" {
-			path := getPkgPath(pkg, config.GetArch(b.bundle.Metadata.Architecture), srcDir)
+			path := getPkgPath(pkg, pkgArch(pkg, config.GetArch(b.bundle.Metadata.Architecture)), srcDir)
 			pkgDir := filepath.Dir(path)
 			// get files in directory
 			files, err := os.ReadDir(pkgDir)
@@ -6,13 +6,16 @@ package bundle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
 	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -35,8 +38,21 @@ func (b *Bundle) Inspect() error {
 	pterm.SetDefaultOutput(os.Stdout)
 	var warns []string
 
+	if b.cfg.InspectOpts.Uncompressed && !b.cfg.InspectOpts.Size {
+		return errors.New("--uncompressed requires --size")
+	}
+
 	if err := utils.CheckYAMLSourcePath(b.cfg.InspectOpts.Source); err == nil {
 		b.cfg.InspectOpts.IsYAMLFile = true
+		if b.cfg.InspectOpts.Provenance {
+			return errors.New("--provenance requires a bundle tarball or OCI ref, a uds-bundle.yaml has no provenance")
+		}
+		if b.cfg.InspectOpts.Licenses {
+			return errors.New("--licenses requires a bundle tarball or OCI ref, a uds-bundle.yaml has no SBOMs")
+		}
+		if b.cfg.InspectOpts.Size {
+			return errors.New("--size requires a bundle tarball or OCI ref, a uds-bundle.yaml has no packages to measure")
+		}
 		if err := utils.ReadYAMLStrict(b.cfg.InspectOpts.Source, &b.bundle); err != nil {
 			return err
 		}
@@ -53,6 +69,7 @@ func (b *Bundle) Inspect() error {
 		if err != nil {
 			return err
 		}
+		b.cfg.InspectOpts.Source = localBundleSource(provider, b.cfg.InspectOpts.Source)
 
 		// pull the bundle's metadata + sig + sboms (optional)
 		filepaths, err := provider.LoadBundleMetadata()
@@ -61,18 +78,38 @@ func (b *Bundle) Inspect() error {
 		}
 
 		// validate the sig (if present)
-		if err := ValidateBundleSignature(filepaths[config.BundleYAML], filepaths[config.BundleYAMLSignature], b.cfg.InspectOpts.PublicKeyPath); err != nil {
+		if _, err := validateBundleSignatures(context.TODO(), provider, source, filepaths, b.cfg.InspectOpts.PublicKeyPaths, b.tmp); err != nil {
 			return err
 		}
 
+		// handle --provenance flag
+		if b.cfg.InspectOpts.Provenance {
+			return printProvenance(filepaths)
+		}
+
 		// read the bundle's metadata into memory
 		if err := utils.ReadYAMLStrict(filepaths[config.BundleYAML], &b.bundle); err != nil {
 			return err
 		}
 
+		// handle --licenses flag
+		if b.cfg.InspectOpts.Licenses {
+			return b.printLicenseReport(provider)
+		}
+
+		// handle --size flag
+		if b.cfg.InspectOpts.Size {
+			return b.printSizeReport(provider)
+		}
+
 		// pull sbom
-		if b.cfg.InspectOpts.IncludeSBOM {
-			warns, err = provider.CreateBundleSBOM(b.cfg.InspectOpts.ExtractSBOM, b.bundle.Metadata.Name)
+		if b.cfg.InspectOpts.IncludeSBOM && b.cfg.InspectOpts.Format != "" {
+			warns, err = b.writeMergedSBOM(provider)
+			if err != nil {
+				return err
+			}
+		} else if b.cfg.InspectOpts.IncludeSBOM {
+			warns, err = provider.CreateBundleSBOM(b.cfg.InspectOpts.ExtractSBOM, b.bundle.Metadata.Name, nil)
 			if err != nil {
 				return err
 			}
@@ -110,6 +147,73 @@ func (b *Bundle) Inspect() error {
 	return nil
 }
 
+// writeMergedSBOM merges the SBOMs of the bundle's packages into a single SBOM in the current working directory
+func (b *Bundle) writeMergedSBOM(provider Provider) ([]string, error) {
+	collection := sbom.NewCollection()
+	warns, err := provider.CreateBundleSBOM(false, b.bundle.Metadata.Name, collection)
+	if err != nil {
+		return warns, err
+	}
+	if len(collection.Packages()) == 0 {
+		return append(warns, "Cannot merge SBOMs: none of the bundle's packages contain an SBOM"), nil
+	}
+
+	sbomPath := sbom.FileName(b.bundle.Metadata.Name, b.cfg.InspectOpts.Format)
+	f, err := os.Create(sbomPath)
+	if err != nil {
+		return warns, err
+	}
+	defer f.Close()
+	metadata := sbom.Metadata{
+		Name:        b.bundle.Metadata.Name,
+		Version:     b.bundle.Metadata.Version,
+		ToolVersion: config.CLIVersion,
+		Timestamp:   time.Now(),
+	}
+	if err := sbom.Write(f, b.cfg.InspectOpts.Format, collection, metadata); err != nil {
+		return warns, fmt.Errorf("unable to write merged SBOM: %w", err)
+	}
+	message.Successf("Merged SBOMs of %d package(s) into %s", len(collection.Packages()), sbomPath)
+	return warns, nil
+}
+
+// printLicenseReport prints a report of the licenses found in the SBOMs of the bundle's packages
+func (b *Bundle) printLicenseReport(provider Provider) error {
+	collection := sbom.NewCollection()
+	warns, err := provider.CreateBundleSBOM(false, b.bundle.Metadata.Name, collection)
+	if err != nil {
+		return err
+	}
+	report, err := sbom.NewLicenseReport(collection, b.cfg.InspectOpts.DenyLicenses)
+	if err != nil {
+		return err
+	}
+
+	switch b.cfg.InspectOpts.Format {
+	case "":
+		if err := zarfUtils.ColorPrintYAML(report, nil, false); err != nil {
+			return err
+		}
+	default:
+		if err := sbom.WriteLicenseReport(os.Stdout, b.cfg.InspectOpts.Format, collection, report); err != nil {
+			return err
+		}
+	}
+
+	// print warnings to stderr so they don't end up in the report
+	pterm.SetDefaultOutput(os.Stderr)
+	for _, warn := range warns {
+		message.Warn(warn)
+	}
+	if len(collection.Packages()) == 0 {
+		message.Warn("None of the bundle's packages contain an SBOM, the license report is empty")
+	}
+	if len(report.Flagged) > 0 {
+		message.Warnf("%d component(s) have unknown or denied licenses", len(report.Flagged))
+	}
+	return nil
+}
+
 func (b *Bundle) listImages() error {
 	// find images in the packages taking into account optional components
 	pkgImgMap := make(map[string][]string)
@@ -0,0 +1,365 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	goyaml "github.com/goccy/go-yaml"
+	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// maxBufferedBlobSize is the largest blob kept in memory while scanning a bundle tarball, metadata blobs (manifests,
+// zarf.yaml and checksums.txt) are almost always smaller than this and larger ones are re-extracted when needed
+const maxBufferedBlobSize = 1 << 20
+
+var (
+	errBlobMissing = errors.New("missing")
+	errBlobCorrupt = errors.New("corrupt")
+)
+
+// blobSource provides the blobs of a bundle for integrity checks
+type blobSource interface {
+	// verify reads a blob and checks it against its descriptor, returning errBlobMissing or errBlobCorrupt
+	verify(ctx context.Context, desc ocispec.Descriptor) error
+	// fetch returns the verified contents of a blob
+	fetch(ctx context.Context, desc ocispec.Descriptor) ([]byte, error)
+}
+
+// fetcherBlobSource checks the blobs of a bundle in an OCI repository or store
+type fetcherBlobSource struct {
+	fetcher content.Fetcher
+}
+
+func (f fetcherBlobSource) verify(ctx context.Context, desc ocispec.Descriptor) error {
+	rc, err := f.fetcher.Fetch(ctx, desc)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return errBlobMissing
+	}
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	vr := content.NewVerifyReader(rc, desc)
+	if _, err := io.Copy(io.Discard, vr); err != nil {
+		if errors.Is(err, content.ErrMismatchedDigest) || errors.Is(err, content.ErrTrailingData) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errBlobCorrupt
+		}
+		return err
+	}
+	if err := vr.Verify(); err != nil {
+		return errBlobCorrupt
+	}
+	return nil
+}
+
+func (f fetcherBlobSource) fetch(ctx context.Context, desc ocispec.Descriptor) ([]byte, error) {
+	return content.FetchAll(ctx, f.fetcher, desc)
+}
+
+// tarballBlobSource checks the blobs of a bundle tarball, the tarball is read once up front to hash every blob
+type tarballBlobSource struct {
+	src string
+	// digests maps the path of each blob in the tarball to the digest of its contents
+	digests map[string]string
+	// buffered holds the contents of the small blobs in the tarball
+	buffered map[string][]byte
+	// index is the tarball's index.json
+	index ocispec.Index
+}
+
+// newTarballBlobSource scans a bundle tarball, hashing all of its blobs
+func newTarballBlobSource(ctx context.Context, src string) (*tarballBlobSource, error) {
+	tb := &tarballBlobSource{
+		src:      src,
+		digests:  make(map[string]string),
+		buffered: make(map[string][]byte),
+	}
+
+	tarFile, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tarFile.Close()
+
+	foundIndex := false
+	err = config.BundleArchiveFormat.Extract(ctx, tarFile, func(_ context.Context, file archives.FileInfo) error {
+		if file.IsDir() {
+			return nil
+		}
+		isIndex := file.NameInArchive == "index.json"
+		if !isIndex && !strings.HasPrefix(file.NameInArchive, config.BlobsDir+"/") {
+			return nil
+		}
+
+		stream, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		hasher := sha256.New()
+		var buf bytes.Buffer
+		w := io.Writer(hasher)
+		if isIndex || file.Size() <= maxBufferedBlobSize {
+			w = io.MultiWriter(hasher, &buf)
+		}
+		if _, err := io.Copy(w, stream); err != nil {
+			return err
+		}
+
+		if isIndex {
+			foundIndex = true
+			return json.Unmarshal(buf.Bytes(), &tb.index)
+		}
+		tb.digests[file.NameInArchive] = fmt.Sprintf("sha256:%x", hasher.Sum(nil))
+		if buf.Len() > 0 || file.Size() == 0 {
+			tb.buffered[file.NameInArchive] = buf.Bytes()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !foundIndex {
+		return nil, fmt.Errorf("%s does not contain an index.json", src)
+	}
+	return tb, nil
+}
+
+func (tb *tarballBlobSource) verify(_ context.Context, desc ocispec.Descriptor) error {
+	actual, ok := tb.digests[filepath.Join(config.BlobsDir, desc.Digest.Encoded())]
+	if !ok {
+		return errBlobMissing
+	}
+	if actual != desc.Digest.String() {
+		return errBlobCorrupt
+	}
+	return nil
+}
+
+func (tb *tarballBlobSource) fetch(ctx context.Context, desc ocispec.Descriptor) ([]byte, error) {
+	if err := tb.verify(ctx, desc); err != nil {
+		return nil, fmt.Errorf("blob %s is %w", desc.Digest, err)
+	}
+	path := filepath.Join(config.BlobsDir, desc.Digest.Encoded())
+	if b, ok := tb.buffered[path]; ok {
+		return b, nil
+	}
+
+	// large blobs weren't kept in memory so extract them again
+	tarFile, err := os.Open(tb.src)
+	if err != nil {
+		return nil, err
+	}
+	defer tarFile.Close()
+	var b []byte
+	if err := config.BundleArchiveFormat.Extract(ctx, tarFile, utils.ExtractBytes(&b, path)); err != nil {
+		return nil, err
+	}
+	if fmt.Sprintf("sha256:%x", sha256.Sum256(b)) != desc.Digest.String() {
+		return nil, fmt.Errorf("blob %s is %w", desc.Digest, errBlobCorrupt)
+	}
+	return b, nil
+}
+
+// integrityReport holds the results of checking a bundle's integrity
+type integrityReport struct {
+	// checked is the number of blobs that were checked
+	checked int
+	// problems describes each missing or corrupt blob and each failed package checksum
+	problems []string
+	// seen tracks the blobs that have already been checked, blobs can be shared between packages and architectures
+	seen map[string]bool
+}
+
+// checkIntegrity walks the given bundle root manifests and every package manifest they reference, verifying the digest
+// of every blob and each Zarf package's checksums
+func checkIntegrity(ctx context.Context, src blobSource, roots []ocispec.Descriptor) (*integrityReport, error) {
+	report := &integrityReport{seen: make(map[string]bool)}
+	for _, root := range roots {
+		name := "bundle root manifest"
+		if root.Platform != nil {
+			name = fmt.Sprintf("%s (%s)", name, root.Platform.Architecture)
+		}
+		manifest, ok, err := report.fetchManifest(ctx, src, root, name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err := report.checkBlob(ctx, src, manifest.Config, name+" config"); err != nil {
+			return nil, err
+		}
+
+		for _, layer := range manifest.Layers {
+			title, hasTitle := layer.Annotations[ocispec.AnnotationTitle]
+			// Zarf package manifests are the bundle layers without a title
+			if layer.MediaType == zoci.ZarfLayerMediaTypeBlob && !hasTitle {
+				if report.seen[layer.Digest.String()] {
+					continue
+				}
+				if err := report.checkPackage(ctx, src, layer); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := report.checkBlob(ctx, src, layer, title); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// checkBlob verifies a single blob, recording a problem if it is missing or corrupt
+func (r *integrityReport) checkBlob(ctx context.Context, src blobSource, desc ocispec.Descriptor, name string) error {
+	if desc.Digest == "" || r.seen[desc.Digest.String()] {
+		return nil
+	}
+	r.seen[desc.Digest.String()] = true
+	r.checked++
+
+	err := src.verify(ctx, desc)
+	if errors.Is(err, errBlobMissing) || errors.Is(err, errBlobCorrupt) {
+		r.problems = append(r.problems, fmt.Sprintf("%s (%s) is %s", name, desc.Digest, err))
+		return nil
+	}
+	return err
+}
+
+// fetchManifest verifies and reads a manifest, returning false if the manifest is missing or corrupt
+func (r *integrityReport) fetchManifest(ctx context.Context, src blobSource, desc ocispec.Descriptor, name string) (*oci.Manifest, bool, error) {
+	problemsBefore := len(r.problems)
+	if err := r.checkBlob(ctx, src, desc, name); err != nil {
+		return nil, false, err
+	}
+	if len(r.problems) > problemsBefore {
+		return nil, false, nil
+	}
+	b, err := src.fetch(ctx, desc)
+	if err != nil {
+		return nil, false, err
+	}
+	var manifest oci.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s (%s) is not a valid manifest: %s", name, desc.Digest, err))
+		return nil, false, nil
+	}
+	return &manifest, true, nil
+}
+
+// checkPackage verifies a Zarf package's manifest, blobs and checksums
+func (r *integrityReport) checkPackage(ctx context.Context, src blobSource, desc ocispec.Descriptor) error {
+	manifest, ok, err := r.fetchManifest(ctx, src, desc, fmt.Sprintf("package manifest %s", desc.Digest.Encoded()[:12]))
+	if err != nil || !ok {
+		return err
+	}
+	pkgName := manifest.Annotations[ocispec.AnnotationTitle]
+	if pkgName == "" {
+		pkgName = desc.Digest.Encoded()[:12]
+	}
+
+	if err := r.checkBlob(ctx, src, manifest.Config, fmt.Sprintf("package %s config", pkgName)); err != nil {
+		return err
+	}
+	problemsBefore := len(r.problems)
+	for _, layer := range manifest.Layers {
+		if err := r.checkBlob(ctx, src, layer, fmt.Sprintf("package %s layer %s", pkgName, layer.Annotations[ocispec.AnnotationTitle])); err != nil {
+			return err
+		}
+	}
+
+	zarfYAMLDesc := manifest.Locate(config.ZarfYAML)
+	checksumsDesc := manifest.Locate(config.ChecksumsTxt)
+	if oci.IsEmptyDescriptor(zarfYAMLDesc) || oci.IsEmptyDescriptor(checksumsDesc) {
+		r.problems = append(r.problems, fmt.Sprintf("package %s is missing its %s or %s", pkgName, config.ZarfYAML, config.ChecksumsTxt))
+		return nil
+	}
+	// checksums can't be validated if the package's metadata is damaged, that has already been reported
+	if len(r.problems) > problemsBefore {
+		return nil
+	}
+
+	zarfYAML, err := src.fetch(ctx, zarfYAMLDesc)
+	if err != nil {
+		return err
+	}
+	var pkg v1alpha1.ZarfPackage
+	if err := goyaml.Unmarshal(zarfYAML, &pkg); err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("package %s has an invalid %s: %s", pkgName, config.ZarfYAML, err))
+		return nil
+	}
+	checksums, err := src.fetch(ctx, checksumsDesc)
+	if err != nil {
+		return err
+	}
+	r.problems = append(r.problems, validatePkgChecksums(pkgName, pkg, checksums, manifest.Layers)...)
+	return nil
+}
+
+// validatePkgChecksums checks a Zarf package's checksums.txt against its aggregate checksum and the layers in the
+// bundle, layers of optional components that weren't included in the bundle are expected to be missing
+func validatePkgChecksums(pkgName string, pkg v1alpha1.ZarfPackage, checksums []byte, layers []ocispec.Descriptor) []string {
+	var problems []string
+	if actual := fmt.Sprintf("%x", sha256.Sum256(checksums)); actual != pkg.Metadata.AggregateChecksum {
+		problems = append(problems, fmt.Sprintf("package %s %s does not match the aggregate checksum %s", pkgName, config.ChecksumsTxt, pkg.Metadata.AggregateChecksum))
+	}
+
+	layersByTitle := make(map[string]ocispec.Descriptor, len(layers))
+	for _, layer := range layers {
+		layersByTitle[layer.Annotations[ocispec.AnnotationTitle]] = layer
+	}
+	requiredComponents := make(map[string]bool)
+	for _, component := range pkg.Components {
+		if component.IsRequired() {
+			requiredComponents[filepath.Join("components", component.Name+".tar")] = true
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		sha, rel, ok := strings.Cut(line, " ")
+		if !ok || sha == "" || rel == "" {
+			problems = append(problems, fmt.Sprintf("package %s has an invalid checksum line: %s", pkgName, line))
+			continue
+		}
+		layer, ok := layersByTitle[rel]
+		if !ok {
+			if requiredComponents[rel] {
+				problems = append(problems, fmt.Sprintf("package %s is missing required component layer %s", pkgName, rel))
+			}
+			continue
+		}
+		if layer.Digest.Encoded() != sha {
+			problems = append(problems, fmt.Sprintf("package %s layer %s does not match its checksum in %s", pkgName, rel, config.ChecksumsTxt))
+		}
+	}
+	return problems
+}
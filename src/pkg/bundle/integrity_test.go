@@ -0,0 +1,218 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	goyaml "github.com/goccy/go-yaml"
+	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+)
+
+// testBundleBlobs builds the blobs of a bundle with a single package holding one required component
+func testBundleBlobs(t *testing.T) (map[string][]byte, ocispec.Descriptor, ocispec.Descriptor) {
+	t.Helper()
+	blobs := make(map[string][]byte)
+	addBlob := func(mediaType string, b []byte, title string) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, b)
+		if title != "" {
+			desc.Annotations = map[string]string{ocispec.AnnotationTitle: title}
+		}
+		blobs[desc.Digest.Encoded()] = b
+		return desc
+	}
+	addManifest := func(manifest ocispec.Manifest, mediaType string) ocispec.Descriptor {
+		manifest.SchemaVersion = 2
+		manifest.MediaType = ocispec.MediaTypeImageManifest
+		b, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		return addBlob(mediaType, b, "")
+	}
+
+	component := []byte("component")
+	checksums := []byte(fmt.Sprintf("%x components/a.tar\n", sha256.Sum256(component)))
+	required := true
+	zarfYAML, err := goyaml.Marshal(v1alpha1.ZarfPackage{
+		Kind:       v1alpha1.ZarfPackageConfig,
+		Metadata:   v1alpha1.ZarfMetadata{Name: "test", AggregateChecksum: fmt.Sprintf("%x", sha256.Sum256(checksums))},
+		Components: []v1alpha1.ZarfComponent{{Name: "a", Required: &required}},
+	})
+	require.NoError(t, err)
+
+	componentDesc := addBlob(zoci.ZarfLayerMediaTypeBlob, component, "components/a.tar")
+	pkgConfig := addBlob(ocispec.MediaTypeImageConfig, []byte("{}"), "")
+	pkgManifest := addManifest(ocispec.Manifest{
+		Config: pkgConfig,
+		Layers: []ocispec.Descriptor{
+			addBlob(zoci.ZarfLayerMediaTypeBlob, zarfYAML, config.ZarfYAML),
+			addBlob(zoci.ZarfLayerMediaTypeBlob, checksums, config.ChecksumsTxt),
+			componentDesc,
+		},
+		Annotations: map[string]string{ocispec.AnnotationTitle: "test"},
+	}, zoci.ZarfLayerMediaTypeBlob)
+
+	root := addManifest(ocispec.Manifest{
+		Config: addBlob(ocispec.MediaTypeImageConfig, []byte(`{"bundle":true}`), ""),
+		Layers: []ocispec.Descriptor{
+			pkgManifest,
+			addBlob(zoci.ZarfLayerMediaTypeBlob, []byte("kind: UDSBundle"), config.BundleYAML),
+		},
+	}, ocispec.MediaTypeImageManifest)
+	return blobs, root, componentDesc
+}
+
+// writeTestBundleTarball writes the blobs and an index.json pointing at the root manifest to a bundle tarball
+func writeTestBundleTarball(t *testing.T, blobs map[string][]byte, root ocispec.Descriptor) string {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	pathMap := make(map[string]string)
+	for encoded, b := range blobs {
+		path := filepath.Join(dir, encoded)
+		require.NoError(t, os.WriteFile(path, b, 0600))
+		pathMap[path] = filepath.Join(config.BlobsDir, encoded)
+	}
+	index, err := json.Marshal(ocispec.Index{Manifests: []ocispec.Descriptor{root}})
+	require.NoError(t, err)
+	indexPath := filepath.Join(dir, "index.json")
+	require.NoError(t, os.WriteFile(indexPath, index, 0600))
+	pathMap[indexPath] = "index.json"
+
+	files, err := archives.FilesFromDisk(ctx, nil, pathMap)
+	require.NoError(t, err)
+	tarballPath := filepath.Join(t.TempDir(), "uds-bundle-test-amd64-0.0.1.tar.zst")
+	out, err := os.Create(tarballPath)
+	require.NoError(t, err)
+	defer out.Close()
+	require.NoError(t, config.BundleArchiveFormat.Archive(ctx, out, files))
+	return tarballPath
+}
+
+func Test_checkIntegrity(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(blobs map[string][]byte, component ocispec.Descriptor)
+		problems int
+		contains string
+	}{
+		{
+			name:   "intact bundle",
+			tamper: func(_ map[string][]byte, _ ocispec.Descriptor) {},
+		},
+		{
+			name: "corrupt layer",
+			tamper: func(blobs map[string][]byte, component ocispec.Descriptor) {
+				blobs[component.Digest.Encoded()] = []byte("tampered")
+			},
+			problems: 1,
+			contains: "layer components/a.tar (sha256:",
+		},
+		{
+			name: "missing layer",
+			tamper: func(blobs map[string][]byte, component ocispec.Descriptor) {
+				delete(blobs, component.Digest.Encoded())
+			},
+			problems: 1,
+			contains: "is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			blobs, root, component := testBundleBlobs(t)
+			tt.tamper(blobs, component)
+			tarballPath := writeTestBundleTarball(t, blobs, root)
+
+			src, err := newTarballBlobSource(ctx, tarballPath)
+			require.NoError(t, err)
+			require.Len(t, src.index.Manifests, 1)
+
+			report, err := checkIntegrity(ctx, src, src.index.Manifests)
+			require.NoError(t, err)
+			require.Len(t, report.problems, tt.problems)
+			// root manifest, its config and 2 layers (one being the package manifest) plus the package config and 3 layers
+			require.Equal(t, 8, report.checked)
+			if tt.contains != "" {
+				require.Contains(t, report.problems[0], tt.contains)
+			}
+		})
+	}
+}
+
+func Test_validatePkgChecksums(t *testing.T) {
+	componentA := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("a"))
+	componentA.Annotations = map[string]string{ocispec.AnnotationTitle: "components/a.tar"}
+	required := true
+	pkg := func(checksums string) v1alpha1.ZarfPackage {
+		return v1alpha1.ZarfPackage{
+			Metadata: v1alpha1.ZarfMetadata{AggregateChecksum: fmt.Sprintf("%x", sha256.Sum256([]byte(checksums)))},
+			Components: []v1alpha1.ZarfComponent{
+				{Name: "a", Required: &required},
+				{Name: "b"},
+			},
+		}
+	}
+	validChecksums := fmt.Sprintf("%s components/a.tar\n%x components/b.tar\n", componentA.Digest.Encoded(), sha256.Sum256([]byte("b")))
+
+	tests := []struct {
+		name      string
+		pkg       v1alpha1.ZarfPackage
+		checksums string
+		layers    []ocispec.Descriptor
+		problems  int
+	}{
+		{
+			name:      "optional component not in bundle",
+			pkg:       pkg(validChecksums),
+			checksums: validChecksums,
+			layers:    []ocispec.Descriptor{componentA},
+		},
+		{
+			name:      "required component not in bundle",
+			pkg:       pkg(validChecksums),
+			checksums: validChecksums,
+			problems:  1,
+		},
+		{
+			name:      "aggregate checksum mismatch",
+			pkg:       pkg("something else"),
+			checksums: validChecksums,
+			layers:    []ocispec.Descriptor{componentA},
+			problems:  1,
+		},
+		{
+			name:      "layer checksum mismatch",
+			pkg:       pkg("0000 components/a.tar\n"),
+			checksums: "0000 components/a.tar\n",
+			layers:    []ocispec.Descriptor{componentA},
+			problems:  1,
+		},
+		{
+			name:      "invalid checksum line",
+			pkg:       pkg("components/a.tar\n"),
+			checksums: "components/a.tar\n",
+			layers:    []ocispec.Descriptor{componentA},
+			problems:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validatePkgChecksums("test", tt.pkg, []byte(tt.checksums), tt.layers)
+			require.Len(t, problems, tt.problems, problems)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+// ociDirBundleProvider reads a bundle from an unpacked OCI image layout dir, such as one written by uds pull --format oci-dir
+type ociDirBundleProvider struct {
+	ctx context.Context
+	src string
+	dst string
+	// arch selects the bundle root manifest from multi-arch bundles
+	arch string
+
+	// these fields are populated by loadBundleManifest as part of the provider constructor
+	bundleRootDesc ocispec.Descriptor
+	rootManifest   *oci.Manifest
+	index          ocispec.Index
+}
+
+// blobPath returns the path of a blob in the OCI layout dir
+func (dp *ociDirBundleProvider) blobPath(desc ocispec.Descriptor) string {
+	return filepath.Join(dp.src, config.BlobsDir, desc.Digest.Encoded())
+}
+
+// readBlob reads a blob from the OCI layout dir, verifying it against its descriptor
+func (dp *ociDirBundleProvider) readBlob(desc ocispec.Descriptor) ([]byte, error) {
+	b, err := os.ReadFile(dp.blobPath(desc))
+	if err != nil {
+		return nil, err
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	if desc.Digest.Algorithm().FromBytes(b) != desc.Digest {
+		return nil, fmt.Errorf("%s in %s failed verification", desc.Digest, dp.src)
+	}
+	return b, nil
+}
+
+// CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
+func (dp *ociDirBundleProvider) CreateBundleSBOM(extractSBOM bool, bundleName string, collection *sbom.Collection) ([]string, error) {
+	var warns []string
+	rootManifest, err := dp.getBundleManifest()
+	if err != nil {
+		return warns, err
+	}
+	// make tmp dir for pkg SBOM extraction
+	err = os.Mkdir(filepath.Join(dp.dst, config.BundleSBOM), 0o700)
+	if err != nil {
+		return warns, err
+	}
+
+	// track SBOM artifact paths, used for extraction and creation of bundleSBOM artifact
+	SBOMArtifactPathMap := make(types.PathMap)
+
+	for _, layer := range rootManifest.Layers {
+		// get Zarf image manifests from bundle manifest, only they are missing a title annotation
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
+			continue
+		}
+
+		zarfImageManifestBytes, err := dp.readBlob(layer)
+		if err != nil {
+			return warns, err
+		}
+		var zarfImageManifest *oci.Manifest
+		if err := json.Unmarshal(zarfImageManifestBytes, &zarfImageManifest); err != nil {
+			return warns, err
+		}
+
+		// find sbom layer descriptor, if it doesn't exist continue
+		sbomDesc := zarfImageManifest.Locate(config.SBOMsTar)
+		if oci.IsEmptyDescriptor(sbomDesc) {
+			continue
+		}
+
+		// the SBOM tar is read in place, the dir is never written to
+		sbomTarFile, err := os.Open(dp.blobPath(sbomDesc))
+		if err != nil {
+			return warns, err
+		}
+
+		if collection != nil {
+			err = collection.AddTar(context.TODO(), zarfImageManifest.Annotations[ocispec.AnnotationTitle], sbomTarFile)
+		} else {
+			extractor := utils.SBOMExtractor(dp.dst, SBOMArtifactPathMap)
+			err = archives.Tar{}.Extract(context.TODO(), sbomTarFile, extractor)
+		}
+		sbomTarFile.Close()
+		if err != nil {
+			return warns, err
+		}
+	}
+
+	if collection != nil {
+		return warns, nil
+	}
+	return utils.HandleSBOM(extractSBOM, SBOMArtifactPathMap, bundleName, dp.dst)
+}
+
+func (dp *ociDirBundleProvider) getBundleManifest() (*oci.Manifest, error) {
+	if dp.rootManifest != nil {
+		return dp.rootManifest, nil
+	}
+	return nil, errors.New("bundle root manifest not loaded")
+}
+
+func (dp *ociDirBundleProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	if dp.rootManifest != nil {
+		return dp.bundleRootDesc, nil
+	}
+	return ocispec.Descriptor{}, errors.New("bundle root manifest not loaded")
+}
+
+// loadBundleManifest loads the bundle's root manifest and desc into the ociDirBundleProvider so we don't have to load it multiple times
+func (dp *ociDirBundleProvider) loadBundleManifest() error {
+	indexBytes, err := os.ReadFile(filepath.Join(dp.src, ocispec.ImageIndexFile))
+	if err != nil {
+		return err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		return err
+	}
+
+	// multi-arch bundles have a bundle root manifest per arch in their index.json
+	bundleManifestDesc, err := boci.SelectRootManifest(index, dp.arch)
+	if err != nil {
+		return err
+	}
+	dp.bundleRootDesc = bundleManifestDesc
+	dp.index = index
+
+	manifestBytes, err := dp.readBlob(bundleManifestDesc)
+	if err != nil {
+		return err
+	}
+	var manifest *oci.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return err
+	}
+	dp.rootManifest = manifest
+	return nil
+}
+
+// LoadBundle loads a bundle from an OCI layout dir
+func (dp *ociDirBundleProvider) LoadBundle(_ types.BundlePullOptions, _ int) (*types.UDSBundle, types.PathMap, error) {
+	return nil, nil, errors.New("uds pull does not support pulling local bundles")
+}
+
+// LoadBundleMetadata copies a bundle's metadata from an OCI layout dir
+func (dp *ociDirBundleProvider) LoadBundleMetadata() (types.PathMap, error) {
+	bundleRootManifest, err := dp.getBundleManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	filepaths := make(types.PathMap)
+	for _, path := range bundleMetadataPaths(bundleRootManifest) {
+		layer := bundleRootManifest.Locate(path)
+		if oci.IsEmptyDescriptor(layer) {
+			continue
+		}
+		abs := filepath.Join(dp.dst, config.BlobsDir, layer.Digest.Encoded())
+		filepaths[path] = abs
+		if !helpers.InvalidPath(abs) && helpers.SHAsMatch(abs, layer.Digest.Encoded()) == nil {
+			continue
+		}
+
+		b, err := dp.readBlob(layer)
+		if err != nil {
+			return nil, err
+		}
+		if err := helpers.CreateDirectory(filepath.Dir(abs), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(abs, b, helpers.ReadWriteUser); err != nil {
+			return nil, err
+		}
+	}
+	return filepaths, nil
+}
+
+// PublishBundle publishes a bundle in an OCI layout dir to a remote OCI registry
+func (dp *ociDirBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, opts types.BundlePublishOptions) error {
+	// the dir already is an OCI layout so it is published as is
+	store, err := ocistore.NewWithContext(dp.ctx, dp.src)
+	if err != nil {
+		return err
+	}
+	return publishFromStore(dp.ctx, store, dp.index, dp.bundleRootDesc, bundle, remote, opts)
+}
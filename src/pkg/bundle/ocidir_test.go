@@ -0,0 +1,112 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/layout"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	zarfTypes "github.com/zarf-dev/zarf/src/types"
+)
+
+func TestOCIDirBundleProvider(t *testing.T) {
+	t.Run("load metadata", func(t *testing.T) {
+		dir, b := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+		dst := t.TempDir()
+		provider, err := NewBundleProvider(dir, dst)
+		require.NoError(t, err)
+		require.IsType(t, &ociDirBundleProvider{}, provider)
+
+		desc, err := provider.getBundleRootDesc()
+		require.NoError(t, err)
+		require.Equal(t, b.Root.Digest, desc.Digest)
+
+		// metadata is copied out of the dir, leaving it untouched
+		filepaths, err := provider.LoadBundleMetadata()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dst, config.BlobsDir, b.BundleYAML.Digest.Encoded()), filepaths[config.BundleYAML])
+		bundleYAML, err := os.ReadFile(filepaths[config.BundleYAML])
+		require.NoError(t, err)
+		require.Contains(t, string(bundleYAML), "kind: UDSBundle")
+	})
+
+	t.Run("corrupt root manifest", func(t *testing.T) {
+		dir, b := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.BlobsDir, b.Root.Digest.Encoded()), []byte("{}"), 0o600))
+		_, err := NewBundleProvider(dir, t.TempDir())
+		require.ErrorContains(t, err, "failed verification")
+	})
+
+	t.Run("corrupt package manifest", func(t *testing.T) {
+		dir, b := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+		pkgManifestSHA := b.PkgManifest.Digest.Encoded()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.BlobsDir, pkgManifestSHA), []byte("{}"), 0o600))
+
+		bundleCfg := types.BundleConfig{DeployOpts: types.BundleDeployOptions{Source: dir}}
+		source, err := sources.NewFromLocation(bundleCfg, types.Package{Name: "nginx"}, zarfTypes.ZarfPackageOptions{PackageSource: t.TempDir()}, pkgManifestSHA, nil)
+		require.NoError(t, err)
+		_, _, err = source.LoadPackageMetadata(context.Background(), layout.New(t.TempDir()), false, false)
+		require.ErrorContains(t, err, "manifest of package nginx in "+dir+" failed verification")
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		dir, b := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+		provider, err := NewBundleProvider(dir, t.TempDir())
+		require.NoError(t, err)
+
+		remote, err := zoci.NewRemote(context.Background(), repo.Reference.String()+":0.0.1", ocispec.Platform{Architecture: "amd64", OS: oci.MultiOS}, oci.WithPlainHTTP(true))
+		require.NoError(t, err)
+		bundle := types.UDSBundle{Metadata: types.UDSMetadata{Name: "example", Version: "0.0.1", Architecture: "amd64"}}
+		require.NoError(t, provider.PublishBundle(bundle, remote.OrasRemote, types.BundlePublishOptions{}))
+
+		// the dir's root manifest and the package's layers are published as is
+		indexDesc, err := repo.Resolve(context.Background(), "0.0.1")
+		require.NoError(t, err)
+		index, err := fetchIndex(context.Background(), repo, indexDesc)
+		require.NoError(t, err)
+		require.Len(t, index.Manifests, 1)
+		require.Equal(t, b.Root.Digest, index.Manifests[0].Digest)
+		rootManifest, err := fetchRootManifest(context.Background(), repo, index.Manifests[0])
+		require.NoError(t, err)
+		pkgManifest, err := fetchRootManifest(context.Background(), repo, rootManifest.Layers[0])
+		require.NoError(t, err)
+		exists, err := repo.Exists(context.Background(), pkgManifest.Layers[0])
+		require.NoError(t, err)
+		require.True(t, exists)
+	})
+}
+
+func TestWriteOCIDir(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "uds-bundle-example-amd64-0.0.1")
+	require.NoError(t, os.WriteFile(filepath.Join(src, "index.json"), []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "blob"), []byte("blob"), 0o600))
+
+	// anything left from an earlier pull is replaced
+	require.NoError(t, os.MkdirAll(filepath.Join(dst, config.BlobsDir), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dst, config.BlobsDir, "stale"), []byte("stale"), 0o600))
+
+	require.NoError(t, writeOCIDir(dst, types.PathMap{
+		filepath.Join(src, "index.json"): "index.json",
+		filepath.Join(src, "blob"):       filepath.Join(config.BlobsDir, "abc"),
+	}))
+	b, err := os.ReadFile(filepath.Join(dst, config.BlobsDir, "abc"))
+	require.NoError(t, err)
+	require.Equal(t, "blob", string(b))
+	require.FileExists(t, filepath.Join(dst, "index.json"))
+	require.NoFileExists(t, filepath.Join(dst, config.BlobsDir, "stale"))
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
+)
+
+// newProvenance starts the bundle's provenance statement with the source files and parameters given to uds create, the
+// bundler adds the bundle's packages and subject once they have been resolved
+func (b *Bundle) newProvenance() (*provenance.Statement, error) {
+	sourceFiles := []string{b.cfg.CreateOpts.BundleFile}
+	for _, pkg := range b.bundle.Packages {
+		for _, overrides := range pkg.Overrides {
+			for _, chartOverrides := range overrides {
+				for _, valuesFile := range chartOverrides.ValuesFiles {
+					if !slices.Contains(sourceFiles, valuesFile) {
+						sourceFiles = append(sourceFiles, valuesFile)
+					}
+				}
+			}
+		}
+	}
+	// values files are collected from maps so sort them to keep the statement deterministic
+	slices.Sort(sourceFiles[1:])
+
+	dependencies := make([]provenance.ResourceDescriptor, 0, len(sourceFiles))
+	for _, sourceFile := range sourceFiles {
+		path := sourceFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(b.cfg.CreateOpts.SourceDirectory, path)
+		}
+		fileBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s for the bundle's provenance: %w", sourceFile, err)
+		}
+		dependencies = append(dependencies, provenance.ResourceDescriptor{
+			Name:   sourceFile,
+			Digest: map[string]string{"sha256": fmt.Sprintf("%x", sha256.Sum256(fileBytes))},
+		})
+	}
+
+	return &provenance.Statement{
+		Type:          provenance.StatementType,
+		PredicateType: provenance.PredicateType,
+		Predicate: provenance.Predicate{
+			BuildDefinition: provenance.BuildDefinition{
+				BuildType: provenance.BuildType,
+				ExternalParameters: provenance.ExternalParameters{
+					BundleFile:   b.cfg.CreateOpts.BundleFile,
+					Name:         b.bundle.Metadata.Name,
+					Version:      b.bundle.Metadata.Version,
+					Architecture: b.bundle.Metadata.Architecture,
+				},
+				InternalParameters: provenance.InternalParameters{
+					Terminal: b.bundle.Build.Terminal,
+					User:     b.bundle.Build.User,
+					OS:       runtime.GOOS,
+					Arch:     runtime.GOARCH,
+				},
+				ResolvedDependencies: dependencies,
+			},
+			RunDetails: provenance.RunDetails{
+				Builder: provenance.Builder{
+					ID:      provenance.BuilderID,
+					Version: map[string]string{"uds-cli": config.CLIVersion},
+				},
+				Metadata: provenance.Metadata{
+					StartedOn: time.Now().UTC().Format(time.RFC3339),
+				},
+			},
+		},
+	}, nil
+}
+
+// printProvenance prints the provenance statement embedded in a bundle
+func printProvenance(filepaths types.PathMap) error {
+	provenancePath, ok := filepaths[config.BundleProvenance]
+	if !ok {
+		return fmt.Errorf("bundle does not contain a %s, it was created before provenance was recorded", config.BundleProvenance)
+	}
+	provenanceBytes, err := os.ReadFile(provenancePath)
+	if err != nil {
+		return err
+	}
+	// print the statement as it was recorded rather than round-tripping it through our types
+	var out bytes.Buffer
+	if err := json.Indent(&out, provenanceBytes, "", "  "); err != nil {
+		return fmt.Errorf("invalid %s: %w", config.BundleProvenance, err)
+	}
+	fmt.Println(out.String())
+	return nil
+}
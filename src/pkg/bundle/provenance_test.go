@@ -0,0 +1,67 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newProvenance(t *testing.T) {
+	sourceDir := t.TempDir()
+	files := map[string][]byte{
+		config.BundleYAML: []byte("kind: UDSBundle"),
+		"values-b.yaml":   []byte("b: true"),
+		"values-a.yaml":   []byte("a: true"),
+	}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), contents, 0600))
+	}
+
+	b := Bundle{
+		cfg: &types.BundleConfig{
+			CreateOpts: types.BundleCreateOptions{SourceDirectory: sourceDir, BundleFile: config.BundleYAML},
+		},
+		bundle: types.UDSBundle{
+			Metadata: types.UDSMetadata{Name: "test", Version: "0.0.1", Architecture: "arm64"},
+			Build:    types.UDSBuildData{User: "uds", Terminal: "host"},
+			Packages: []types.Package{
+				{Name: "foo", Overrides: map[string]map[string]types.BundleChartOverrides{"component": {
+					"chart-1": {ValuesFiles: []string{"values-b.yaml"}},
+					"chart-2": {ValuesFiles: []string{"values-a.yaml", "values-b.yaml"}},
+				}}},
+			},
+		},
+	}
+
+	statement, err := b.newProvenance()
+	require.NoError(t, err)
+	require.Equal(t, provenance.StatementType, statement.Type)
+	require.Equal(t, provenance.PredicateType, statement.PredicateType)
+	require.Equal(t, provenance.ExternalParameters{BundleFile: config.BundleYAML, Name: "test", Version: "0.0.1", Architecture: "arm64"},
+		statement.Predicate.BuildDefinition.ExternalParameters)
+	require.Equal(t, "uds", statement.Predicate.BuildDefinition.InternalParameters.User)
+
+	// the bundle file comes first followed by the deduplicated values files
+	dependencies := statement.Predicate.BuildDefinition.ResolvedDependencies
+	require.Len(t, dependencies, 3)
+	for i, name := range []string{config.BundleYAML, "values-a.yaml", "values-b.yaml"} {
+		require.Equal(t, name, dependencies[i].Name)
+		require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(files[name])), dependencies[i].Digest["sha256"])
+	}
+
+	// missing source files can't be recorded
+	b.bundle.Packages[0].Overrides["component"]["chart-1"] = types.BundleChartOverrides{ValuesFiles: []string{"missing.yaml"}}
+	_, err = b.newProvenance()
+	require.ErrorContains(t, err, "missing.yaml")
+}
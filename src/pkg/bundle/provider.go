@@ -11,6 +11,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -28,6 +29,9 @@ type Provider interface {
 	// : if tarball
 	// : : extracts the metadata from the tarball
 	//
+	// : if OCI layout dir
+	// : : copies the metadata from the dir's blobs
+	//
 	// : if OCI ref
 	// : : pulls the metadata from the OCI ref
 	LoadBundleMetadata() (types.PathMap, error)
@@ -37,23 +41,32 @@ type Provider interface {
 	// (currently only the remote provider utilizes the concurrency parameter)
 	LoadBundle(options types.BundlePullOptions, concurrency int) (*types.UDSBundle, types.PathMap, error)
 
-	// CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
-	CreateBundleSBOM(extractSBOM bool, bundleName string) ([]string, error)
+	// CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM,
+	// if a collection is given the packages' SBOMs are added to it to be merged instead
+	CreateBundleSBOM(extractSBOM bool, bundleName string, collection *sbom.Collection) ([]string, error)
 
 	// PublishBundle publishes a bundle to a remote OCI repo
-	PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote) error
+	PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, opts types.BundlePublishOptions) error
 
 	// getBundleManifest gets the bundle's root manifest
 	getBundleManifest() (*oci.Manifest, error)
+
+	// getBundleRootDesc gets the descriptor of the bundle's root manifest
+	getBundleRootDesc() (ocispec.Descriptor, error)
 }
 
 // NewBundleProvider returns a new bundler Provider based on the source type
 func NewBundleProvider(source, destination string) (Provider, error) {
+	return newArchBundleProvider(source, destination, config.GetArch())
+}
+
+// newArchBundleProvider returns a new bundler Provider that reads the bundle root manifest for arch
+func newArchBundleProvider(source, destination, arch string) (Provider, error) {
 	ctx := context.TODO()
 	if helpers.IsOCIURL(source) {
 		op := ociProvider{src: source, dst: destination}
 		platform := ocispec.Platform{
-			Architecture: config.GetArch(),
+			Architecture: arch,
 			OS:           oci.MultiOS,
 		}
 		// get remote client
@@ -72,13 +85,37 @@ func NewBundleProvider(source, destination string) (Provider, error) {
 
 		return &op, nil
 	}
+	if utils.IsValidBundleDir(source) {
+		dp := ociDirBundleProvider{ctx: ctx, src: source, dst: destination, arch: arch}
+		if err := dp.loadBundleManifest(); err != nil {
+			return nil, err
+		}
+		return &dp, nil
+	}
 	if !utils.IsValidTarballPath(source) {
 		return nil, fmt.Errorf("invalid tarball path: %s", source)
 	}
-	tp := tarballBundleProvider{ctx: ctx, src: source, dst: destination}
+	// split bundle tarballs are reassembled into the destination and read from there
+	if utils.IsSplitFile(source) {
+		reassembled, err := utils.ReassembleSplitFile(source, destination)
+		if err != nil {
+			return nil, err
+		}
+		source = reassembled
+	}
+	tp := tarballBundleProvider{ctx: ctx, src: source, dst: destination, arch: arch}
 	err := tp.loadBundleManifest()
 	if err != nil {
 		return nil, err
 	}
 	return &tp, nil
 }
+
+// localBundleSource returns the path a provider reads a local bundle from, which is the reassembled tarball for split
+// bundle tarballs
+func localBundleSource(provider Provider, source string) string {
+	if tp, ok := provider.(*tarballBundleProvider); ok {
+		return tp.src
+	}
+	return source
+}
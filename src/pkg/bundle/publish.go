@@ -6,21 +6,33 @@ package bundle
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/message"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
 )
 
-// Publish publishes a bundle to a remote OCI registry
+// Publish publishes a bundle tarball to a remote OCI registry, or copies a bundle from one OCI registry to another
 func (b *Bundle) Publish() error {
 	b.cfg.PublishOpts.Destination = boci.EnsureOCIPrefix(b.cfg.PublishOpts.Destination)
+	isRemoteSource := helpers.IsOCIURL(b.cfg.PublishOpts.Source)
 
 	// load bundle metadata into memory
 	// todo: having the tmp dir be the provider.dst is weird
@@ -28,6 +40,8 @@ func (b *Bundle) Publish() error {
 	if err != nil {
 		return err
 	}
+	source := b.cfg.PublishOpts.Source
+	b.cfg.PublishOpts.Source = localBundleSource(provider, b.cfg.PublishOpts.Source)
 	filepaths, err := provider.LoadBundleMetadata()
 	if err != nil {
 		return err
@@ -40,25 +54,33 @@ func (b *Bundle) Publish() error {
 		return err
 	}
 
-	// Open the bundle file for streaming instead of loading it all into memory
-	bundleFile, err := os.Open(b.cfg.PublishOpts.Source)
-	if err != nil {
-		return err
-	}
-	defer bundleFile.Close()
+	// remote bundles are streamed between registries and OCI layout dirs are published in place, only bundle tarballs need to be extracted
+	if !isRemoteSource && !utils.IsValidBundleDir(b.cfg.PublishOpts.Source) {
+		// Open the bundle file for streaming instead of loading it all into memory
+		bundleFile, err := os.Open(b.cfg.PublishOpts.Source)
+		if err != nil {
+			return err
+		}
+		defer bundleFile.Close()
 
-	// Extract all files from the archive into a tmpdir using streaming
-	err = config.BundleArchiveFormat.Extract(context.TODO(), bundleFile, utils.ExtractAllFiles(b.tmp))
-	if err != nil {
-		return err
+		// Extract all files from the archive into a tmpdir using streaming
+		err = config.BundleArchiveFormat.Extract(context.TODO(), bundleFile, utils.ExtractAllFiles(b.tmp))
+		if err != nil {
+			return err
+		}
 	}
 
 	// create new OCI artifact in remote
 	ociURL := b.cfg.PublishOpts.Destination
 	bundleName := b.bundle.Metadata.Name
 
-	// tag bundle with metadata.version, unless user specifies a version
+	// tag bundle with metadata.version, or the source's tag for remote bundles, unless user specifies a version
 	bundleTag := b.bundle.Metadata.Version
+	if isRemoteSource {
+		if srcTag := sourceTag(b.cfg.PublishOpts.Source); srcTag != "" {
+			bundleTag = srcTag
+		}
+	}
 	if b.cfg.PublishOpts.Version != "" {
 		bundleTag = b.cfg.PublishOpts.Version
 	}
@@ -71,9 +93,222 @@ func (b *Bundle) Publish() error {
 	if err != nil {
 		return err
 	}
-	err = provider.PublishBundle(b.bundle, remote.OrasRemote)
+	err = provider.PublishBundle(b.bundle, remote.OrasRemote, b.cfg.PublishOpts)
 	if err != nil {
 		return err
 	}
+
+	// signatures of remote bundles are copied with them, local bundles get their sidecar signatures attached
+	if !isRemoteSource {
+		if err := attachSidecarSignatures(context.TODO(), provider, source, remote.Repo()); err != nil {
+			return err
+		}
+	}
+
+	// remote bundles are copied with their whole index so the additional tags point at it, local bundles were merged
+	// into the index of each of the additional tags as they were published
+	if len(b.cfg.PublishOpts.Tags) > 0 {
+		if isRemoteSource {
+			if err := boci.TagAll(context.TODO(), remote.Repo(), b.cfg.PublishOpts.Tags); err != nil {
+				return err
+			}
+		}
+		message.Successf("Tagged %s as %s", remote.Repo().Reference, strings.Join(b.cfg.PublishOpts.Tags, ", "))
+	}
+	return nil
+}
+
+// attachSidecarSignatures attaches the detached signatures next to a local bundle to its published root manifest
+func attachSidecarSignatures(ctx context.Context, provider Provider, source string, target oras.Target) error {
+	sigPaths, err := sidecarSignaturePaths(source)
+	if err != nil || len(sigPaths) == 0 {
+		return err
+	}
+	rootDesc, err := provider.getBundleRootDesc()
+	if err != nil {
+		return err
+	}
+	for _, sigPath := range sigPaths {
+		signature, err := os.ReadFile(sigPath)
+		if err != nil {
+			return err
+		}
+		if _, err := attachSignature(ctx, target, rootDesc, signature); err != nil {
+			return fmt.Errorf("unable to attach signature %s: %w", filepath.Base(sigPath), err)
+		}
+	}
+	message.Successf("Attached %d signature(s) from %s", len(sigPaths), filepath.Base(source))
+	return nil
+}
+
+// sourceTag returns the tag of a remote bundle's reference, or an empty string if it is referenced by digest
+func sourceTag(source string) string {
+	ref, err := registry.ParseReference(strings.TrimPrefix(source, helpers.OCIURLPrefix))
+	if err != nil || ref.ValidateReferenceAsDigest() == nil {
+		return ""
+	}
+	return ref.Reference
+}
+
+// getZarfLayers returns the layers of the Zarf package that are in the bundle
+func getZarfLayers(ctx context.Context, store *ocistore.Store, pkgManifestDesc ocispec.Descriptor) ([]ocispec.Descriptor, int64, error) {
+	var layersToPull []ocispec.Descriptor
+	estimatedPkgSize := int64(0)
+
+	zarfImageManifestBytes, err := content.FetchAll(ctx, store, pkgManifestDesc)
+	if err != nil {
+		return nil, int64(0), err
+	}
+	var zarfImageManifest *oci.Manifest
+	if err := json.Unmarshal(zarfImageManifestBytes, &zarfImageManifest); err != nil {
+		return nil, int64(0), err
+	}
+
+	// only grab image layers that we want
+	for _, layer := range zarfImageManifest.Manifest.Layers {
+		ok, err := store.Exists(ctx, layer)
+		if err != nil {
+			return nil, int64(0), err
+		}
+		if ok {
+			estimatedPkgSize += layer.Size
+			layersToPull = append(layersToPull, layer)
+		}
+	}
+
+	return layersToPull, estimatedPkgSize, nil
+}
+
+// publishFromStore publishes a bundle held in a local OCI layout to a remote OCI registry
+func publishFromStore(ctx context.Context, store *ocistore.Store, index ocispec.Index, bundleRootDesc ocispec.Descriptor, bundle types.UDSBundle, remote *oci.OrasRemote, opts types.BundlePublishOptions) error {
+	// multi-arch bundles publish the bundle root manifest of every arch
+	rootDescs := []ocispec.Descriptor{bundleRootDesc}
+	if len(index.Manifests) > 1 {
+		rootDescs = index.Manifests
+	}
+
+	// the root manifests are merged into the index of the bundle's tag and of each additional tag
+	tags := []string{remote.Repo().Reference.Reference}
+	for _, tag := range opts.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	// check every tag before pushing anything
+	if opts.NoOverwrite {
+		for _, rootDesc := range rootDescs {
+			if rootDesc.Platform == nil {
+				rootDesc.Platform = &ocispec.Platform{Architecture: bundle.Metadata.Architecture, OS: oci.MultiOS}
+			}
+			if err := boci.CheckOverwriteTags(ctx, remote.Repo(), tags, rootDesc); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, rootDesc := range rootDescs {
+		archBundle := bundle
+		if rootDesc.Platform != nil {
+			archBundle.Metadata.Architecture = rootDesc.Platform.Architecture
+		}
+		if err := publishRootManifest(ctx, store, archBundle, rootDesc, remote, tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishRootManifest publishes a single bundle root manifest and its layers and adds it to the index of each tag
+func publishRootManifest(ctx context.Context, store *ocistore.Store, bundle types.UDSBundle, rootDesc ocispec.Descriptor, remote *oci.OrasRemote, tags []string) error {
+	var layersToPush []ocispec.Descriptor
+	rootManifestBytes, err := content.FetchAll(ctx, store, rootDesc)
+	if err != nil {
+		return err
+	}
+	var bundleRootManifest oci.Manifest
+	if err := json.Unmarshal(rootManifestBytes, &bundleRootManifest); err != nil {
+		return err
+	}
+	estimatedBytes := int64(0)
+
+	// push bundle layers to remote
+	for _, manifestDesc := range bundleRootManifest.Layers {
+		layersToPush = append(layersToPush, manifestDesc)
+		if _, ok := manifestDesc.Annotations[ocispec.AnnotationTitle]; ok {
+			continue // uds-bundle.yaml, signatures and provenance don't have layers
+		}
+		layers, estimatedPkgSize, err := getZarfLayers(ctx, store, manifestDesc)
+		estimatedBytes += estimatedPkgSize
+		if err != nil {
+			return err
+		}
+		layersToPush = append(layersToPush, layers...)
+	}
+
+	// grab image config
+	layersToPush = append(layersToPush, bundleRootManifest.Config)
+
+	// copy bundle
+	copyOpts := boci.CreateCopyOpts(layersToPush, config.CommonOptions.OCIConcurrency)
+	progressBar := message.NewProgressBar(estimatedBytes, fmt.Sprintf("Publishing %s:%s (%s)", remote.Repo().Reference.Repository, remote.Repo().Reference.Reference, bundle.Metadata.Architecture))
+	defer progressBar.Close()
+	remote.SetProgressWriter(progressBar)
+	defer remote.ClearProgressWriter()
+
+	// copy by digest, the root manifest is only tagged through the indexes that hold it
+	ref := rootDesc.Digest.String()
+
+	// copy bundle layers to remote with retries
+	maxRetries := 3
+	retries := 0
+
+	// reset retries if a desc was successful
+	copyOpts.PostCopy = func(_ context.Context, _ ocispec.Descriptor) error {
+		retries = 0
+		return nil
+	}
+
+	for {
+		_, err = oras.Copy(ctx, store, ref, remote.Repo(), ref, copyOpts)
+		if err != nil && retries < maxRetries {
+			retries++
+			message.Debugf("Encountered err during publish: %s\nRetrying %d/%d", err, retries, maxRetries)
+			continue
+		} else if err != nil {
+			return err
+		}
+		break
+	}
+
+	// create or update, then push the index of each tag, additional tags that don't exist yet start from the index of
+	// the bundle's tag so that they hold every architecture of the bundle
+	for _, tag := range tags {
+		index, err := boci.GetIndex(remote, tag)
+		if err != nil {
+			return err
+		}
+		if index == nil && tag != tags[0] {
+			if index, err = boci.GetIndex(remote, tags[0]); err != nil {
+				return err
+			}
+		}
+		if err := boci.UpdateIndex(index, remote, &bundle, rootDesc, tag); err != nil {
+			return err
+		}
+	}
+
+	// attach the bundle's provenance to the bundle root manifest so that it can be discovered with the referrers API
+	if provenanceDesc := bundleRootManifest.Locate(config.BundleProvenance); !oci.IsEmptyDescriptor(provenanceDesc) {
+		provenanceBytes, err := content.FetchAll(ctx, store, provenanceDesc)
+		if err != nil {
+			return err
+		}
+		if _, err := boci.AttachReferrer(ctx, remote.Repo(), rootDesc, config.BundleProvenanceArtifactType, config.BundleProvenanceArtifactType, provenanceBytes); err != nil {
+			return fmt.Errorf("unable to attach provenance to %s: %w", remote.Repo().Reference, err)
+		}
+	}
+
+	progressBar.Successf("Published %s", remote.Repo().Reference)
 	return nil
 }
@@ -0,0 +1,102 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func TestPublishTags(t *testing.T) {
+	ctx := context.Background()
+
+	// publish writes a single-arch bundle to an OCI layout dir and publishes it to tag
+	publish := func(t *testing.T, repo *remote.Repository, arch, tag string, opts types.BundlePublishOptions) (ocispec.Descriptor, error) {
+		t.Helper()
+		dir, b := ocitest.NewOCIDir(t, ocitest.BundleOptions{Arch: arch})
+		provider, err := NewBundleProvider(dir, t.TempDir())
+		require.NoError(t, err)
+		remote, err := zoci.NewRemote(ctx, repo.Reference.String()+":"+tag, ocispec.Platform{Architecture: arch, OS: oci.MultiOS}, oci.WithPlainHTTP(true))
+		require.NoError(t, err)
+		bundle := types.UDSBundle{Metadata: types.UDSMetadata{Name: "example", Version: "0.0.1", Architecture: arch}}
+		return b.Root, provider.PublishBundle(bundle, remote.OrasRemote, opts)
+	}
+
+	// requireIndex checks that each tag points to the same index holding the root manifests
+	requireIndex := func(t *testing.T, repo *remote.Repository, tags []string, rootDescs ...ocispec.Descriptor) {
+		t.Helper()
+		var want []string
+		for _, rootDesc := range rootDescs {
+			want = append(want, rootDesc.Digest.String())
+		}
+		var indexDigests []string
+		for _, tag := range tags {
+			indexDesc, err := repo.Resolve(ctx, tag)
+			require.NoError(t, err)
+			require.Equal(t, ocispec.MediaTypeImageIndex, indexDesc.MediaType)
+			index, err := fetchIndex(ctx, repo, indexDesc)
+			require.NoError(t, err)
+			var got []string
+			for _, manifest := range index.Manifests {
+				got = append(got, manifest.Digest.String())
+			}
+			require.ElementsMatch(t, want, got, tag)
+			indexDigests = append(indexDigests, indexDesc.Digest.String())
+		}
+		for _, indexDigest := range indexDigests {
+			require.Equal(t, indexDigests[0], indexDigest)
+		}
+	}
+
+	t.Run("two architectures with a shared tag", func(t *testing.T) {
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+		amd64, err := publish(t, repo, "amd64", "0.0.1", types.BundlePublishOptions{Tags: []string{"latest"}})
+		require.NoError(t, err)
+		arm64, err := publish(t, repo, "arm64", "0.0.1", types.BundlePublishOptions{Tags: []string{"latest"}})
+		require.NoError(t, err)
+		requireIndex(t, repo, []string{"0.0.1", "latest"}, amd64, arm64)
+	})
+
+	t.Run("version that differs from the metadata version", func(t *testing.T) {
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+		amd64, err := publish(t, repo, "amd64", "custom", types.BundlePublishOptions{Version: "custom", Tags: []string{"edge"}})
+		require.NoError(t, err)
+		arm64, err := publish(t, repo, "arm64", "custom", types.BundlePublishOptions{Version: "custom", Tags: []string{"edge"}})
+		require.NoError(t, err)
+		requireIndex(t, repo, []string{"custom", "edge"}, amd64, arm64)
+
+		// only the tags that were asked for are pushed
+		_, err = repo.Resolve(ctx, "0.0.1")
+		require.ErrorIs(t, err, errdef.ErrNotFound)
+	})
+
+	t.Run("no overwrite with a tag holding a different index", func(t *testing.T) {
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+		amd64, err := publish(t, repo, "amd64", "0.0.1", types.BundlePublishOptions{})
+		require.NoError(t, err)
+		stable, err := publish(t, repo, "amd64-old", "stable", types.BundlePublishOptions{})
+		require.NoError(t, err)
+
+		_, err = publish(t, repo, "arm64", "0.0.1", types.BundlePublishOptions{NoOverwrite: true, Tags: []string{"stable"}})
+		require.ErrorIs(t, err, boci.ErrTagConflict)
+		requireIndex(t, repo, []string{"0.0.1"}, amd64)
+		requireIndex(t, repo, []string{"stable"}, stable)
+
+		// adding an architecture to the tags that hold the bundle is still allowed
+		arm64, err := publish(t, repo, "arm64", "0.0.1", types.BundlePublishOptions{NoOverwrite: true, Tags: []string{"0.0.1", "next"}})
+		require.NoError(t, err)
+		requireIndex(t, repo, []string{"0.0.1", "next"}, amd64, arm64)
+	})
+}
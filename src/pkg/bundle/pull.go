@@ -8,28 +8,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	goyaml "github.com/goccy/go-yaml"
 	"github.com/mholt/archives"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	zarfConfig "github.com/zarf-dev/zarf/src/config"
 	"github.com/zarf-dev/zarf/src/pkg/message"
-	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
 )
 
 // Pull pulls a bundle and saves it locally
-func (b *Bundle) Pull() error {
+func (b *Bundle) Pull() (err error) {
 	ctx := context.TODO()
-	tmpDstDir, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
-	if err != nil {
-		return fmt.Errorf("pull bundle unable to create temp directory: %w", err)
-	}
 
 	// Get validated source path
 	source, err := CheckOCISourcePath(b.cfg.PullOpts.Source)
@@ -38,33 +40,66 @@ func (b *Bundle) Pull() error {
 	}
 	b.cfg.PullOpts.Source = source
 
-	provider, err := NewBundleProvider(b.cfg.PullOpts.Source, tmpDstDir)
+	// create a remote client just to resolve the root descriptor
+	platform := ocispec.Platform{
+		Architecture: config.GetArch(),
+		OS:           oci.MultiOS,
+	}
+	remote, err := zoci.NewRemote(ctx, b.cfg.PullOpts.Source, platform)
 	if err != nil {
 		return err
 	}
 
-	// pull the bundle's uds-bundle.yaml and it's Zarf pkgs
-	bundle, filepaths, err := provider.LoadBundle(b.cfg.PullOpts, zarfConfig.CommonOptions.OCIConcurrency)
+	// fetch the bundle's root descriptor
+	rootDesc, err := remote.ResolveRoot(ctx)
 	if err != nil {
 		return err
 	}
-	b.bundle = *bundle
 
-	// create a remote client just to resolve the root descriptor
-	platform := ocispec.Platform{
-		Architecture: config.GetArch(),
-		OS:           oci.MultiOS,
+	// blobs are staged in the cache so that an interrupted pull can be resumed
+	unlock, err := cache.LockPull(rootDesc.Digest.Encoded())
+	if err != nil {
+		return err
 	}
-	remote, err := zoci.NewRemote(ctx, b.cfg.PullOpts.Source, platform)
+	defer unlock()
+	stagingDir := cache.PullDir(rootDesc.Digest.Encoded())
+	if !b.cfg.PullOpts.Resume {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			return err
+		}
+	} else if helpers.IsDir(stagingDir) {
+		message.Infof("Resuming pull of %s", b.cfg.PullOpts.Source)
+	}
+	if err := helpers.CreateDirectory(stagingDir, 0o700); err != nil {
+		return fmt.Errorf("pull bundle unable to create staging directory: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			message.Warnf("The pulled blobs were kept in %s, run the pull again with --resume to continue where it left off", stagingDir)
+			return
+		}
+		_ = os.RemoveAll(stagingDir)
+	}()
+
+	provider, err := NewBundleProvider(b.cfg.PullOpts.Source, stagingDir)
 	if err != nil {
 		return err
 	}
 
-	// fetch the bundle's root descriptor
-	rootDesc, err := remote.ResolveRoot(ctx)
+	// pull the bundle's uds-bundle.yaml and it's Zarf pkgs
+	bundle, filepaths, err := provider.LoadBundle(b.cfg.PullOpts, zarfConfig.CommonOptions.OCIConcurrency)
 	if err != nil {
 		return err
 	}
+	b.bundle = *bundle
+
+	// a bundle pulled with only some of its packages gets its own uds-bundle.yaml and root manifest
+	if len(b.cfg.PullOpts.Packages) != 0 {
+		rootDesc, err = deriveBundle(ctx, remote, rootDesc, b.cfg.PullOpts.Source, &b.bundle, filepaths, stagingDir)
+		if err != nil {
+			return err
+		}
+	}
 
 	// make an index.json for this bundle and write to tmp
 	index := ocispec.Index{}
@@ -84,32 +119,41 @@ func (b *Bundle) Pull() error {
 		return err
 	}
 
-	// tarball the bundle
-	filename := fmt.Sprintf("%s%s-%s-%s.tar.zst", config.BundlePrefix, b.bundle.Metadata.Name, b.bundle.Metadata.Architecture, b.bundle.Metadata.Version)
-	dst := filepath.Join(b.cfg.PullOpts.OutputDirectory, filename)
-
-	_ = os.RemoveAll(dst)
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
 	pathMap := make(types.PathMap)
 
-	// put the index.json and oci-layout at the root of the tarball
+	// put the index.json and oci-layout at the root of the bundle
 	pathMap[filepath.Join(b.tmp, "index.json")] = "index.json"
-	pathMap[filepath.Join(tmpDstDir, "oci-layout")] = "oci-layout"
+	pathMap[filepath.Join(stagingDir, "oci-layout")] = "oci-layout"
 
 	// re-map the paths to be relative to the cache directory
 	for sha, abs := range filepaths {
-		if sha == config.BundleYAML || sha == config.BundleYAMLSignature {
+		if sha == config.BundleYAML || config.IsBundleSignature(sha) || sha == config.BundleProvenance {
 			sha = filepath.Base(abs)
 		}
 		pathMap[abs] = filepath.Join(config.BlobsDir, sha)
 	}
 
+	name := fmt.Sprintf("%s%s-%s-%s", config.BundlePrefix, b.bundle.Metadata.Name, b.bundle.Metadata.Architecture, b.bundle.Metadata.Version)
+	if b.cfg.PullOpts.Format == config.BundleFormatOCIDir {
+		dst := filepath.Join(b.cfg.PullOpts.OutputDirectory, name)
+		if err := writeOCIDir(dst, pathMap); err != nil {
+			return err
+		}
+		message.Debug("Bundle OCI layout saved to", dst)
+		return nil
+	}
+
+	// tarball the bundle
+	dst := filepath.Join(b.cfg.PullOpts.OutputDirectory, name+".tar.zst")
+
+	_ = os.RemoveAll(dst)
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
 	files, err := archives.FilesFromDisk(context.TODO(), nil, pathMap)
 	if err != nil {
 		return err
@@ -122,5 +166,105 @@ func (b *Bundle) Pull() error {
 
 	message.Debug("Create tarball saved to", dst)
 
+	return utils.SplitBundleTarball(dst, b.cfg.PullOpts.MaxPackageSizeMB)
+}
+
+// writeOCIDir copies a pulled bundle's files into dst as an unpacked OCI image layout, replacing anything already there
+func writeOCIDir(dst string, pathMap types.PathMap) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	for src, rel := range pathMap {
+		if err := helpers.CreatePathAndCopy(src, filepath.Join(dst, rel)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// deriveBundle replaces a pulled bundle's uds-bundle.yaml and root manifest with ones that only reference the packages
+// that were pulled, marking the bundle as derived from the one it was pulled from. The bundle's signatures and
+// provenance are dropped since they describe the original bundle
+func deriveBundle(ctx context.Context, remote *zoci.Remote, rootDesc ocispec.Descriptor, source string, bundle *types.UDSBundle, filepaths types.PathMap, dstDir string) (ocispec.Descriptor, error) {
+	rootManifest, err := remote.FetchRoot(ctx)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	pkgManifests := make(map[string]bool)
+	for _, pkg := range bundle.Packages {
+		_, sha, ok := strings.Cut(pkg.Ref, "@sha256:")
+		if !ok {
+			return ocispec.Descriptor{}, fmt.Errorf("package %s has no digest in its ref %s", pkg.Name, pkg.Ref)
+		}
+		pkgManifests[sha] = true
+	}
+	for name := range bundle.Build.PackageSignatures {
+		if !slices.ContainsFunc(bundle.Packages, func(pkg types.Package) bool { return pkg.Name == name }) {
+			delete(bundle.Build.PackageSignatures, name)
+		}
+	}
+	bundle.Build.DerivedFrom = &types.DerivedFrom{Source: source, Digest: rootDesc.Digest.String()}
+
+	bundleYAMLBytes, err := goyaml.Marshal(bundle)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	bundleYAMLDesc, bundleYAMLPath, err := writeBlob(dstDir, zoci.ZarfLayerMediaTypeBlob, bundleYAMLBytes)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	bundleYAMLDesc.Annotations = map[string]string{ocispec.AnnotationTitle: config.BundleYAML}
+	filepaths[config.BundleYAML] = bundleYAMLPath
+
+	derived := rootManifest.Manifest
+	derived.Layers = nil
+	droppedSignatures := false
+	for _, layer := range rootManifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		switch {
+		case title == "" && !pkgManifests[layer.Digest.Encoded()]:
+			continue
+		case title == config.BundleYAML:
+			layer = bundleYAMLDesc
+		case config.IsBundleSignature(title) || title == config.BundleProvenance:
+			droppedSignatures = droppedSignatures || config.IsBundleSignature(title)
+			delete(filepaths, title)
+			continue
+		}
+		derived.Layers = append(derived.Layers, layer)
+	}
+	if droppedSignatures {
+		message.Warn("The bundle's signatures don't cover a bundle with only some of its packages and were not kept")
+	}
+
+	// mark the root manifest as derived from the original using the OCI base image annotations
+	derived.Annotations = maps.Clone(derived.Annotations)
+	if derived.Annotations == nil {
+		derived.Annotations = make(map[string]string)
+	}
+	derived.Annotations[ocispec.AnnotationBaseImageName] = source
+	derived.Annotations[ocispec.AnnotationBaseImageDigest] = rootDesc.Digest.String()
+	manifestBytes, err := json.Marshal(derived)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	manifestDesc, manifestPath, err := writeBlob(dstDir, ocispec.MediaTypeImageManifest, manifestBytes)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	delete(filepaths, rootDesc.Digest.Encoded())
+	filepaths[manifestDesc.Digest.Encoded()] = manifestPath
+	manifestDesc.Platform = rootDesc.Platform
+	return manifestDesc, nil
+}
+
+// writeBlob writes a blob to the blobs dir of dstDir, returning its descriptor and path
+func writeBlob(dstDir, mediaType string, b []byte) (ocispec.Descriptor, string, error) {
+	desc := content.NewDescriptorFromBytes(mediaType, b)
+	path := filepath.Join(dstDir, config.BlobsDir, desc.Digest.Encoded())
+	if err := os.WriteFile(path, b, helpers.ReadWriteUser); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	return desc, path, nil
+}
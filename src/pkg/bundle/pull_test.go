@@ -0,0 +1,113 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/pkgsignature"
+	goyaml "github.com/goccy/go-yaml"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+func TestDeriveBundle(t *testing.T) {
+	ctx := context.Background()
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+	title := func(title string) map[string]string {
+		return map[string]string{ocispec.AnnotationTitle: title}
+	}
+
+	// the root manifest holds two packages along with the bundle's signature and provenance
+	initPkg := ocitest.PushBlob(t, repo, zoci.ZarfLayerMediaTypeBlob, []byte(`{"layers":["init"]}`), nil)
+	nginx := ocitest.PushBlob(t, repo, zoci.ZarfLayerMediaTypeBlob, []byte(`{"layers":["nginx"]}`), nil)
+	bundleYAML := ocitest.PushBlob(t, repo, zoci.ZarfLayerMediaTypeBlob, []byte("kind: UDSBundle"), title(config.BundleYAML))
+	signature := ocitest.PushBlob(t, repo, zoci.ZarfLayerMediaTypeBlob, []byte("signature"), title(config.BundleYAMLSignature))
+	provenance := ocitest.PushBlob(t, repo, zoci.ZarfLayerMediaTypeBlob, []byte("provenance"), title(config.BundleProvenance))
+	rootDesc, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, "application/vnd.test.bundle", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{initPkg, nginx, bundleYAML, signature, provenance},
+		ManifestAnnotations: map[string]string{
+			ocispec.AnnotationVersion: "0.0.1",
+			ocispec.AnnotationCreated: "2024-06-01T12:00:00Z",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Tag(ctx, rootDesc, "0.0.1"))
+
+	remote, err := zoci.NewRemote(ctx, repo.Reference.String()+":0.0.1", ocispec.Platform{Architecture: "amd64", OS: oci.MultiOS}, oci.WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	dstDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dstDir, config.BlobsDir), 0o700))
+	filepaths := types.PathMap{
+		config.BundleYAML:          filepath.Join(dstDir, config.BlobsDir, bundleYAML.Digest.Encoded()),
+		config.BundleYAMLSignature: filepath.Join(dstDir, config.BlobsDir, signature.Digest.Encoded()),
+		config.BundleProvenance:    filepath.Join(dstDir, config.BlobsDir, provenance.Digest.Encoded()),
+		rootDesc.Digest.Encoded():  filepath.Join(dstDir, config.BlobsDir, rootDesc.Digest.Encoded()),
+		nginx.Digest.Encoded():     filepath.Join(dstDir, config.BlobsDir, nginx.Digest.Encoded()),
+	}
+	bundle := types.UDSBundle{
+		Kind:     "UDSBundle",
+		Metadata: types.UDSMetadata{Name: "example", Version: "0.0.1"},
+		Build: types.UDSBuildData{PackageSignatures: map[string]pkgsignature.Status{
+			"init":  pkgsignature.Verified,
+			"nginx": pkgsignature.Unsigned,
+		}},
+		Packages: []types.Package{{Name: "nginx", Ref: "0.0.1@" + nginx.Digest.String()}},
+	}
+
+	source := "oci://" + repo.Reference.String() + ":0.0.1"
+
+	// packages without the digest appended at create time can't be matched to the root manifest
+	noDigest := bundle
+	noDigest.Packages = []types.Package{{Name: "nginx", Ref: "0.0.1"}}
+	_, err = deriveBundle(ctx, remote, rootDesc, source, &noDigest, types.PathMap{}, dstDir)
+	require.ErrorContains(t, err, "package nginx has no digest in its ref 0.0.1")
+
+	derivedDesc, err := deriveBundle(ctx, remote, rootDesc, source, &bundle, filepaths, dstDir)
+	require.NoError(t, err)
+	require.Equal(t, ocispec.MediaTypeImageManifest, derivedDesc.MediaType)
+
+	// the derived root manifest only has the selected package and the new uds-bundle.yaml
+	manifestBytes, err := os.ReadFile(filepaths[derivedDesc.Digest.Encoded()])
+	require.NoError(t, err)
+	require.Equal(t, derivedDesc.Digest, content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestBytes).Digest)
+	var derived ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestBytes, &derived))
+	require.Len(t, derived.Layers, 2)
+	require.Equal(t, nginx.Digest, derived.Layers[0].Digest)
+	require.Equal(t, config.BundleYAML, derived.Layers[1].Annotations[ocispec.AnnotationTitle])
+	require.Equal(t, map[string]string{
+		ocispec.AnnotationVersion:         "0.0.1",
+		ocispec.AnnotationCreated:         "2024-06-01T12:00:00Z",
+		ocispec.AnnotationBaseImageName:   source,
+		ocispec.AnnotationBaseImageDigest: rootDesc.Digest.String(),
+	}, derived.Annotations)
+
+	// the new uds-bundle.yaml is marked as derived and replaces the original along with its signatures and provenance
+	require.Equal(t, filepath.Join(dstDir, config.BlobsDir, derived.Layers[1].Digest.Encoded()), filepaths[config.BundleYAML])
+	var derivedBundle types.UDSBundle
+	bundleYAMLBytes, err := os.ReadFile(filepaths[config.BundleYAML])
+	require.NoError(t, err)
+	require.NoError(t, goyaml.Unmarshal(bundleYAMLBytes, &derivedBundle))
+	require.Equal(t, &types.DerivedFrom{Source: source, Digest: rootDesc.Digest.String()}, derivedBundle.Build.DerivedFrom)
+	require.Equal(t, map[string]pkgsignature.Status{"nginx": pkgsignature.Unsigned}, derivedBundle.Build.PackageSignatures)
+	require.Len(t, derivedBundle.Packages, 1)
+	require.NotContains(t, filepaths, config.BundleYAMLSignature)
+	require.NotContains(t, filepaths, config.BundleProvenance)
+	require.NotContains(t, filepaths, rootDesc.Digest.Encoded())
+	require.Contains(t, filepaths, nginx.Digest.Encoded())
+}
@@ -0,0 +1,374 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pterm/pterm"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// referrersTag matches the tags registries without the referrers API use to track referrers, such as signatures
+var referrersTag = regexp.MustCompile(`^sha256-[a-f0-9]{64}$`)
+
+// publishedBundle is a tag of a bundle published to a repository
+type publishedBundle struct {
+	Tag           string
+	Digest        string
+	Architectures []string
+	Version       string
+	Created       string
+	// Size is the total size of the bundle's blobs across all architectures
+	Size int64
+}
+
+// RegistryList lists the bundles published to a repository
+func (b *Bundle) RegistryList() error {
+	ctx := context.TODO()
+	repo, err := registryRepo(ctx, b.cfg.RegistryOpts.Source)
+	if err != nil {
+		return err
+	}
+
+	bundles, unrecognized, err := listPublishedBundles(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if len(unrecognized) > 0 {
+		message.Notef("Skipped %d tag(s) that don't point to a bundle: %s", len(unrecognized), strings.Join(unrecognized, ", "))
+	}
+	if len(bundles) == 0 {
+		message.Warnf("No bundles found in %s", repo.Reference)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(bundles))
+	for _, bundle := range bundles {
+		rows = append(rows, []string{
+			bundle.Tag,
+			strings.TrimPrefix(bundle.Digest, "sha256:")[:12],
+			strings.Join(bundle.Architectures, ", "),
+			bundle.Version,
+			bundle.Created,
+			zarfUtils.ByteFormat(float64(bundle.Size), 2),
+		})
+	}
+	// print to stdout to enable users to easily grab the output
+	pterm.SetDefaultOutput(os.Stdout)
+	defer pterm.SetDefaultOutput(os.Stderr)
+	message.Table([]string{"Tag", "Digest", "Architectures", "Version", "Created", "Size"}, rows)
+	return nil
+}
+
+// RegistryRemove removes a single architecture from a published bundle's index, or the bundle's tag entirely
+func (b *Bundle) RegistryRemove() error {
+	ctx := context.TODO()
+	repo, err := registryRepo(ctx, b.cfg.RegistryOpts.Source)
+	if err != nil {
+		return err
+	}
+	tag := repo.Reference.Reference
+	if tag == "" {
+		return fmt.Errorf("%s must include the tag to remove", b.cfg.RegistryOpts.Source)
+	}
+
+	tags, removed, err := removePublishedBundle(ctx, repo, tag, b.cfg.RegistryOpts.Arch)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		message.Successf("Removed %s from %s:%s", b.cfg.RegistryOpts.Arch, repo.Reference.Repository, strings.Join(tags, ", "))
+		return nil
+	}
+	message.Successf("Removed %s:%s", repo.Reference.Repository, strings.Join(tags, ", "))
+	return nil
+}
+
+// registryRepo returns the repository of an oci:// ref using the CLI's registry configuration
+func registryRepo(ctx context.Context, source string) (*remote.Repository, error) {
+	platform := ocispec.Platform{
+		Architecture: config.GetArch(),
+		OS:           oci.MultiOS,
+	}
+	r, err := zoci.NewRemote(ctx, boci.EnsureOCIPrefix(source), platform)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repo(), nil
+}
+
+// errNotBundle is returned for tags that don't point to a bundle, such as images, Zarf packages and signatures
+var errNotBundle = errors.New("not a bundle")
+
+// listPublishedBundles lists every tag of a repository along with the bundle it points to, and the tags that don't
+// point to a bundle
+func listPublishedBundles(ctx context.Context, repo *remote.Repository) ([]publishedBundle, []string, error) {
+	var tags []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		for _, tag := range page {
+			if !referrersTag.MatchString(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("unable to list tags of %s: %w", repo.Reference, err)
+	}
+	slices.Sort(tags)
+
+	// tags commonly share root manifests and packages so only size each one once
+	sizes := make(map[string]int64)
+	bundles := make([]publishedBundle, 0, len(tags))
+	var unrecognized []string
+	for _, tag := range tags {
+		bundle, err := describePublishedBundle(ctx, repo, tag, sizes)
+		if errors.Is(err, errNotBundle) {
+			message.Debugf("Skipping %s:%s: %s", repo.Reference.Repository, tag, err)
+			unrecognized = append(unrecognized, tag)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, unrecognized, nil
+}
+
+// describePublishedBundle describes the bundle a tag points to, returning errNotBundle if it doesn't point to one
+func describePublishedBundle(ctx context.Context, repo *remote.Repository, tag string, sizes map[string]int64) (publishedBundle, error) {
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return publishedBundle{}, err
+	}
+	bundle := publishedBundle{Tag: tag, Digest: desc.Digest.String()}
+
+	var rootDescs []ocispec.Descriptor
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex:
+		index, err := fetchIndex(ctx, repo, desc)
+		if err != nil {
+			return publishedBundle{}, err
+		}
+		rootDescs = index.Manifests
+	case ocispec.MediaTypeImageManifest:
+		rootDescs = []ocispec.Descriptor{desc}
+	default:
+		return publishedBundle{}, fmt.Errorf("%w: unsupported media type %s", errNotBundle, desc.MediaType)
+	}
+	for _, rootDesc := range rootDescs {
+		if rootDesc.MediaType != ocispec.MediaTypeImageManifest {
+			return publishedBundle{}, fmt.Errorf("%w: unsupported media type %s", errNotBundle, rootDesc.MediaType)
+		}
+		rootManifest, err := fetchRootManifest(ctx, repo, rootDesc)
+		if err != nil {
+			return publishedBundle{}, err
+		}
+		if !slices.ContainsFunc(rootManifest.Layers, func(layer ocispec.Descriptor) bool {
+			return layer.Annotations[ocispec.AnnotationTitle] == config.BundleYAML
+		}) {
+			return publishedBundle{}, fmt.Errorf("%w: %s has no %s", errNotBundle, rootDesc.Digest, config.BundleYAML)
+		}
+		arch, err := rootManifestArch(ctx, repo, rootDesc, rootManifest)
+		if err != nil {
+			return publishedBundle{}, err
+		}
+		if arch != "" {
+			bundle.Architectures = append(bundle.Architectures, arch)
+		}
+		if bundle.Version == "" {
+			bundle.Version = rootManifest.Annotations[ocispec.AnnotationVersion]
+		}
+		if bundle.Created == "" {
+			bundle.Created = rootManifest.Annotations[ocispec.AnnotationCreated]
+		}
+		size, err := rootManifestSize(ctx, repo, rootDesc, rootManifest, sizes)
+		if err != nil {
+			return publishedBundle{}, err
+		}
+		bundle.Size += size
+	}
+	return bundle, nil
+}
+
+// rootManifestArch returns the architecture of a bundle root manifest from its platform in an index or, for root
+// manifests that are tagged directly, from its config
+func rootManifestArch(ctx context.Context, repo *remote.Repository, rootDesc ocispec.Descriptor, rootManifest *ocispec.Manifest) (string, error) {
+	if rootDesc.Platform != nil {
+		return rootDesc.Platform.Architecture, nil
+	}
+	b, err := content.FetchAll(ctx, repo.Blobs(), rootManifest.Config)
+	if err != nil {
+		return "", err
+	}
+	var manifestConfig oci.ConfigPartial
+	if err := json.Unmarshal(b, &manifestConfig); err != nil {
+		return "", fmt.Errorf("%w: %s has an invalid config", errNotBundle, rootDesc.Digest)
+	}
+	return manifestConfig.Architecture, nil
+}
+
+// rootManifestSize returns the total size of a bundle root manifest, its config, its layers and the blobs of its
+// Zarf packages that were bundled
+func rootManifestSize(ctx context.Context, repo *remote.Repository, rootDesc ocispec.Descriptor, rootManifest *ocispec.Manifest, sizes map[string]int64) (int64, error) {
+	if size, ok := sizes[rootDesc.Digest.String()]; ok {
+		return size, nil
+	}
+	size := rootDesc.Size + rootManifest.Config.Size
+	for _, layer := range rootManifest.Layers {
+		size += layer.Size
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
+			continue
+		}
+		pkgSize, ok := sizes[layer.Digest.String()]
+		if !ok {
+			pkgManifest, err := fetchRootManifest(ctx, repo, layer)
+			if err != nil {
+				return 0, err
+			}
+			pkgSize = pkgManifest.Config.Size
+			// bundles only contain the layers of a package's selected components
+			for _, pkgLayer := range pkgManifest.Layers {
+				exists, err := repo.Exists(ctx, pkgLayer)
+				if err != nil {
+					return 0, err
+				}
+				if exists {
+					pkgSize += pkgLayer.Size
+				}
+			}
+			sizes[layer.Digest.String()] = pkgSize
+		}
+		size += pkgSize
+	}
+	sizes[rootDesc.Digest.String()] = size
+	return size, nil
+}
+
+// removePublishedBundle removes an architecture from the index a tag points to, or deletes what the tag points to if
+// no architecture is given or it is the index's last architecture. Every tag that pointed to the same index is updated
+// along with the tag, and registries can only delete manifests by digest so every tag that pointed to a deleted
+// manifest is removed with it. It returns the tags that were updated or removed and whether they were removed
+func removePublishedBundle(ctx context.Context, repo *remote.Repository, tag, arch string) ([]string, bool, error) {
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return nil, false, err
+	}
+	tags, err := tagsOf(ctx, repo, desc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if arch != "" {
+		if desc.MediaType != ocispec.MediaTypeImageIndex {
+			return nil, false, fmt.Errorf("%s:%s is not a multi-arch bundle, remove the whole tag instead", repo.Reference.Repository, tag)
+		}
+		index, err := fetchIndex(ctx, repo, desc)
+		if err != nil {
+			return nil, false, err
+		}
+		manifests := slices.DeleteFunc(slices.Clone(index.Manifests), func(manifest ocispec.Descriptor) bool {
+			return manifest.Platform != nil && manifest.Platform.Architecture == arch
+		})
+		if len(manifests) == len(index.Manifests) {
+			return nil, false, fmt.Errorf("%s:%s does not contain architecture %s", repo.Reference.Repository, tag, arch)
+		}
+		// the root manifest is left for the registry to garbage collect since other indexes may still use it
+		if len(manifests) > 0 {
+			index.Manifests = manifests
+			indexBytes, err := json.Marshal(index)
+			if err != nil {
+				return nil, false, err
+			}
+			indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexBytes)
+			for _, t := range tags {
+				if err := repo.PushReference(ctx, indexDesc, bytes.NewReader(indexBytes), t); err != nil {
+					return nil, false, fmt.Errorf("unable to update %s:%s: %w", repo.Reference.Repository, t, err)
+				}
+			}
+			return tags, false, nil
+		}
+	}
+
+	if err := repo.Delete(ctx, desc); err != nil {
+		return nil, false, fmt.Errorf("unable to delete %s:%s: %w", repo.Reference.Repository, tag, err)
+	}
+	return tags, true, nil
+}
+
+// tagsOf returns every tag of a repository that points to desc
+func tagsOf(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) ([]string, error) {
+	var tags []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		for _, tag := range page {
+			if referrersTag.MatchString(tag) {
+				continue
+			}
+			tagDesc, err := repo.Resolve(ctx, tag)
+			if err != nil {
+				return err
+			}
+			if tagDesc.Digest == desc.Digest {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
+
+func fetchIndex(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) (*ocispec.Index, error) {
+	b, err := content.FetchAll(ctx, repo, desc)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// fetchRootManifest fetches a bundle root manifest or a Zarf package manifest pushed as a blob
+func fetchRootManifest(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	var b []byte
+	var err error
+	if desc.MediaType == ocispec.MediaTypeImageManifest {
+		b, err = content.FetchAll(ctx, repo, desc)
+	} else {
+		b, err = content.FetchAll(ctx, repo.Blobs(), desc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Layers == nil && desc.MediaType != ocispec.MediaTypeImageManifest {
+		return nil, errors.New("not a manifest")
+	}
+	return &manifest, nil
+}
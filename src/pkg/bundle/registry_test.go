@@ -0,0 +1,110 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func TestListPublishedBundles(t *testing.T) {
+	ctx := context.Background()
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+
+	amd64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "amd64"})
+	arm64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "arm64"})
+	amd64Size, arm64Size := amd64.Size(), arm64.Size()
+
+	multi := ocitest.PushIndex(t, repo, "0.0.1", amd64.Root, arm64.Root)
+	require.NoError(t, repo.Tag(ctx, multi, "latest"))
+	single := ocitest.PushIndex(t, repo, "0.0.1-amd64", amd64.Root)
+	// a root manifest tagged directly takes its architecture from its config
+	require.NoError(t, repo.Tag(ctx, arm64.Root, "0.0.1-arm64"))
+	// referrer fallback tags aren't bundles
+	require.NoError(t, repo.Tag(ctx, amd64.Root, "sha256-"+amd64.Root.Digest.Encoded()))
+
+	// tags that don't point to a bundle, such as images and signatures, are skipped
+	imageConfig := ocitest.PushBlob(t, repo, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64"}`), nil)
+	image, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, "", oras.PackManifestOptions{ConfigDescriptor: &imageConfig})
+	require.NoError(t, err)
+	require.NoError(t, repo.Tag(ctx, image, "image"))
+	require.NoError(t, repo.Tag(ctx, image, "sha256-"+image.Digest.Encoded()+".sig"))
+	ocitest.PushIndex(t, repo, "image-index", image)
+
+	bundles, unrecognized, err := listPublishedBundles(ctx, repo)
+	require.NoError(t, err)
+	require.Equal(t, []publishedBundle{
+		{Tag: "0.0.1", Digest: multi.Digest.String(), Architectures: []string{"amd64", "arm64"}, Version: "0.0.1", Created: "2024-06-01T12:00:00Z", Size: amd64Size + arm64Size},
+		{Tag: "0.0.1-amd64", Digest: single.Digest.String(), Architectures: []string{"amd64"}, Version: "0.0.1", Created: "2024-06-01T12:00:00Z", Size: amd64Size},
+		{Tag: "0.0.1-arm64", Digest: arm64.Root.Digest.String(), Architectures: []string{"arm64"}, Version: "0.0.1", Created: "2024-06-01T12:00:00Z", Size: arm64Size},
+		{Tag: "latest", Digest: multi.Digest.String(), Architectures: []string{"amd64", "arm64"}, Version: "0.0.1", Created: "2024-06-01T12:00:00Z", Size: amd64Size + arm64Size},
+	}, bundles)
+	require.Equal(t, []string{"image", "image-index", "sha256-" + image.Digest.Encoded() + ".sig"}, unrecognized)
+}
+
+func TestRemovePublishedBundle(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*remote.Repository, ocispec.Descriptor, ocispec.Descriptor) {
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+		amd64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "amd64"})
+		arm64 := ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: "arm64"})
+		index := ocitest.PushIndex(t, repo, "0.0.1", amd64.Root, arm64.Root)
+		require.NoError(t, repo.Tag(ctx, index, "latest"))
+		return repo, index, arm64.Root
+	}
+
+	t.Run("single arch", func(t *testing.T) {
+		repo, index, arm64 := setup(t)
+		updatedTags, removed, err := removePublishedBundle(ctx, repo, "0.0.1", "amd64")
+		require.NoError(t, err)
+		require.False(t, removed)
+		// every tag of the old index is updated
+		require.Equal(t, []string{"0.0.1", "latest"}, updatedTags)
+
+		for _, tag := range updatedTags {
+			desc, err := repo.Resolve(ctx, tag)
+			require.NoError(t, err)
+			require.NotEqual(t, index.Digest, desc.Digest)
+			updated, err := fetchIndex(ctx, repo, desc)
+			require.NoError(t, err)
+			require.Equal(t, []ocispec.Descriptor{arm64}, updated.Manifests)
+		}
+
+		// removing the last arch removes the tags
+		removedTags, removed, err := removePublishedBundle(ctx, repo, "0.0.1", "arm64")
+		require.NoError(t, err)
+		require.True(t, removed)
+		require.Equal(t, []string{"0.0.1", "latest"}, removedTags)
+	})
+
+	t.Run("missing arch", func(t *testing.T) {
+		repo, _, _ := setup(t)
+		_, _, err := removePublishedBundle(ctx, repo, "latest", "s390x")
+		require.ErrorContains(t, err, "does not contain architecture s390x")
+	})
+
+	t.Run("whole tag", func(t *testing.T) {
+		repo, index, _ := setup(t)
+		removedTags, removed, err := removePublishedBundle(ctx, repo, "latest", "")
+		require.NoError(t, err)
+		require.True(t, removed)
+		require.Equal(t, []string{"0.0.1", "latest"}, removedTags)
+		_, err = repo.Resolve(ctx, index.Digest.String())
+		require.Error(t, err)
+	})
+
+	t.Run("missing tag", func(t *testing.T) {
+		repo, _, _ := setup(t)
+		_, _, err := removePublishedBundle(ctx, repo, "0.0.2", "")
+		require.Error(t, err)
+	})
+}
@@ -11,11 +11,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -23,6 +25,7 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 	"golang.org/x/exp/slices"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -53,6 +56,10 @@ func (op *ociProvider) getBundleManifest() (*oci.Manifest, error) {
 	return nil, errors.New("bundle root manifest not loaded")
 }
 
+func (op *ociProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	return op.ResolveRoot(context.TODO())
+}
+
 // LoadBundleMetadata loads a remote bundle's metadata
 func (op *ociProvider) LoadBundleMetadata() (types.PathMap, error) {
 	ctx := context.TODO()
@@ -60,7 +67,11 @@ func (op *ociProvider) LoadBundleMetadata() (types.PathMap, error) {
 		return nil, err
 	}
 
-	layers, err := op.PullPaths(ctx, filepath.Join(op.dst, config.BlobsDir), config.BundleAlwaysPull)
+	rootManifest, err := op.getBundleManifest()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := op.PullPaths(ctx, filepath.Join(op.dst, config.BlobsDir), bundleMetadataPaths(rootManifest))
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +95,7 @@ func (op *ociProvider) LoadBundleMetadata() (types.PathMap, error) {
 }
 
 // CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
-func (op *ociProvider) CreateBundleSBOM(extractSBOM bool, bundleName string) ([]string, error) {
+func (op *ociProvider) CreateBundleSBOM(extractSBOM bool, bundleName string, collection *sbom.Collection) ([]string, error) {
 	var warns []string
 	ctx := context.TODO()
 	SBOMArtifactPathMap := make(types.PathMap)
@@ -101,7 +112,8 @@ func (op *ociProvider) CreateBundleSBOM(extractSBOM bool, bundleName string) ([]
 
 	// iterate through Zarf image manifests and find the Zarf pkg's sboms.tar
 	for _, layer := range root.Layers {
-		if layer.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
+		// only Zarf pkg manifests are missing a title annotation
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
 			continue
 		}
 		zarfManifest, err := op.OrasRemote.FetchManifest(ctx, layer)
@@ -119,6 +131,12 @@ func (op *ociProvider) CreateBundleSBOM(extractSBOM bool, bundleName string) ([]
 		if err != nil {
 			return warns, err
 		}
+		if collection != nil {
+			if err := collection.AddTar(ctx, zarfManifest.Annotations[ocispec.AnnotationTitle], bytes.NewReader(sbomBytes)); err != nil {
+				return warns, err
+			}
+			continue
+		}
 
 		extractor := utils.SBOMExtractor(op.dst, SBOMArtifactPathMap)
 		err = archives.Tar{}.Extract(context.TODO(), bytes.NewReader(sbomBytes), extractor)
@@ -127,6 +145,9 @@ func (op *ociProvider) CreateBundleSBOM(extractSBOM bool, bundleName string) ([]
 		}
 	}
 
+	if collection != nil {
+		return warns, nil
+	}
 	return utils.HandleSBOM(extractSBOM, SBOMArtifactPathMap, bundleName, op.dst)
 }
 
@@ -145,15 +166,18 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 	}
 
 	// validate the sig (if present) before pulling the whole bundle
-	if err := ValidateBundleSignature(filepaths[config.BundleYAML], filepaths[config.BundleYAMLSignature], opts.PublicKeyPath); err != nil {
+	if _, err := validateBundleSignatures(ctx, op, opts.Source, filepaths, opts.PublicKeyPaths, op.dst); err != nil {
 		return nil, nil, err
 	}
 
-	var layersToPull []ocispec.Descriptor
-	// need to keep track of all the bundle layers (pulled and cached)
-	var bundleLayers []ocispec.Descriptor
-
-	estimatedBytes := int64(0)
+	// Check if --packages flag is set and zarf packages have been specified
+	if len(opts.Packages) != 0 {
+		selectedPackages, err := selectPackages(bundle.Packages, opts.Packages)
+		if err != nil {
+			return nil, nil, err
+		}
+		bundle.Packages = selectedPackages
+	}
 
 	// get the bundle's root manifest
 	rootManifest, err := op.getBundleManifest()
@@ -161,21 +185,17 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 		return nil, nil, err
 	}
 
-	// grab root manifest config
-	layersToPull = append(layersToPull, rootManifest.Config)
-
 	store, err := ocistore.NewWithContext(ctx, op.dst)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// grab the bundle root manifest and add it to the layers to pull
+	// grab the bundle root manifest and its config
 	bundleRootDesc, err := op.ResolveRoot(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	layersToPull = append(layersToPull, bundleRootDesc)
-	bundleLayers = append(bundleLayers, layersToPull...)
+	bundleLayers := []ocispec.Descriptor{rootManifest.Config, bundleRootDesc}
 
 	for _, pkg := range bundle.Packages {
 		// go through the pkg's layers and figure out which ones to pull based on the req'd + selected components
@@ -184,32 +204,30 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 			return nil, nil, err
 		}
 
-		// check if the layer already exists in the cache or the store
+		// copy layers that are in the cache to the store, layers that are already in the store are skipped when pulling
 		for _, layer := range pkgLayers {
-			exists, err := cache.CheckLayerExists(ctx, layer, store, op.dst)
-			if err != nil {
+			if _, err := cache.CheckLayerExists(ctx, layer, store, op.dst); err != nil {
 				return nil, nil, err
 			}
-			// if layers don't already exist on disk, add to layersToPull
-			if !exists {
-				layersToPull = append(layersToPull, layer)
-				estimatedBytes += layer.Size
-			}
 		}
 		bundleLayers = append(bundleLayers, pkgLayers...)
 	}
 
-	// pull layers that didn't already exist on disk
-	if len(layersToPull) > 0 {
-		_, err := boci.CopyLayers(layersToPull, estimatedBytes, op.dst, op.Repo(), store, bundle.Metadata.Name)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		err = cache.AddPulledImgLayers(layersToPull, op.dst)
-		if err != nil {
+	// pull with retries, partially pulled layers are resumed on the next attempt
+	maxRetries := 3
+	for retries := 0; ; retries++ {
+		err := boci.PullLayers(ctx, bundleLayers, op.dst, op.Repo(), bundle.Metadata.Name)
+		if err != nil && retries < maxRetries {
+			message.Debugf("Encountered err during pull: %s\nRetrying %d/%d", err, retries+1, maxRetries)
+			continue
+		} else if err != nil {
 			return nil, nil, err
 		}
+		break
+	}
+	// the bundle was pulled, so layers that can't be cached are only fetched again next time
+	if err := cache.AddPulledLayers(bundleLayers, op.dst); err != nil {
+		message.Warnf("Unable to cache the pulled layers: %s", err)
 	}
 
 	for _, layer := range bundleLayers {
@@ -220,9 +238,46 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 	return &bundle, filepaths, nil
 }
 
-func (op *ociProvider) PublishBundle(_ types.UDSBundle, _ *oci.OrasRemote) error {
-	// todo: implement moving bundles from one registry to another
-	return errors.New("moving bundles in between remote registries not yet supported")
+// PublishBundle copies a remote bundle to another OCI repo, streaming it between the registries
+func (op *ociProvider) PublishBundle(_ types.UDSBundle, remote *oci.OrasRemote, opts types.BundlePublishOptions) error {
+	ctx := context.TODO()
+	src := op.Repo()
+	dst := remote.Repo()
+
+	if opts.NoOverwrite {
+		srcDesc, err := src.Resolve(ctx, src.Reference.Reference)
+		if err != nil {
+			return err
+		}
+		for _, tag := range append([]string{dst.Reference.Reference}, opts.Tags...) {
+			if err := boci.CheckOverwrite(ctx, dst, tag, srcDesc); err != nil {
+				return err
+			}
+		}
+	}
+
+	spinner := message.NewProgressSpinner("Publishing %s to %s", src.Reference, dst.Reference)
+	defer spinner.Stop()
+	var copiedBytes atomic.Int64
+	onCopied := func(desc ocispec.Descriptor) {
+		spinner.Updatef("Publishing %s to %s (%s)", src.Reference, dst.Reference, zarfUtils.ByteFormat(float64(copiedBytes.Add(desc.Size)), 2))
+	}
+
+	// copy with retries, blobs that were already copied are skipped on the next attempt
+	maxRetries := 3
+	for retries := 0; ; retries++ {
+		_, err := boci.CopyBundle(ctx, src, dst, src.Reference.Reference, dst.Reference.Reference, config.CommonOptions.OCIConcurrency, onCopied)
+		if err != nil && retries < maxRetries {
+			message.Debugf("Encountered err during publish: %s\nRetrying %d/%d", err, retries+1, maxRetries)
+			continue
+		} else if err != nil {
+			return err
+		}
+		break
+	}
+
+	spinner.Successf("Published %s to %s", src.Reference, dst.Reference)
+	return nil
 }
 
 // Returns the validated source path based on the provided oci source path
@@ -315,9 +370,9 @@ func ValidateArch(arch string) error {
 
 // CheckOCISourcePath checks that provided oci source path is valid, and updates it if it's missing the full path
 func CheckOCISourcePath(source string) (string, error) {
-	validTarballPath := utils.IsValidTarballPath(source)
+	isLocalBundle := utils.IsValidTarballPath(source) || utils.IsValidBundleDir(source)
 	var err error
-	if !validTarballPath {
+	if !isLocalBundle {
 		source, err = getOCIValidatedSource(source)
 		if err != nil {
 			return "", err
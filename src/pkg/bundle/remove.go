@@ -6,7 +6,6 @@ package bundle
 
 import (
 	"context"
-	"errors"
 	"strings"
 
 	"github.com/defenseunicorns/uds-cli/src/config"
@@ -40,6 +39,7 @@ func (b *Bundle) Remove() error {
 	if err != nil {
 		return err
 	}
+	b.cfg.RemoveOpts.Source = localBundleSource(provider, b.cfg.RemoveOpts.Source)
 
 	// pull the bundle's metadata + sig
 	filepaths, err := provider.LoadBundleMetadata()
@@ -53,19 +53,10 @@ func (b *Bundle) Remove() error {
 	}
 
 	// Check if --packages flag is set and zarf packages have been specified
-	var packagesToRemove []types.Package
-
 	if len(b.cfg.RemoveOpts.Packages) != 0 {
-		userSpecifiedPackages := strings.Split(strings.ReplaceAll(b.cfg.RemoveOpts.Packages[0], " ", ""), ",")
-		for _, pkg := range b.bundle.Packages {
-			if slices.Contains(userSpecifiedPackages, pkg.Name) {
-				packagesToRemove = append(packagesToRemove, pkg)
-			}
-		}
-
-		// Check if invalid packages were specified
-		if len(userSpecifiedPackages) != len(packagesToRemove) {
-			return errors.New("invalid zarf packages specified by --packages")
+		packagesToRemove, err := selectPackages(b.bundle.Packages, b.cfg.RemoveOpts.Packages)
+		if err != nil {
+			return err
 		}
 		return removePackages(packagesToRemove, b)
 	}
@@ -0,0 +1,129 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
+	"github.com/defenseunicorns/uds-cli/src/pkg/scan"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/pterm/pterm"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+)
+
+// Scan scans the SBOMs of a bundle's images and Zarf components for vulnerabilities, failing if any are at or above
+// the --fail-on severity
+func (b *Bundle) Scan() error {
+	ctx := context.TODO()
+	opts := b.cfg.ScanOpts
+
+	source, err := CheckOCISourcePath(opts.Source)
+	if err != nil {
+		return fmt.Errorf("source %s is either invalid or doesn't exist", opts.Source)
+	}
+	provider, err := NewBundleProvider(source, b.tmp)
+	if err != nil {
+		return err
+	}
+	filepaths, err := provider.LoadBundleMetadata()
+	if err != nil {
+		return err
+	}
+	if err := utils.ReadYAMLStrict(filepaths[config.BundleYAML], &b.bundle); err != nil {
+		return err
+	}
+
+	scanner, err := scan.New(opts.Scanner, opts.ScannerPath, opts.DBPath)
+	if err != nil {
+		return err
+	}
+	defer scanner.Close()
+
+	collection := sbom.NewCollection()
+	warns, err := provider.CreateBundleSBOM(false, b.bundle.Metadata.Name, collection)
+	if err != nil {
+		return err
+	}
+	for _, warn := range warns {
+		message.Warn(warn)
+	}
+	if len(collection.Packages()) == 0 {
+		return errors.New("none of the bundle's packages contain an SBOM to scan")
+	}
+
+	spinner := message.NewProgressSpinner("Scanning %s with %s", b.bundle.Metadata.Name, scanner.Name())
+	defer spinner.Stop()
+	report, err := scan.Run(ctx, scanner, collection, b.tmp)
+	if err != nil {
+		return err
+	}
+	spinner.Successf("Scanned %d image(s) and Zarf component(s) with %s", len(report.Targets), scanner.Name())
+
+	if opts.Format == scan.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printScanReport(report)
+	}
+
+	if opts.FailOn == "" {
+		return nil
+	}
+	if failures := report.Failures(scan.ParseSeverity(opts.FailOn)); failures > 0 {
+		return fmt.Errorf("found %d vulnerabilities at or above %s severity", failures, strings.ToLower(opts.FailOn))
+	}
+	return nil
+}
+
+// printScanReport prints the vulnerabilities of each image and Zarf component followed by a summary of their severities
+func printScanReport(report *scan.Report) {
+	// print to stdout to enable users to easily grab the output
+	pterm.SetDefaultOutput(os.Stdout)
+	defer pterm.SetDefaultOutput(os.Stderr)
+
+	severities := slices.Clone(scan.Severities)
+	slices.Reverse(severities)
+	severities = append(severities, scan.SeverityUnknown.String())
+
+	summary := [][]string{}
+	for _, target := range report.Targets {
+		counts := make(map[string]int)
+		rows := [][]string{}
+		for _, vuln := range target.Vulnerabilities {
+			counts[vuln.Severity.String()]++
+			rows = append(rows, []string{vuln.ID, vuln.Severity.String(), vuln.Component, vuln.Version, strings.Join(vuln.FixedIn, ", ")})
+		}
+		if len(rows) > 0 {
+			pterm.Println()
+			pterm.Println(fmt.Sprintf("%s (%s)", target.Name(), target.Package))
+			message.Table([]string{"Vulnerability", "Severity", "Component", "Version", "Fixed In"}, rows)
+		}
+
+		row := []string{target.Package, target.Name()}
+		for _, severity := range severities {
+			row = append(row, strconv.Itoa(counts[severity]))
+		}
+		summary = append(summary, row)
+	}
+
+	pterm.Println()
+	header := []string{"Package", "Image"}
+	for _, severity := range severities {
+		header = append(header, strings.ToUpper(severity[:1])+severity[1:])
+	}
+	message.Table(header, summary)
+}
@@ -0,0 +1,334 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/interactive"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+// signaturePayload is the name of the file holding the bundle root manifest digest that detached signatures sign
+const signaturePayload = "bundle-root-digest"
+
+// Sign signs an existing bundle with detached signatures, published bundles get the signatures attached as OCI
+// referrers of the bundle's root manifest and bundle tarballs get them written as sidecar files
+func (b *Bundle) Sign() error {
+	ctx := context.TODO()
+	if len(b.cfg.SignOpts.SigningKeyPaths) == 0 {
+		return errors.New("a signing key is required to sign a bundle")
+	}
+
+	source, err := CheckOCISourcePath(b.cfg.SignOpts.Source)
+	if err != nil {
+		return fmt.Errorf("source %s is either invalid or doesn't exist", b.cfg.SignOpts.Source)
+	}
+	b.cfg.SignOpts.Source = source
+
+	provider, err := NewBundleProvider(source, b.tmp)
+	if err != nil {
+		return err
+	}
+	rootDesc, payloadPath, err := writeSignaturePayload(provider, b.tmp)
+	if err != nil {
+		return err
+	}
+
+	getSigCreatePassword := func(_ bool) ([]byte, error) {
+		if b.cfg.SignOpts.SigningKeyPassword != "" {
+			return []byte(b.cfg.SignOpts.SigningKeyPassword), nil
+		}
+		return interactive.PromptSigPassword()
+	}
+
+	for i, signingKeyPath := range b.cfg.SignOpts.SigningKeyPaths {
+		signature, err := zarfUtils.CosignSignBlob(payloadPath, filepath.Join(b.tmp, config.BundleSignatureName(i)), signingKeyPath, getSigCreatePassword)
+		if err != nil {
+			return fmt.Errorf("unable to sign bundle with %s: %w", signingKeyPath, err)
+		}
+
+		if op, ok := provider.(*ociProvider); ok {
+			sigManifestDesc, err := attachSignature(ctx, op.Repo(), rootDesc, signature)
+			if err != nil {
+				return err
+			}
+			message.Successf("Attached signature %s to %s", sigManifestDesc.Digest, source)
+			continue
+		}
+
+		sidecarPath := nextSidecarSignaturePath(source)
+		if err := os.WriteFile(sidecarPath, signature, helpers.ReadWriteUser); err != nil {
+			return err
+		}
+		message.Successf("Wrote signature to %s", sidecarPath)
+	}
+	return nil
+}
+
+// Verify checks the integrity of every blob and package in a bundle without deploying it and, if public keys are
+// provided, verifies the bundle's detached and embedded signatures; the bundle is trusted if any of its signatures is
+// verified by any of the public keys
+func (b *Bundle) Verify() error {
+	ctx := context.TODO()
+	source, err := CheckOCISourcePath(b.cfg.VerifyOpts.Source)
+	if err != nil {
+		return fmt.Errorf("source %s is either invalid or doesn't exist", b.cfg.VerifyOpts.Source)
+	}
+	b.cfg.VerifyOpts.Source = source
+
+	publicKeyPaths, err := trustedPublicKeys(append(slices.Clone(b.cfg.VerifyOpts.PublicKeyPaths), config.CommonOptions.TrustedKeys...), b.tmp)
+	if err != nil {
+		return err
+	}
+
+	provider, err := NewBundleProvider(source, b.tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyIntegrity(ctx, provider, localBundleSource(provider, source)); err != nil {
+		return err
+	}
+
+	// gather the bundle's detached signatures and the signatures embedded in the bundle at create time
+	filepaths, err := provider.LoadBundleMetadata()
+	if err != nil {
+		return err
+	}
+	signatures, err := loadBundleSignatures(ctx, provider, source, filepaths, b.tmp)
+	if err != nil {
+		return err
+	}
+
+	if len(publicKeyPaths) == 0 {
+		if signatures.signed() {
+			message.Note("Bundle is signed, provide a public key with --key to verify its signatures")
+		}
+		return nil
+	}
+	if !signatures.signed() {
+		return fmt.Errorf("bundle %s is not signed", source)
+	}
+	kind, err := signatures.verify(publicKeyPaths)
+	if err != nil {
+		return err
+	}
+	message.Successf("Verified %s signature of %s", kind, source)
+	return nil
+}
+
+// bundleSignatures are a bundle's embedded signatures of its uds-bundle.yaml and its detached signatures of its root
+// manifest digest
+type bundleSignatures struct {
+	bundleYAMLPath string
+	embedded       []string
+	payloadPath    string
+	detached       []string
+}
+
+// loadBundleSignatures gathers a bundle's embedded signatures from its metadata and its detached signatures, which are
+// attached as OCI referrers to published bundles and written as sidecar files next to local bundles
+func loadBundleSignatures(ctx context.Context, provider Provider, source string, filepaths types.PathMap, tmpDir string) (bundleSignatures, error) {
+	signatures := bundleSignatures{
+		bundleYAMLPath: filepaths[config.BundleYAML],
+		embedded:       bundleSignaturePaths(filepaths),
+	}
+
+	rootDesc, payloadPath, err := writeSignaturePayload(provider, tmpDir)
+	if err != nil {
+		return bundleSignatures{}, err
+	}
+	signatures.payloadPath = payloadPath
+
+	if op, ok := provider.(*ociProvider); ok {
+		detached, err := fetchSignatures(ctx, op.Repo(), rootDesc)
+		if err != nil {
+			return bundleSignatures{}, err
+		}
+		for i, signature := range detached {
+			sigPath := filepath.Join(tmpDir, fmt.Sprintf("detached-%d%s", i, config.BundleSignatureSidecarExt))
+			if err := os.WriteFile(sigPath, signature, helpers.ReadWriteUser); err != nil {
+				return bundleSignatures{}, err
+			}
+			signatures.detached = append(signatures.detached, sigPath)
+		}
+		return signatures, nil
+	}
+
+	signatures.detached, err = sidecarSignaturePaths(source)
+	if err != nil {
+		return bundleSignatures{}, err
+	}
+	return signatures, nil
+}
+
+// signed returns true if the bundle has any detached or embedded signatures
+func (s bundleSignatures) signed() bool {
+	return len(s.detached) > 0 || len(s.embedded) > 0
+}
+
+// verify verifies the bundle's signatures against the public keys and returns the kind of signature that was verified,
+// the bundle is trusted if any of its signatures is verified by any of the public keys
+func (s bundleSignatures) verify(publicKeyPaths []string) (string, error) {
+	var errs []error
+	if len(s.detached) > 0 {
+		err := ValidateBundleSignature(s.payloadPath, s.detached, publicKeyPaths)
+		if err == nil {
+			return "detached", nil
+		}
+		errs = append(errs, err)
+	}
+	if len(s.embedded) > 0 {
+		err := ValidateBundleSignature(s.bundleYAMLPath, s.embedded, publicKeyPaths)
+		if err == nil {
+			return "embedded", nil
+		}
+		errs = append(errs, err)
+	}
+	return "", errors.Join(errs...)
+}
+
+// verifyIntegrity checks the integrity of every blob and package in a bundle, reporting each missing or corrupt layer
+func verifyIntegrity(ctx context.Context, provider Provider, source string) error {
+	spinner := message.NewProgressSpinner("Verifying integrity of %s", source)
+	defer spinner.Stop()
+
+	var (
+		src   blobSource
+		roots []ocispec.Descriptor
+	)
+	if op, ok := provider.(*ociProvider); ok {
+		rootDesc, err := op.getBundleRootDesc()
+		if err != nil {
+			return err
+		}
+		src = fetcherBlobSource{fetcher: op.Repo()}
+		roots = []ocispec.Descriptor{rootDesc}
+	} else if dp, ok := provider.(*ociDirBundleProvider); ok {
+		// every architecture in an OCI layout dir is checked
+		store, err := ocistore.NewWithContext(ctx, dp.src)
+		if err != nil {
+			return err
+		}
+		src = fetcherBlobSource{fetcher: store}
+		roots = dp.index.Manifests
+	} else {
+		// every architecture in a bundle tarball is checked
+		tb, err := newTarballBlobSource(ctx, source)
+		if err != nil {
+			return err
+		}
+		src = tb
+		roots = tb.index.Manifests
+	}
+
+	report, err := checkIntegrity(ctx, src, roots)
+	if err != nil {
+		return err
+	}
+	if len(report.problems) > 0 {
+		spinner.Stop()
+		for _, problem := range report.problems {
+			message.Warn(problem)
+		}
+		return fmt.Errorf("bundle %s failed integrity checks with %d problem(s)", source, len(report.problems))
+	}
+	spinner.Successf("Verified the integrity of %d layers in %s", report.checked, source)
+	return nil
+}
+
+// writeSignaturePayload writes the digest of the bundle's root manifest to the payload file in dir that detached
+// signatures sign, the root manifest digest covers every layer in the bundle
+func writeSignaturePayload(provider Provider, dir string) (ocispec.Descriptor, string, error) {
+	rootDesc, err := provider.getBundleRootDesc()
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	payloadPath := filepath.Join(dir, signaturePayload)
+	if err := os.WriteFile(payloadPath, []byte(rootDesc.Digest.String()), helpers.ReadWriteUser); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	return rootDesc, payloadPath, nil
+}
+
+// attachSignature pushes a signature to the target as an OCI referrer of the subject
+func attachSignature(ctx context.Context, target oras.Target, subject ocispec.Descriptor, signature []byte) (ocispec.Descriptor, error) {
+	return boci.AttachReferrer(ctx, target, subject, config.BundleSignatureArtifactType, config.BundleSignatureMediaType, signature)
+}
+
+// fetchSignatures fetches the signatures attached to the subject as OCI referrers
+func fetchSignatures(ctx context.Context, target content.ReadOnlyGraphStorage, subject ocispec.Descriptor) ([][]byte, error) {
+	referrers, err := registry.Referrers(ctx, target, subject, config.BundleSignatureArtifactType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list signatures: %w", err)
+	}
+
+	var signatures [][]byte
+	for _, referrer := range referrers {
+		manifestBytes, err := content.FetchAll(ctx, target, referrer)
+		if err != nil {
+			return nil, err
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return nil, err
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != config.BundleSignatureMediaType {
+				continue
+			}
+			signature, err := content.FetchAll(ctx, target, layer)
+			if err != nil {
+				return nil, err
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+	return signatures, nil
+}
+
+// sidecarSignaturePaths returns the paths of the detached signature files next to a bundle tarball
+func sidecarSignaturePaths(tarballPath string) ([]string, error) {
+	matches, err := filepath.Glob(tarballPath + config.BundleSignatureSidecarExt + "*")
+	if err != nil {
+		return nil, err
+	}
+	var sigPaths []string
+	prefix := tarballPath + config.BundleSignatureSidecarExt
+	for _, match := range matches {
+		if match == prefix || strings.HasPrefix(match, prefix+".") {
+			sigPaths = append(sigPaths, match)
+		}
+	}
+	slices.Sort(sigPaths)
+	return sigPaths, nil
+}
+
+// nextSidecarSignaturePath returns the first unused detached signature file path for a bundle tarball
+func nextSidecarSignaturePath(tarballPath string) string {
+	sigPath := tarballPath + config.BundleSignatureSidecarExt
+	for i := 1; !helpers.InvalidPath(sigPath); i++ {
+		sigPath = fmt.Sprintf("%s%s.%d", tarballPath, config.BundleSignatureSidecarExt, i)
+	}
+	return sigPath
+}
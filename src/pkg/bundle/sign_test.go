@@ -0,0 +1,158 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+	zarfConfig "github.com/zarf-dev/zarf/src/config"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func Test_attachSignature(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("memory store", func(t *testing.T) {
+		store := memory.New()
+		subject := ocitest.PushBundle(t, store, ocitest.BundleOptions{}).Root
+
+		_, err := attachSignature(ctx, store, subject, []byte("signature-1"))
+		require.NoError(t, err)
+		_, err = attachSignature(ctx, store, subject, []byte("signature-2"))
+		require.NoError(t, err)
+
+		signatures, err := fetchSignatures(ctx, store, subject)
+		require.NoError(t, err)
+		require.ElementsMatch(t, [][]byte{[]byte("signature-1"), []byte("signature-2")}, signatures)
+	})
+
+	t.Run("local registry", func(t *testing.T) {
+		repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "test/bundle")
+		subject := ocitest.PushBundle(t, repo, ocitest.BundleOptions{}).Root
+
+		signatures, err := fetchSignatures(ctx, repo, subject)
+		require.NoError(t, err)
+		require.Empty(t, signatures)
+
+		_, err = attachSignature(ctx, repo, subject, []byte("signature"))
+		require.NoError(t, err)
+
+		signatures, err = fetchSignatures(ctx, repo, subject)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("signature")}, signatures)
+	})
+}
+
+func Test_sidecarSignaturePaths(t *testing.T) {
+	tmp := t.TempDir()
+	tarball := filepath.Join(tmp, "uds-bundle-test-amd64-0.0.1.tar.zst")
+	require.NoError(t, os.WriteFile(tarball, []byte("bundle"), 0o600))
+	// a different bundle's signature shouldn't be picked up
+	require.NoError(t, os.WriteFile(tarball+".sigs", []byte("other"), 0o600))
+
+	paths, err := sidecarSignaturePaths(tarball)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	for _, want := range []string{tarball + ".sig", tarball + ".sig.1", tarball + ".sig.2"} {
+		sigPath := nextSidecarSignaturePath(tarball)
+		require.Equal(t, want, sigPath)
+		require.NoError(t, os.WriteFile(sigPath, []byte("signature"), 0o600))
+	}
+
+	paths, err = sidecarSignaturePaths(tarball)
+	require.NoError(t, err)
+	require.Equal(t, []string{tarball + ".sig", tarball + ".sig.1", tarball + ".sig.2"}, paths)
+}
+
+func TestDetachedSignatures(t *testing.T) {
+	ctx := context.Background()
+	plainHTTP := zarfConfig.CommonOptions.PlainHTTP
+	zarfConfig.CommonOptions.PlainHTTP = true
+	cliArch := config.CLIArch
+	config.CLIArch = "amd64"
+	t.Cleanup(func() {
+		zarfConfig.CommonOptions.PlainHTTP = plainHTTP
+		config.CLIArch = cliArch
+	})
+	// deploy checks the bundle's arch against the cluster, if there is one
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	keysDir := t.TempDir()
+	key, sign := writeSigningKey(t, keysDir, "key.pub")
+	otherKey, _ := writeSigningKey(t, keysDir, "other.pub")
+
+	// a local bundle with a sidecar signature
+	dir, signed := ocitest.NewOCIDir(t, ocitest.BundleOptions{Arch: "amd64"})
+	rootDesc := signed.Root
+	sign([]byte(rootDesc.Digest.String()), dir+config.BundleSignatureSidecarExt)
+
+	newBundle := func(t *testing.T, cfg *types.BundleConfig) *Bundle {
+		b, err := New(cfg)
+		require.NoError(t, err)
+		t.Cleanup(b.ClearPaths)
+		return b
+	}
+
+	t.Run("deploy", func(t *testing.T) {
+		b := newBundle(t, &types.BundleConfig{DeployOpts: types.BundleDeployOptions{Source: dir, PublicKeyPaths: []string{otherKey}}})
+		_, _, _, err := b.PreDeployValidation()
+		require.ErrorContains(t, err, "none of the bundle's signatures could be verified")
+
+		b = newBundle(t, &types.BundleConfig{DeployOpts: types.BundleDeployOptions{Source: dir, PublicKeyPaths: []string{key}}})
+		_, _, _, err = b.PreDeployValidation()
+		require.NoError(t, err)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		b := newBundle(t, &types.BundleConfig{InspectOpts: types.BundleInspectOptions{Source: dir, PublicKeyPaths: []string{otherKey}}})
+		require.ErrorContains(t, b.Inspect(), "none of the bundle's signatures could be verified")
+
+		b = newBundle(t, &types.BundleConfig{InspectOpts: types.BundleInspectOptions{Source: dir, PublicKeyPaths: []string{key}}})
+		require.NoError(t, b.Inspect())
+	})
+
+	// publishing the local bundle attaches its sidecar signature as a referrer
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "bundles/test")
+	destination := "oci://" + repo.Reference.Registry + "/bundles"
+	b := newBundle(t, &types.BundleConfig{PublishOpts: types.BundlePublishOptions{Source: dir, Destination: destination}})
+
+	t.Run("publish", func(t *testing.T) {
+		require.NoError(t, b.Publish())
+
+		published, err := remote.NewRepository(repo.Reference.Registry + "/bundles/example")
+		require.NoError(t, err)
+		published.PlainHTTP = true
+		signatures, err := fetchSignatures(ctx, published, rootDesc)
+		require.NoError(t, err)
+		signature, err := os.ReadFile(dir + config.BundleSignatureSidecarExt)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{signature}, signatures)
+	})
+
+	t.Run("pull", func(t *testing.T) {
+		source := destination + "/example:0.0.1"
+		provider, err := NewBundleProvider(source, t.TempDir())
+		require.NoError(t, err)
+		_, _, err = provider.LoadBundle(types.BundlePullOptions{Source: source, PublicKeyPaths: []string{otherKey}}, 1)
+		require.ErrorContains(t, err, "none of the bundle's signatures could be verified")
+
+		cachePath := config.CommonOptions.CachePath
+		config.CommonOptions.CachePath = t.TempDir()
+		t.Cleanup(func() { config.CommonOptions.CachePath = cachePath })
+		provider, err = NewBundleProvider(source, t.TempDir())
+		require.NoError(t, err)
+		_, _, err = provider.LoadBundle(types.BundlePullOptions{Source: source, PublicKeyPaths: []string{key}}, 1)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// blobSizer reads the blobs of a bundle to report its size
+type blobSizer interface {
+	// fetch returns the contents of a blob
+	fetch(ctx context.Context, desc ocispec.Descriptor) ([]byte, error)
+	// size returns the size of a blob, or of its contents once decompressed if the sizer was created to measure
+	// uncompressed sizes, returning errBlobMissing if it isn't in the bundle
+	size(ctx context.Context, desc ocispec.Descriptor) (int64, error)
+}
+
+// storageBlobSizer sizes the blobs of a bundle in an OCI repository or store, blobs are only read to decompress them
+type storageBlobSizer struct {
+	fetcherBlobSource
+	storage      content.ReadOnlyStorage
+	uncompressed bool
+}
+
+func (s storageBlobSizer) size(ctx context.Context, desc ocispec.Descriptor) (int64, error) {
+	if !s.uncompressed {
+		exists, err := s.storage.Exists(ctx, desc)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, errBlobMissing
+		}
+		return desc.Size, nil
+	}
+	rc, err := s.storage.Fetch(ctx, desc)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return 0, errBlobMissing
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	return boci.UncompressedSize(rc)
+}
+
+// tarballBlobSizer sizes the blobs of a bundle tarball, the tarball is read once up front to size every blob
+type tarballBlobSizer struct {
+	// sizes maps the path of each blob in the tarball to its size, or the size of its decompressed contents
+	sizes map[string]int64
+	// buffered holds the contents of the small blobs in the tarball
+	buffered map[string][]byte
+}
+
+// newTarballBlobSizer scans a bundle tarball, decompressing all of its blobs if uncompressed is set
+func newTarballBlobSizer(ctx context.Context, src string, uncompressed bool) (*tarballBlobSizer, error) {
+	ts := &tarballBlobSizer{
+		sizes:    make(map[string]int64),
+		buffered: make(map[string][]byte),
+	}
+
+	tarFile, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer tarFile.Close()
+
+	err = config.BundleArchiveFormat.Extract(ctx, tarFile, func(_ context.Context, file archives.FileInfo) error {
+		if file.IsDir() || !strings.HasPrefix(file.NameInArchive, config.BlobsDir+"/") {
+			return nil
+		}
+		ts.sizes[file.NameInArchive] = file.Size()
+		if !uncompressed && file.Size() > maxBufferedBlobSize {
+			return nil
+		}
+		stream, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		var buf bytes.Buffer
+		r := io.Reader(stream)
+		if file.Size() <= maxBufferedBlobSize {
+			r = io.TeeReader(stream, &buf)
+		}
+		if uncompressed {
+			size, err := boci.UncompressedSize(r)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %w", file.NameInArchive, err)
+			}
+			ts.sizes[file.NameInArchive] = size
+		}
+		// drain what the decompressor didn't read so that small blobs are buffered whole
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return err
+		}
+		if file.Size() <= maxBufferedBlobSize {
+			ts.buffered[file.NameInArchive] = buf.Bytes()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (ts *tarballBlobSizer) fetch(_ context.Context, desc ocispec.Descriptor) ([]byte, error) {
+	b, ok := ts.buffered[filepath.Join(config.BlobsDir, desc.Digest.Encoded())]
+	if !ok {
+		return nil, fmt.Errorf("blob %s is %w", desc.Digest, errBlobMissing)
+	}
+	return b, nil
+}
+
+func (ts *tarballBlobSizer) size(_ context.Context, desc ocispec.Descriptor) (int64, error) {
+	size, ok := ts.sizes[filepath.Join(config.BlobsDir, desc.Digest.Encoded())]
+	if !ok {
+		return 0, errBlobMissing
+	}
+	return size, nil
+}
+
+// newBlobSizer returns a blobSizer reading the blobs of the bundle a provider was created for, measuring the
+// uncompressed size of blobs if uncompressed is set and otherwise using the sizes of their descriptors
+func newBlobSizer(ctx context.Context, provider Provider, source string, uncompressed bool) (blobSizer, error) {
+	switch p := provider.(type) {
+	case *ociProvider:
+		return storageBlobSizer{fetcherBlobSource: fetcherBlobSource{fetcher: p.Repo()}, storage: p.Repo(), uncompressed: uncompressed}, nil
+	case *ociDirBundleProvider:
+		store, err := ocistore.NewWithContext(ctx, p.src)
+		if err != nil {
+			return nil, err
+		}
+		return storageBlobSizer{fetcherBlobSource: fetcherBlobSource{fetcher: store}, storage: store, uncompressed: uncompressed}, nil
+	default:
+		return newTarballBlobSizer(ctx, source, uncompressed)
+	}
+}
+
+// packageSizeReport is the size of a Zarf package in a bundle
+type packageSizeReport struct {
+	boci.PackageSize
+	// Uncompressed is the size of the package's layers once decompressed, if the blobSizer measured it
+	Uncompressed int64
+}
+
+// bundleSizes returns the size of each of the bundle's packages in the order they appear in the bundle root manifest,
+// along with the total size of the packages' layers once each digest is stored only once
+func bundleSizes(ctx context.Context, src blobSizer, rootManifest *oci.Manifest, bundle types.UDSBundle) ([]packageSizeReport, int64, error) {
+	pkgNames := make(map[string]string, len(bundle.Packages))
+	for _, pkg := range bundle.Packages {
+		if _, sha, ok := strings.Cut(pkg.Ref, "@sha256:"); ok {
+			pkgNames[sha] = pkg.Name
+		}
+	}
+
+	uncompressed := make(map[string]int64)
+	var pkgLayers []boci.PackageLayers
+	var pkgUncompressed []int64
+	for _, layer := range rootManifest.Layers {
+		// Zarf image manifests are the only layers missing a title annotation
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
+			continue
+		}
+		b, err := src.fetch(ctx, layer)
+		if err != nil {
+			return nil, 0, err
+		}
+		var pkgManifest ocispec.Manifest
+		if err := json.Unmarshal(b, &pkgManifest); err != nil {
+			return nil, 0, err
+		}
+
+		name := pkgNames[layer.Digest.Encoded()]
+		if name == "" {
+			name = pkgManifest.Annotations[ocispec.AnnotationTitle]
+		}
+		pkg := boci.PackageLayers{Name: name, Layers: []ocispec.Descriptor{layer}}
+		uncompressedTotal := int64(len(b))
+		// only the layers of the package's selected components are in the bundle
+		for _, pkgLayer := range append([]ocispec.Descriptor{pkgManifest.Config}, pkgManifest.Layers...) {
+			size, ok := uncompressed[pkgLayer.Digest.String()]
+			if !ok {
+				size, err = src.size(ctx, pkgLayer)
+				if errors.Is(err, errBlobMissing) {
+					continue
+				}
+				if err != nil {
+					return nil, 0, err
+				}
+				uncompressed[pkgLayer.Digest.String()] = size
+			}
+			pkg.Layers = append(pkg.Layers, pkgLayer)
+			uncompressedTotal += size
+		}
+		pkgLayers = append(pkgLayers, pkg)
+		pkgUncompressed = append(pkgUncompressed, uncompressedTotal)
+	}
+
+	pkgSizes, total := boci.PackageSizes(pkgLayers)
+	reports := make([]packageSizeReport, 0, len(pkgSizes))
+	for i, pkgSize := range pkgSizes {
+		reports = append(reports, packageSizeReport{PackageSize: pkgSize, Uncompressed: pkgUncompressed[i]})
+	}
+	return reports, total, nil
+}
+
+// printSizeReport prints the compressed size of each of the bundle's packages, along with how much of each package is
+// unique to it and how much it shares with the bundle's other packages. Layers are only decompressed to report the
+// packages' uncompressed size when --uncompressed is set
+func (b *Bundle) printSizeReport(provider Provider) error {
+	ctx := context.TODO()
+	rootManifest, err := provider.getBundleManifest()
+	if err != nil {
+		return err
+	}
+	spinner := message.NewProgressSpinner("Measuring the packages in %s", b.cfg.InspectOpts.Source)
+	defer spinner.Stop()
+	src, err := newBlobSizer(ctx, provider, b.cfg.InspectOpts.Source, b.cfg.InspectOpts.Uncompressed)
+	if err != nil {
+		return err
+	}
+	reports, total, err := bundleSizes(ctx, src, rootManifest, b.bundle)
+	if err != nil {
+		return err
+	}
+	spinner.Successf("Measured %d packages", len(reports))
+
+	byteFormat := func(size int64) string {
+		return zarfUtils.ByteFormat(float64(size), 2)
+	}
+	header := []string{"Package", "Compressed", "Unique", "Shared"}
+	if b.cfg.InspectOpts.Uncompressed {
+		header = []string{"Package", "Compressed", "Uncompressed", "Unique", "Shared"}
+	}
+	rows := make([][]string, 0, len(reports))
+	var sum int64
+	for _, report := range reports {
+		sum += report.Size
+		row := []string{report.Name, byteFormat(report.Size), byteFormat(report.Unique), byteFormat(report.Shared)}
+		if b.cfg.InspectOpts.Uncompressed {
+			row = slices.Insert(row, 2, byteFormat(report.Uncompressed))
+		}
+		rows = append(rows, row)
+	}
+	message.Table(header, rows)
+	message.Infof("The packages' layers total %s, %s once layers shared between packages are stored once", byteFormat(sum), byteFormat(total))
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+func TestBundleSizes(t *testing.T) {
+	dir, _ := ocitest.NewOCIDir(t, ocitest.BundleOptions{})
+	provider, err := NewBundleProvider(dir, t.TempDir())
+	require.NoError(t, err)
+	rootManifest, err := provider.getBundleManifest()
+	require.NoError(t, err)
+
+	for _, uncompressed := range []bool{false, true} {
+		src, err := newBlobSizer(context.Background(), provider, dir, uncompressed)
+		require.NoError(t, err)
+
+		reports, total, err := bundleSizes(context.Background(), src, rootManifest, types.UDSBundle{})
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		report := reports[0]
+		// the package manifest, config and component layer are all unique to the only package
+		require.Equal(t, report.Size, report.Unique)
+		require.Zero(t, report.Shared)
+		require.Equal(t, total, report.Size)
+		// none of the layers are compressed
+		require.Equal(t, report.Size, report.Uncompressed)
+	}
+}
+
+func TestStorageBlobSizer(t *testing.T) {
+	ctx := context.Background()
+	store, err := ocistore.NewWithContext(ctx, t.TempDir())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err = gw.Write(bytes.Repeat([]byte("a"), 4096))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	layer := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, buf.Bytes())
+	require.NoError(t, store.Push(ctx, layer, bytes.NewReader(buf.Bytes())))
+	missing := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("missing"))
+
+	// without --uncompressed the descriptor's size is used
+	sizer := storageBlobSizer{fetcherBlobSource: fetcherBlobSource{fetcher: store}, storage: store}
+	size, err := sizer.size(ctx, layer)
+	require.NoError(t, err)
+	require.Equal(t, layer.Size, size)
+	_, err = sizer.size(ctx, missing)
+	require.ErrorIs(t, err, errBlobMissing)
+
+	sizer.uncompressed = true
+	size, err = sizer.size(ctx, layer)
+	require.NoError(t, err)
+	require.Equal(t, int64(4096), size)
+	_, err = sizer.size(ctx, missing)
+	require.ErrorIs(t, err, errBlobMissing)
+}
@@ -15,14 +15,13 @@ import (
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/mholt/archives"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"github.com/zarf-dev/zarf/src/pkg/message"
 	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
-	"oras.land/oras-go/v2"
 	ocistore "oras.land/oras-go/v2/content/oci"
 )
 
@@ -30,14 +29,17 @@ type tarballBundleProvider struct {
 	ctx context.Context
 	src string
 	dst string
+	// arch selects the bundle root manifest from multi-arch bundles
+	arch string
 
 	// these fields are populated by loadBundleManifest as part of the provider constructor
 	bundleRootDesc ocispec.Descriptor
 	rootManifest   *oci.Manifest
+	index          ocispec.Index
 }
 
 // CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
-func (tp *tarballBundleProvider) CreateBundleSBOM(extractSBOM bool, bundleName string) ([]string, error) {
+func (tp *tarballBundleProvider) CreateBundleSBOM(extractSBOM bool, bundleName string, collection *sbom.Collection) ([]string, error) {
 	var warns []string
 	rootManifest, err := tp.getBundleManifest()
 	if err != nil {
@@ -53,8 +55,8 @@ func (tp *tarballBundleProvider) CreateBundleSBOM(extractSBOM bool, bundleName s
 	SBOMArtifactPathMap := make(types.PathMap)
 
 	for _, layer := range rootManifest.Layers {
-		// get Zarf image manifests from bundle manifest
-		if layer.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
+		// get Zarf image manifests from bundle manifest, only they are missing a title annotation
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
 			continue
 		}
 
@@ -110,14 +112,21 @@ func (tp *tarballBundleProvider) CreateBundleSBOM(extractSBOM bool, bundleName s
 			return warns, err
 		}
 
-		extractor := utils.SBOMExtractor(tp.dst, SBOMArtifactPathMap)
-		err = archives.Tar{}.Extract(context.TODO(), sbomTarFile, extractor)
+		if collection != nil {
+			err = collection.AddTar(context.TODO(), zarfImageManifest.Annotations[ocispec.AnnotationTitle], sbomTarFile)
+		} else {
+			extractor := utils.SBOMExtractor(tp.dst, SBOMArtifactPathMap)
+			err = archives.Tar{}.Extract(context.TODO(), sbomTarFile, extractor)
+		}
 		sbomTarFile.Close() // Close the file after extraction
 		if err != nil {
 			return warns, err
 		}
 	}
 
+	if collection != nil {
+		return warns, nil
+	}
 	return utils.HandleSBOM(extractSBOM, SBOMArtifactPathMap, bundleName, tp.dst)
 }
 
@@ -128,6 +137,13 @@ func (tp *tarballBundleProvider) getBundleManifest() (*oci.Manifest, error) {
 	return nil, errors.New("bundle root manifest not loaded")
 }
 
+func (tp *tarballBundleProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	if tp.rootManifest != nil {
+		return tp.bundleRootDesc, nil
+	}
+	return ocispec.Descriptor{}, errors.New("bundle root manifest not loaded")
+}
+
 // loadBundleManifest loads the bundle's root manifest and desc into the tarballBundleProvider so we don't have to load it multiple times
 func (tp *tarballBundleProvider) loadBundleManifest() error {
 	// Create a secure temporary directory for handling files
@@ -152,13 +168,13 @@ func (tp *tarballBundleProvider) loadBundleManifest() error {
 		return err
 	}
 
-	// local bundles only have one manifest entry in their index.json
-	bundleManifestDesc := index.Manifests[0]
-	tp.bundleRootDesc = bundleManifestDesc
-
-	if len(index.Manifests) > 1 {
-		return fmt.Errorf("expected only one manifest in index.json, found %d", len(index.Manifests))
+	// multi-arch bundles have a bundle root manifest per arch in their index.json
+	bundleManifestDesc, err := boci.SelectRootManifest(index, tp.arch)
+	if err != nil {
+		return err
 	}
+	tp.bundleRootDesc = bundleManifestDesc
+	tp.index = index
 
 	manifestRelativePath := filepath.Join(config.BlobsDir, bundleManifestDesc.Digest.Encoded())
 	manifestPath := filepath.Join(secureTempDir, manifestRelativePath)
@@ -209,7 +225,7 @@ func (tp *tarballBundleProvider) LoadBundleMetadata() (types.PathMap, error) {
 	if err != nil {
 		return nil, err
 	}
-	pathsToExtract := config.BundleAlwaysPull
+	pathsToExtract := bundleMetadataPaths(bundleRootManifest)
 
 	filepaths := make(types.PathMap)
 
@@ -240,115 +256,12 @@ func (tp *tarballBundleProvider) LoadBundleMetadata() (types.PathMap, error) {
 	return filepaths, nil
 }
 
-// getZarfLayers returns the layers of the Zarf package that are in the bundle
-func (tp *tarballBundleProvider) getZarfLayers(store *ocistore.Store, pkgManifestDesc ocispec.Descriptor) ([]ocispec.Descriptor, int64, error) {
-	var layersToPull []ocispec.Descriptor
-	estimatedPkgSize := int64(0)
-
-	// Open the layer file for streaming
-	layerFile, err := os.Open(filepath.Join(tp.dst, config.BlobsDir, pkgManifestDesc.Digest.Encoded()))
-	if err != nil {
-		return nil, int64(0), err
-	}
-	defer layerFile.Close()
-
-	var zarfImageManifest *oci.Manifest
-	decoder := json.NewDecoder(layerFile)
-	if err := decoder.Decode(&zarfImageManifest); err != nil {
-		return nil, int64(0), err
-	}
-
-	// only grab image layers that we want
-	for _, layer := range zarfImageManifest.Manifest.Layers {
-		ok, err := store.Exists(tp.ctx, layer)
-		if err != nil {
-			return nil, int64(0), err
-		}
-		if ok {
-			estimatedPkgSize += layer.Size
-			layersToPull = append(layersToPull, layer)
-		}
-	}
-
-	return layersToPull, estimatedPkgSize, nil
-}
-
 // PublishBundle publishes a local bundle to a remote OCI registry
-func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote) error {
-	var layersToPush []ocispec.Descriptor
-	bundleRootManifest, err := tp.getBundleManifest()
-	if err != nil {
-		return err
-	}
-	estimatedBytes := int64(0)
-
+func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, opts types.BundlePublishOptions) error {
 	// reference local store holding untarred bundle
 	store, err := ocistore.NewWithContext(tp.ctx, tp.dst)
 	if err != nil {
 		return err
 	}
-	// push bundle layers to remote
-	for _, manifestDesc := range bundleRootManifest.Layers {
-		layersToPush = append(layersToPush, manifestDesc)
-		if manifestDesc.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
-			continue // uds-bundle.yaml doesn't have layers
-		}
-		layers, estimatedPkgSize, err := tp.getZarfLayers(store, manifestDesc)
-		estimatedBytes += estimatedPkgSize
-		if err != nil {
-			return err
-		}
-		layersToPush = append(layersToPush, layers...)
-	}
-
-	// grab image config
-	layersToPush = append(layersToPush, bundleRootManifest.Config)
-
-	// copy bundle
-	copyOpts := boci.CreateCopyOpts(layersToPush, config.CommonOptions.OCIConcurrency)
-	progressBar := message.NewProgressBar(estimatedBytes, fmt.Sprintf("Publishing %s:%s", remote.Repo().Reference.Repository, remote.Repo().Reference.Reference))
-	defer progressBar.Close()
-	remote.SetProgressWriter(progressBar)
-	defer remote.ClearProgressWriter()
-
-	srcRef := bundle.Metadata.Version
-	// use tag given to remote e.g. ghcr.io/path/my-bundle:tag
-	dstRef := remote.Repo().Reference.Reference
-
-	// check for existing index
-	index, err := boci.GetIndex(remote, srcRef)
-	if err != nil {
-		return err
-	}
-
-	// copy bundle layers to remote with retries
-	maxRetries := 3
-	retries := 0
-
-	// reset retries if a desc was successful
-	copyOpts.PostCopy = func(_ context.Context, _ ocispec.Descriptor) error {
-		retries = 0
-		return nil
-	}
-
-	for {
-		_, err = oras.Copy(tp.ctx, store, srcRef, remote.Repo(), dstRef, copyOpts)
-		if err != nil && retries < maxRetries {
-			retries++
-			message.Debugf("Encountered err during publish: %s\nRetrying %d/%d", err, retries, maxRetries)
-			continue
-		} else if err != nil {
-			return err
-		}
-		break
-	}
-
-	// create or update, then push index.json
-	err = boci.UpdateIndex(index, remote, &bundle, tp.bundleRootDesc)
-	if err != nil {
-		return err
-	}
-
-	progressBar.Successf("Published %s", remote.Repo().Reference)
-	return nil
+	return publishFromStore(tp.ctx, store, tp.index, tp.bundleRootDesc, bundle, remote, opts)
 }
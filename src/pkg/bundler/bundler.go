@@ -6,17 +6,29 @@ package bundler
 
 import (
 	"context"
+	"errors"
 
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
 )
 
 // Bundler is used for bundling packages
 type Bundler struct {
-	bundle    *types.UDSBundle
-	output    string
-	tmpDstDir string
-	sourceDir string
+	bundle     *types.UDSBundle
+	bundles    []*types.UDSBundle
+	signatures map[string][][]byte
+	provenance map[string]*provenance.Statement
+	output     string
+	tmpDstDir  string
+	sourceDir  string
+	// maxPackageSizeMB is the size in megabytes above which bundle tarballs are split into parts, 0 disables splitting
+	maxPackageSizeMB int
+	// base is a previously created bundle to copy unchanged packages from
+	base *fetcher.Base
+	// deltaFrom is an older bundle to create a delta bundle against
+	deltaFrom *DeltaBase
 }
 
 // Pusher is the interface for pushing bundles
@@ -24,19 +36,39 @@ type Pusher interface{}
 
 // Options are the options for creating a bundler
 type Options struct {
-	Bundle    *types.UDSBundle
-	Output    string
-	TmpDstDir string
-	SourceDir string
+	Bundle *types.UDSBundle
+	// Bundles are the per-architecture bundles to combine when creating a multi-arch bundle
+	Bundles []*types.UDSBundle
+	// Signatures are the signatures of each bundle's metadata keyed by the bundle's architecture
+	Signatures map[string][][]byte
+	// Provenance are the partial provenance statements of each bundle keyed by the bundle's architecture, the packages
+	// and subject are added as the bundle is built
+	Provenance map[string]*provenance.Statement
+	Output     string
+	TmpDstDir  string
+	SourceDir  string
+	// MaxPackageSizeMB is the size in megabytes above which bundle tarballs are split into parts, 0 disables splitting
+	MaxPackageSizeMB int
+	// Base is a previously created bundle to copy unchanged packages from instead of fetching them
+	Base *fetcher.Base
+	// DeltaFrom is an older bundle to create a delta bundle against, alongside the bundle tarball
+	DeltaFrom *DeltaBase
 }
 
 // NewBundler creates a new bundler
 func NewBundler(opts *Options) *Bundler {
 	b := Bundler{
-		bundle:    opts.Bundle,
-		output:    opts.Output,
-		tmpDstDir: opts.TmpDstDir,
-		sourceDir: opts.SourceDir,
+		bundle:     opts.Bundle,
+		bundles:    opts.Bundles,
+		signatures: opts.Signatures,
+		provenance: opts.Provenance,
+		output:     opts.Output,
+		tmpDstDir:  opts.TmpDstDir,
+		sourceDir:  opts.SourceDir,
+
+		maxPackageSizeMB: opts.MaxPackageSizeMB,
+		base:             opts.Base,
+		deltaFrom:        opts.DeltaFrom,
 	}
 	return &b
 }
@@ -45,16 +77,37 @@ func NewBundler(opts *Options) *Bundler {
 func (b *Bundler) Create(ctx context.Context) error {
 	if utils.IsRegistryURL(b.output) {
 		remoteBundle := NewRemoteBundle(&RemoteBundleOpts{Bundle: b.bundle, Output: b.output})
-		err := remoteBundle.create(ctx, nil)
+		err := remoteBundle.create(ctx, b.signatures[b.bundle.Metadata.Architecture], b.provenance[b.bundle.Metadata.Architecture])
 		if err != nil {
 			return err
 		}
 	} else {
-		localBundle := NewLocalBundle(&LocalBundleOpts{Bundle: b.bundle, TmpDstDir: b.tmpDstDir, SourceDir: b.sourceDir, OutputDir: b.output})
-		err := localBundle.create(ctx, nil)
+		localBundle := NewLocalBundle(&LocalBundleOpts{Bundle: b.bundle, TmpDstDir: b.tmpDstDir, SourceDir: b.sourceDir, OutputDir: b.output, MaxPackageSizeMB: b.maxPackageSizeMB, Base: b.base, DeltaFrom: b.deltaFrom})
+		err := localBundle.create(ctx, b.signatures[b.bundle.Metadata.Architecture], b.provenance[b.bundle.Metadata.Architecture])
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// CreateMultiArch creates a single bundle with a bundle root manifest for each of the per-architecture bundles
+func (b *Bundler) CreateMultiArch(ctx context.Context) error {
+	if len(b.bundles) == 0 {
+		return errors.New("no bundles provided for multi-arch bundling")
+	}
+
+	if utils.IsRegistryURL(b.output) {
+		// each root manifest is added to the remote's index under its arch
+		for _, bundle := range b.bundles {
+			remoteBundle := NewRemoteBundle(&RemoteBundleOpts{Bundle: bundle, Output: b.output})
+			if err := remoteBundle.create(ctx, b.signatures[bundle.Metadata.Architecture], b.provenance[bundle.Metadata.Architecture]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	localBundle := NewLocalBundle(&LocalBundleOpts{Bundle: b.bundles[0], TmpDstDir: b.tmpDstDir, SourceDir: b.sourceDir, OutputDir: b.output, MaxPackageSizeMB: b.maxPackageSizeMB, Base: b.base, DeltaFrom: b.deltaFrom})
+	return localBundle.createMultiArch(ctx, b.bundles, b.signatures, b.provenance)
+}
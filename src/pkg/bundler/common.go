@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
@@ -20,11 +21,18 @@ import (
 )
 
 // copied from: https://github.com/zarf-dev/zarf/blob/main/src/pkg/oci/push.go
-func manifestAnnotationsFromMetadata(metadata *types.UDSMetadata) map[string]string {
+func manifestAnnotationsFromMetadata(metadata *types.UDSMetadata, build *types.UDSBuildData) map[string]string {
 	annotations := map[string]string{
 		ocispec.AnnotationDescription: metadata.Description,
 	}
 
+	if version := metadata.Version; version != "" {
+		annotations[ocispec.AnnotationVersion] = version
+	}
+	if created, err := time.Parse(time.RFC1123Z, build.Timestamp); err == nil {
+		annotations[ocispec.AnnotationCreated] = created.UTC().Format(time.RFC3339)
+	}
+
 	if url := metadata.URL; url != "" {
 		annotations[ocispec.AnnotationURL] = url
 	}
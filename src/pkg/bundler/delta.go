@@ -0,0 +1,83 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundler defines behavior for bundling packages
+package bundler
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
+)
+
+// DeltaBase is the older bundle a delta bundle is created against
+type DeltaBase struct {
+	// Source is the path of the older bundle
+	Source string
+	// Blobs are the digests of the blobs in the older bundle
+	Blobs map[string]bool
+}
+
+// deltaFilename returns the filename of the delta bundle for a bundle tarball
+func deltaFilename(bundlePath string) string {
+	return config.BundleDeltaPrefix + strings.TrimPrefix(filepath.Base(bundlePath), config.BundlePrefix)
+}
+
+// writeDelta writes a delta bundle next to the bundle tarball at bundlePath. The delta bundle holds the bundle's
+// index.json, oci-layout and the blobs that the older bundle doesn't have, along with a delta.json recording the blobs
+// to read from the older bundle and the digest of the bundle tarball they rebuild
+func writeDelta(bundlePath string, artifactPathMap types.PathMap, base *DeltaBase, tmpDir string) error {
+	sha256Sum, err := helpers.GetSHA256OfFile(bundlePath)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return err
+	}
+	delta := types.BundleDelta{
+		Bundle:    filepath.Base(bundlePath),
+		Sha256Sum: sha256Sum,
+		Bytes:     info.Size(),
+		From:      filepath.Base(base.Source),
+		BaseBlobs: []string{},
+	}
+
+	deltaPathMap := make(types.PathMap)
+	var deltaBytes int64
+	for src, dst := range artifactPathMap {
+		if digest, ok := strings.CutPrefix(filepath.ToSlash(dst), config.BlobsDir+"/"); ok && base.Blobs[digest] {
+			delta.BaseBlobs = append(delta.BaseBlobs, digest)
+			continue
+		}
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		deltaBytes += info.Size()
+		deltaPathMap[src] = dst
+	}
+	slices.Sort(delta.BaseBlobs)
+
+	deltaJSON := filepath.Join(tmpDir, config.BundleDeltaJSON)
+	if err := utils.ToLocalFile(delta, deltaJSON); err != nil {
+		return err
+	}
+	deltaPathMap[deltaJSON] = config.BundleDeltaJSON
+
+	dst := filepath.Join(filepath.Dir(bundlePath), deltaFilename(bundlePath))
+	if err := utils.WriteBundleArchive(dst, deltaPathMap); err != nil {
+		return err
+	}
+	message.Infof("Created delta bundle %s holding %s of the bundle's %s, %d blobs are read from %s when it is applied",
+		dst, zarfUtils.ByteFormat(float64(deltaBytes), 2), zarfUtils.ByteFormat(float64(delta.Bytes), 2), len(delta.BaseBlobs), delta.From)
+	return nil
+}
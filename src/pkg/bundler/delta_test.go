@@ -0,0 +1,67 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundler defines behavior for bundling packages
+package bundler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/mholt/archives"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeDelta(t *testing.T) {
+	dir := t.TempDir()
+	pathMap := make(types.PathMap)
+	for name, contents := range map[string]string{
+		"index.json":             "{}",
+		"oci-layout":             "{}",
+		"blobs/sha256/unchanged": "unchanged blob",
+		"blobs/sha256/added":     "added blob",
+	} {
+		path := filepath.Join(dir, "src", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+		pathMap[path] = name
+	}
+	bundlePath := filepath.Join(dir, "uds-bundle-example-amd64-0.0.2.tar.zst")
+	require.NoError(t, utils.WriteBundleArchive(bundlePath, pathMap))
+
+	base := &DeltaBase{Source: "uds-bundle-example-amd64-0.0.1.tar.zst", Blobs: map[string]bool{"unchanged": true, "removed": true}}
+	require.NoError(t, writeDelta(bundlePath, pathMap, base, t.TempDir()))
+
+	deltaFile, err := os.Open(filepath.Join(dir, "uds-delta-example-amd64-0.0.2.tar.zst"))
+	require.NoError(t, err)
+	defer deltaFile.Close()
+	var names []string
+	var deltaJSON []byte
+	err = config.BundleArchiveFormat.Extract(context.Background(), deltaFile, func(ctx context.Context, file archives.FileInfo) error {
+		names = append(names, file.NameInArchive)
+		return utils.ExtractBytes(&deltaJSON, config.BundleDeltaJSON)(ctx, file)
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"blobs/sha256/added", config.BundleDeltaJSON, "index.json", "oci-layout"}, names)
+
+	var delta types.BundleDelta
+	require.NoError(t, json.Unmarshal(deltaJSON, &delta))
+	sum, err := helpers.GetSHA256OfFile(bundlePath)
+	require.NoError(t, err)
+	info, err := os.Stat(bundlePath)
+	require.NoError(t, err)
+	require.Equal(t, types.BundleDelta{
+		Bundle:    "uds-bundle-example-amd64-0.0.2.tar.zst",
+		Sha256Sum: sum,
+		Bytes:     info.Size(),
+		From:      "uds-bundle-example-amd64-0.0.1.tar.zst",
+		BaseBlobs: []string{"unchanged"},
+	}, delta)
+}
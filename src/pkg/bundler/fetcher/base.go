@@ -0,0 +1,130 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package fetcher contains functionality to fetch local and remote Zarf pkgs for local bundling
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Base is a previously created bundle whose unchanged packages are copied into a new bundle instead of fetched
+type Base struct {
+	// Storage holds the base bundle's blobs
+	Storage content.ReadOnlyStorage
+	// Packages maps the key of each of the base bundle's packages to the package's manifest, see PackageKey
+	Packages map[string]ocispec.Descriptor
+}
+
+// PackageKey identifies a package by the repository and digest it was fetched from and the optional components that
+// were bundled. Only remote packages have a key because a local package's manifest is generated from its tarball
+func PackageKey(pkg types.Package) (string, bool) {
+	if pkg.Repository == "" {
+		return "", false
+	}
+	_, digest, ok := strings.Cut(pkg.Ref, "@")
+	if !ok {
+		if !strings.HasPrefix(pkg.Ref, "sha256:") {
+			return "", false
+		}
+		digest = pkg.Ref
+	}
+	components := slices.Clone(pkg.OptionalComponents)
+	slices.Sort(components)
+	return fmt.Sprintf("%s@%s[%s]", pkg.Repository, digest, strings.Join(components, ",")), true
+}
+
+// Manifest returns the descriptor of the package's manifest in the base bundle, if the base bundle has the package
+func (b *Base) Manifest(pkg types.Package) (ocispec.Descriptor, bool) {
+	if b == nil {
+		return ocispec.Descriptor{}, false
+	}
+	key, ok := PackageKey(pkg)
+	if !ok {
+		return ocispec.Descriptor{}, false
+	}
+	desc, ok := b.Packages[key]
+	return desc, ok
+}
+
+// baseFetcher copies a package from a base bundle into a local bundle
+type baseFetcher struct {
+	pkg             types.Package
+	cfg             Config
+	pkgManifestDesc ocispec.Descriptor
+}
+
+// Fetch copies the package's manifest, config and bundled layers from the base bundle into the local bundle
+func (f *baseFetcher) Fetch() ([]ocispec.Descriptor, error) {
+	ctx := context.TODO()
+	copySpinner := newProgress(f.cfg, "Copying package %s from the base bundle", f.pkg.Name)
+	defer copySpinner.Stop()
+
+	pkgManifestBytes, err := content.FetchAll(ctx, f.cfg.Base.Storage, f.pkgManifestDesc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the manifest of package %s in the base bundle: %w", f.pkg.Name, err)
+	}
+	var pkgManifest ocispec.Manifest
+	if err := json.Unmarshal(pkgManifestBytes, &pkgManifest); err != nil {
+		return nil, err
+	}
+
+	var descs []ocispec.Descriptor
+	for _, desc := range append([]ocispec.Descriptor{pkgManifest.Config}, pkgManifest.Layers...) {
+		// only the layers of the components that were bundled are in the base bundle
+		exists, err := f.cfg.Base.Storage.Exists(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		if err := f.copyBlob(ctx, desc); err != nil {
+			return nil, err
+		}
+		descs = append(descs, desc)
+	}
+	if err := f.copyBlob(ctx, f.pkgManifestDesc); err != nil {
+		return nil, err
+	}
+	descs = append(descs, f.pkgManifestDesc)
+
+	// save pkg manifest to bundle root manifest
+	f.cfg.BundleRootManifest.Layers = append(f.cfg.BundleRootManifest.Layers, f.pkgManifestDesc)
+
+	copySpinner.Successf("Copied package %s from the base bundle", f.pkg.Name)
+	return descs, nil
+}
+
+// copyBlob copies a blob from the base bundle to the local bundle's store, which verifies its digest
+func (f *baseFetcher) copyBlob(ctx context.Context, desc ocispec.Descriptor) error {
+	if exists, err := f.cfg.Store.Exists(ctx, desc); err != nil || exists {
+		return err
+	}
+	rc, err := f.cfg.Base.Storage.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := f.cfg.Store.Push(ctx, desc, rc); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return fmt.Errorf("unable to copy %s of package %s from the base bundle: %w", desc.Digest, f.pkg.Name, err)
+	}
+	return nil
+}
+
+// GetPkgMetadata is not used for packages copied from a base bundle, their metadata is read from the package's source
+// when the bundle is validated
+func (f *baseFetcher) GetPkgMetadata() (v1alpha1.ZarfPackage, error) {
+	return v1alpha1.ZarfPackage{}, errors.New("package metadata is not read from the base bundle")
+}
@@ -17,10 +17,35 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/layout"
+	"github.com/zarf-dev/zarf/src/pkg/message"
 	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
 	zarfSources "github.com/zarf-dev/zarf/src/pkg/packager/sources"
 )
 
+// progress is how a fetcher reports its progress
+type progress interface {
+	Updatef(format string, a ...any)
+	Successf(format string, a ...any)
+	Stop()
+}
+
+// quietProgress only logs a fetcher's progress at debug level
+type quietProgress struct{}
+
+func (quietProgress) Updatef(format string, a ...any)  { message.Debugf(format, a...) }
+func (quietProgress) Successf(format string, a ...any) { message.Debugf(format, a...) }
+func (quietProgress) Stop()                            {}
+
+// newProgress starts a spinner to report a fetcher's progress, the spinner is skipped for quiet fetchers because
+// spinners can't be shown for more than one package at a time
+func newProgress(cfg Config, format string, a ...any) progress {
+	if cfg.Quiet {
+		message.Debugf(format, a...)
+		return quietProgress{}
+	}
+	return message.NewProgressSpinner(format, a...)
+}
+
 // loadPkg loads a package from a tarball source and filters out optional components
 func loadPkg(pkgTmp string, pkgSrc zarfSources.PackageSource, optionalComponents []string) (v1alpha1.ZarfPackage, *layout.PackagePaths, error) {
 	// create empty layout and source
@@ -5,11 +5,11 @@
 package fetcher
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 
 	"github.com/defenseunicorns/pkg/oci"
-	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -32,15 +32,30 @@ type Config struct {
 	NumPkgs            int
 	BundleRootManifest *ocispec.Manifest
 	Bundle             *types.UDSBundle
+	// Arch is the architecture of the package to fetch
+	Arch string
+	// Quiet disables the fetcher's spinners and progress bars, for when packages are fetched concurrently
+	Quiet bool
+	// Concurrency is the number of layers copied at a time when packages are fetched concurrently
+	Concurrency int
+	// Base is a previously created bundle to copy the package from when it is unchanged
+	Base *Base
 }
 
 // NewPkgFetcher creates a fetcher object to pull Zarf pkgs into a local bundle
 func NewPkgFetcher(pkg types.Package, fetcherConfig Config) (Fetcher, error) {
 	var fetcher Fetcher
 	ctx := context.TODO()
+	if pkgManifestDesc, ok := fetcherConfig.Base.Manifest(pkg); ok {
+		return &baseFetcher{
+			pkg:             pkg,
+			cfg:             fetcherConfig,
+			pkgManifestDesc: pkgManifestDesc,
+		}, nil
+	}
 	if utils.IsRemotePkg(pkg) {
 		platform := ocispec.Platform{
-			Architecture: config.GetArch(),
+			Architecture: cmp.Or(pkg.Architecture, fetcherConfig.Arch),
 			OS:           oci.MultiOS,
 		}
 		url := fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
@@ -7,6 +7,7 @@ package fetcher
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -20,13 +21,13 @@ import (
 	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
-	"github.com/zarf-dev/zarf/src/pkg/message"
 	zarfSources "github.com/zarf-dev/zarf/src/pkg/packager/sources"
 	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 	zarfTypes "github.com/zarf-dev/zarf/src/types"
 	"oras.land/oras-go/v2/content/file"
 	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
 )
 
 type localFetcher struct {
@@ -37,7 +38,7 @@ type localFetcher struct {
 
 // Fetch fetches a local Zarf pkg and puts it into a local bundle
 func (f *localFetcher) Fetch() ([]ocispec.Descriptor, error) {
-	fetchSpinner := message.NewProgressSpinner("Fetching package %s", f.pkg.Name)
+	fetchSpinner := newProgress(f.cfg, "Fetching package %s", f.pkg.Name)
 	defer fetchSpinner.Stop()
 	pkgTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
 	defer os.RemoveAll(pkgTmp)
@@ -191,7 +192,8 @@ func (f *localFetcher) toBundle(pkgTmp string) ([]ocispec.Descriptor, error) {
 
 		// push if layer to bundle store if it doesn't already exist
 		if exists, err := f.cfg.Store.Exists(ctx, desc); !exists && err == nil {
-			if err := f.cfg.Store.Push(ctx, desc, layer); err != nil {
+			// another package being fetched at the same time may have pushed the layer since it was checked
+			if err := f.cfg.Store.Push(ctx, desc, layer); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
 				return nil, err
 			}
 		}
@@ -5,6 +5,7 @@
 package fetcher
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"path/filepath"
@@ -17,9 +18,9 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
-	"github.com/zarf-dev/zarf/src/pkg/message"
 	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2"
 )
 
 // remoteFetcher fetches remote Zarf pkgs for local bundles
@@ -32,7 +33,7 @@ type remoteFetcher struct {
 
 // Fetch fetches a Zarf pkg and puts it into a local bundle
 func (f *remoteFetcher) Fetch() ([]ocispec.Descriptor, error) {
-	fetchSpinner := message.NewProgressSpinner("Fetching package %s", f.pkg.Name)
+	fetchSpinner := newProgress(f.cfg, "Fetching package %s", f.pkg.Name)
 	defer fetchSpinner.Stop()
 
 	// find layers in remote
@@ -82,7 +83,15 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 	}
 	// pull layers that didn't already exist on disk
 	if len(layersToPull) > 0 {
-		rootPkgDesc, err := boci.CopyLayers(layersToPull, estimatedBytes, f.cfg.TmpDstDir, f.remote.Repo(), f.cfg.Store, f.pkg.Name)
+		var rootPkgDesc ocispec.Descriptor
+		var err error
+		if f.cfg.Quiet {
+			// the progress bar is based on the size of the whole bundle dir, so it can't be shown for concurrent fetches
+			copyOpts := boci.WithCache(boci.CreateCopyOpts(layersToPull, f.cfg.Concurrency), f.cfg.Store)
+			rootPkgDesc, err = oras.Copy(ctx, f.remote.Repo(), f.remote.Repo().Reference.String(), f.cfg.Store, "", copyOpts)
+		} else {
+			rootPkgDesc, err = boci.CopyLayers(layersToPull, estimatedBytes, f.cfg.TmpDstDir, f.remote.Repo(), f.cfg.Store, f.pkg.Name)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -91,12 +100,6 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 		descsToBundle = append(descsToBundle, rootPkgDesc)
 		rootPkgDesc.MediaType = zoci.ZarfLayerMediaTypeBlob // force media type to Zarf blob
 		f.cfg.BundleRootManifest.Layers = append(f.cfg.BundleRootManifest.Layers, rootPkgDesc)
-
-		// cache only the image layers that were just pulled
-		err = cache.AddPulledImgLayers(layersToPull, f.cfg.TmpDstDir)
-		if err != nil {
-			return nil, err
-		}
 	} else {
 		// no layers to pull but need to grab pkg root manifest and config manually bc we didn't use oras.Copy()
 		pkgManifestDesc, err := boci.ToOCIStore(f.pkgRootManifest, ocispec.MediaTypeImageManifest, f.cfg.Store)
@@ -120,7 +123,7 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 func (f *remoteFetcher) GetPkgMetadata() (v1alpha1.ZarfPackage, error) {
 	ctx := context.TODO()
 	platform := ocispec.Platform{
-		Architecture: config.GetArch(),
+		Architecture: cmp.Or(f.pkg.Architecture, f.cfg.Arch),
 		OS:           oci.MultiOS,
 	}
 
@@ -10,8 +10,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
@@ -20,10 +23,12 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
 	goyaml "github.com/goccy/go-yaml"
-	"github.com/mholt/archives"
+	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/pkg/message"
+	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2/content"
@@ -36,6 +41,12 @@ type LocalBundleOpts struct {
 	TmpDstDir string
 	SourceDir string
 	OutputDir string
+	// MaxPackageSizeMB is the size in megabytes above which the bundle tarball is split into parts, 0 disables splitting
+	MaxPackageSizeMB int
+	// Base is a previously created bundle to copy unchanged packages from instead of fetching them
+	Base *fetcher.Base
+	// DeltaFrom is an older bundle to create a delta bundle against, nil skips creating a delta bundle
+	DeltaFrom *DeltaBase
 }
 
 // LocalBundle enables create ops with local bundles
@@ -44,6 +55,10 @@ type LocalBundle struct {
 	tmpDstDir string
 	sourceDir string
 	outputDir string
+
+	maxPackageSizeMB int
+	base             *fetcher.Base
+	deltaFrom        *DeltaBase
 }
 
 // NewLocalBundle creates a new local bundle
@@ -53,64 +68,143 @@ func NewLocalBundle(opts *LocalBundleOpts) *LocalBundle {
 		tmpDstDir: opts.TmpDstDir,
 		sourceDir: opts.SourceDir,
 		outputDir: opts.OutputDir,
+
+		maxPackageSizeMB: opts.MaxPackageSizeMB,
+		base:             opts.Base,
+		deltaFrom:        opts.DeltaFrom,
 	}
 }
 
 // create creates the bundle and outputs to a local tarball
-func (lo *LocalBundle) create(ctx context.Context, signature []byte) error {
+func (lo *LocalBundle) create(ctx context.Context, signatures [][]byte, statement *provenance.Statement) error {
 	bundle := lo.bundle
-	if bundle.Metadata.Architecture == "" {
-		return errors.New("architecture is required for bundling")
+	store, err := ocistore.NewWithContext(ctx, lo.tmpDstDir)
+	if err != nil {
+		return err
 	}
-	store, err := ocistore.NewWithContext(context.TODO(), lo.tmpDstDir)
 
-	message.HeaderInfof("🐕 Fetching Packages")
-
-	// create root manifest for bundle, will populate with ref to uds-bundle.yaml and zarf image manifests
-	rootManifest := ocispec.Manifest{
-		MediaType: ocispec.MediaTypeImageManifest,
+	rootManifestDesc, artifactPathMap, err := lo.build(ctx, store, bundle, signatures, statement)
+	if err != nil {
+		return err
 	}
 
-	fetcherConfig := fetcher.Config{
-		Bundle:             bundle,
-		Store:              store,
-		TmpDstDir:          lo.tmpDstDir,
-		NumPkgs:            len(lo.bundle.Packages),
-		BundleRootManifest: &rootManifest,
+	// tag the local bundle artifact
+	// todo: no need to tag the local artifact
+	err = store.Tag(ctx, rootManifestDesc, bundle.Metadata.Version)
+	if err != nil {
+		return err
+	}
+	// ensure the bundle root manifest is the only manifest in the index.json
+	err = cleanIndexJSON(lo.tmpDstDir, rootManifestDesc)
+	if err != nil {
+		return err
 	}
 
-	message.Debug("Bundling", bundle.Metadata.Name, "to", lo.tmpDstDir)
+	return lo.writeBundle(bundle, artifactPathMap)
+}
+
+// createMultiArch creates a single local bundle tarball with a bundle root manifest for each of the per-architecture bundles
+func (lo *LocalBundle) createMultiArch(ctx context.Context, bundles []*types.UDSBundle, signatures map[string][][]byte, statements map[string]*provenance.Statement) error {
+	store, err := ocistore.NewWithContext(ctx, lo.tmpDstDir)
 	if err != nil {
 		return err
 	}
 
+	// arch-independent layers are shared between the root manifests in the store
 	artifactPathMap := make(types.PathMap)
+	var rootManifestDescs []ocispec.Descriptor
+	for _, bundle := range bundles {
+		message.HeaderInfof("🏗️  Bundling %s", bundle.Metadata.Architecture)
 
-	// grab all Zarf pkgs from OCI and put blobs in OCI store
-	for i, pkg := range bundle.Packages {
-		fetcherConfig.PkgIter = i
-		pkgFetcher, err := fetcher.NewPkgFetcher(pkg, fetcherConfig)
+		rootManifestDesc, archPathMap, err := lo.build(ctx, store, bundle, signatures[bundle.Metadata.Architecture], statements[bundle.Metadata.Architecture])
 		if err != nil {
 			return err
 		}
-		pkgDescs, err := pkgFetcher.Fetch()
-		if err != nil {
-			return err
+		maps.Copy(artifactPathMap, archPathMap)
+		rootManifestDesc.Platform = &ocispec.Platform{
+			Architecture: bundle.Metadata.Architecture,
+			OS:           oci.MultiOS,
+		}
+		rootManifestDescs = append(rootManifestDescs, rootManifestDesc)
+	}
+
+	// the index.json holds one bundle root manifest per arch
+	if err := writeIndexJSON(lo.tmpDstDir, rootManifestDescs); err != nil {
+		return err
+	}
+
+	// name the tarball after all of the archs it contains
+	multiArchBundle := *bundles[0]
+	multiArchBundle.Metadata.Architecture = config.MultiArch
+	return lo.writeBundle(&multiArchBundle, artifactPathMap)
+}
+
+// writeBundle tarballs the bundle, writes a delta bundle alongside it when creating one, and splits the tarball into
+// parts when it is larger than maxPackageSizeMB
+func (lo *LocalBundle) writeBundle(bundle *types.UDSBundle, artifactPathMap types.PathMap) error {
+	if lo.outputDir == "" {
+		lo.outputDir = lo.sourceDir
+	}
+	artifactPathMap = dedupeArchivePaths(artifactPathMap)
+	dst, err := writeTarball(bundle, artifactPathMap, lo.outputDir)
+	if err != nil {
+		return err
+	}
+	if lo.deltaFrom != nil {
+		if err := writeDelta(dst, artifactPathMap, lo.deltaFrom, lo.tmpDstDir); err != nil {
+			return fmt.Errorf("unable to create delta bundle: %w", err)
 		}
+	}
+	return utils.SplitBundleTarball(dst, lo.maxPackageSizeMB)
+}
+
+// build fetches the bundle's packages into the OCI store and creates the bundle's root manifest, returning the root
+// manifest's descriptor and the paths to include in the bundle tarball
+func (lo *LocalBundle) build(ctx context.Context, store *ocistore.Store, bundle *types.UDSBundle, signatures [][]byte, statement *provenance.Statement) (ocispec.Descriptor, types.PathMap, error) {
+	if bundle.Metadata.Architecture == "" {
+		return ocispec.Descriptor{}, nil, errors.New("architecture is required for bundling")
+	}
+
+	message.HeaderInfof("🐕 Fetching Packages")
+
+	// create root manifest for bundle, will populate with ref to uds-bundle.yaml and zarf image manifests
+	rootManifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+	}
+
+	message.Debug("Bundling", bundle.Metadata.Name, "to", lo.tmpDstDir)
+
+	// grab all Zarf pkgs from OCI and put blobs in OCI store
+	pkgManifests, pkgDescs, err := lo.fetchPackages(ctx, store, bundle)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+
+	artifactPathMap := make(types.PathMap)
+	pkgLayers := make([]boci.PackageLayers, 0, len(bundle.Packages))
+	for i, pkg := range bundle.Packages {
+		// the package manifests are the first layers of the root manifest, in the same order as the packages
+		rootManifest.Layers = append(rootManifest.Layers, pkgManifests[i])
+		pkgLayers = append(pkgLayers, boci.PackageLayers{Name: pkg.Name, Layers: pkgDescs[i]})
 
 		// add to artifactPathMap for local bundle tarball
-		for _, layer := range pkgDescs {
+		for _, layer := range pkgDescs[i] {
 			digest := layer.Digest.Encoded()
 			artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
 		}
 	}
 
+	if lo.base != nil {
+		printBaseReuse(bundle, lo.base)
+	}
+	printLayerSharing(pkgLayers)
+
 	message.HeaderInfof("🚧 Building Bundle")
 
 	// push uds-bundle.yaml to OCI store
 	bundleYAMLDesc, err := pushBundleYAMLToStore(store, bundle)
 	if err != nil {
-		return err
+		return ocispec.Descriptor{}, nil, err
 	}
 
 	// append uds-bundle.yaml layer to rootManifest and grab path for archiving
@@ -118,20 +212,53 @@ func (lo *LocalBundle) create(ctx context.Context, signature []byte) error {
 	digest := bundleYAMLDesc.Digest.Encoded()
 	artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
 
+	// push the bundle's provenance, the bundle's package manifests are the first layers of the root manifest
+	if statement != nil {
+		provenanceBytes, err := completeProvenance(*statement, bundle, rootManifest.Layers[:len(bundle.Packages)], bundleYAMLDesc)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		provenanceDesc, err := pushBundleMetadataFile(store, provenanceBytes, config.BundleProvenance)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		rootManifest.Layers = append(rootManifest.Layers, provenanceDesc)
+		digest := provenanceDesc.Digest.Encoded()
+		artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
+		message.Debug("Pushed", config.BundleProvenance)
+	}
+
+	// push the bundle's signatures, must happen before the root manifest is pushed
+	for i, signature := range signatures {
+		signatureName := config.BundleSignatureName(i)
+		signatureDesc, err := pushBundleMetadataFile(store, signature, signatureName)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		rootManifest.Layers = append(rootManifest.Layers, signatureDesc)
+		digest := signatureDesc.Digest.Encoded()
+		artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
+		jsonValue, err := utils.JSONValue(signatureDesc)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		message.Debug("Pushed", signatureName+":", jsonValue)
+	}
+
 	// create and push bundle manifest config
 	manifestConfigDesc, err := pushManifestConfig(store, bundle.Metadata, bundle.Build)
 	if err != nil {
-		return err
+		return ocispec.Descriptor{}, nil, err
 	}
 	manifestConfigDigest := manifestConfigDesc.Digest.Encoded()
 	artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, manifestConfigDigest)] = filepath.Join(config.BlobsDir, manifestConfigDigest)
 
 	rootManifest.Config = manifestConfigDesc
 	rootManifest.SchemaVersion = 2
-	rootManifest.Annotations = manifestAnnotationsFromMetadata(&bundle.Metadata) // maps to registry UI
+	rootManifest.Annotations = manifestAnnotationsFromMetadata(&bundle.Metadata, &bundle.Build) // maps to registry UI
 	rootManifestDesc, err := boci.ToOCIStore(rootManifest, ocispec.MediaTypeImageManifest, store)
 	if err != nil {
-		return err
+		return ocispec.Descriptor{}, nil, err
 	}
 	digest = rootManifestDesc.Digest.Encoded()
 	artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
@@ -142,42 +269,7 @@ func (lo *LocalBundle) create(ctx context.Context, signature []byte) error {
 	// grab oci-layout
 	artifactPathMap[filepath.Join(lo.tmpDstDir, "oci-layout")] = "oci-layout"
 
-	// push the bundle's signature todo: need to understand functionality and add tests
-	if len(signature) > 0 {
-		signatureDesc, err := pushBundleSignature(store, signature)
-		if err != nil {
-			return err
-		}
-		rootManifest.Layers = append(rootManifest.Layers, signatureDesc)
-		jsonValue, err := utils.JSONValue(signatureDesc)
-		if err != nil {
-			return err
-		}
-		message.Debug("Pushed", config.BundleYAMLSignature+":", jsonValue)
-	}
-
-	// tag the local bundle artifact
-	// todo: no need to tag the local artifact
-	err = store.Tag(ctx, rootManifestDesc, bundle.Metadata.Version)
-	if err != nil {
-		return err
-	}
-	// ensure the bundle root manifest is the only manifest in the index.json
-	err = cleanIndexJSON(lo.tmpDstDir, rootManifestDesc)
-	if err != nil {
-		return err
-	}
-
-	if lo.outputDir == "" {
-		lo.outputDir = lo.sourceDir
-	}
-	// tarball the bundle
-	err = writeTarball(bundle, artifactPathMap, lo.outputDir)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return rootManifestDesc, artifactPathMap, nil
 }
 
 // pushBundleYAMLToStore pushes the uds-bundle.yaml to a provided OCI store
@@ -223,87 +315,177 @@ func pushManifestConfig(store *ocistore.Store, metadata types.UDSMetadata, build
 	return manifestConfigDesc, err
 }
 
-// writeTarball builds and writes a bundle tarball to disk based on a file map
-func writeTarball(bundle *types.UDSBundle, artifactPathMap types.PathMap, outputDir string) error {
-	filename := fmt.Sprintf("%s%s-%s-%s.tar.zst", config.BundlePrefix, bundle.Metadata.Name, bundle.Metadata.Architecture, bundle.Metadata.Version)
-
-	if !helpers.IsDir(outputDir) {
-		err := os.MkdirAll(outputDir, 0o755)
-		if err != nil {
-			return err
-		}
+// fetchConcurrency splits --oci-concurrency between the packages fetched at a time and the layers each of them copies
+// at a time, so that no more than concurrency layers are copied at once. A concurrency of 0 leaves both unlimited
+func fetchConcurrency(concurrency, numPkgs int) (int, int) {
+	if concurrency <= 0 || numPkgs == 0 {
+		return concurrency, concurrency
 	}
+	pkgConcurrency := min(concurrency, numPkgs)
+	return pkgConcurrency, max(1, concurrency/pkgConcurrency)
+}
 
-	dst := filepath.Join(outputDir, filename)
+// fetchPackages fetches the bundle's packages into the OCI store, with up to --oci-concurrency layers copied at a time.
+// It returns each package's manifest descriptor and the descriptors of its layers, in the same order as the bundle's
+// packages
+func (lo *LocalBundle) fetchPackages(ctx context.Context, store *ocistore.Store, bundle *types.UDSBundle) ([]ocispec.Descriptor, [][]ocispec.Descriptor, error) {
+	numPkgs := len(bundle.Packages)
+	concurrency, layerConcurrency := fetchConcurrency(config.CommonOptions.OCIConcurrency, numPkgs)
+	// fetchers display their own progress when packages are fetched one at a time, otherwise progress is combined
+	concurrent := concurrency != 1 && numPkgs > 1
 
-	_ = os.RemoveAll(dst)
+	// each fetcher adds its package manifest to its own manifest so that the order doesn't depend on which finishes first
+	pkgRootManifests := make([]ocispec.Manifest, numPkgs)
+	pkgDescs := make([][]ocispec.Descriptor, numPkgs)
 
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-	files, err := archives.FilesFromDisk(context.TODO(), nil, artifactPathMap)
-	if err != nil {
-		return err
+	var fetchBar *message.ProgressBar
+	var fetchBarMu sync.Mutex
+	if concurrent {
+		fetchBar = message.NewProgressBar(int64(numPkgs), fmt.Sprintf("Fetching %d packages", numPkgs))
+		defer fetchBar.Close()
 	}
 
-	archiveErrorChan := make(chan error, len(files))
-	jobs := make(chan archives.ArchiveAsyncJob, len(files))
+	g, ctx := errgroup.WithContext(ctx)
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+	for i, pkg := range bundle.Packages {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			pkgFetcher, err := fetcher.NewPkgFetcher(pkg, fetcher.Config{
+				Bundle:             bundle,
+				Arch:               bundle.Metadata.Architecture,
+				Store:              store,
+				TmpDstDir:          lo.tmpDstDir,
+				PkgIter:            i,
+				NumPkgs:            numPkgs,
+				BundleRootManifest: &pkgRootManifests[i],
+				Quiet:              concurrent,
+				Concurrency:        layerConcurrency,
+				Base:               lo.base,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to fetch package %s: %w", pkg.Name, err)
+			}
+			descs, err := pkgFetcher.Fetch()
+			if err != nil {
+				return fmt.Errorf("unable to fetch package %s: %w", pkg.Name, err)
+			}
+			pkgDescs[i] = descs
+			if concurrent {
+				fetchBarMu.Lock()
+				defer fetchBarMu.Unlock()
+				fetchBar.Add(1)
+				fetchBar.Updatef("Fetched package %s (%d of %d)", pkg.Name, fetchBar.GetCurrent(), numPkgs)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	if concurrent {
+		fetchBar.Successf("Fetched %d packages", numPkgs)
+	}
 
-	for _, file := range files {
-		archiveJob := archives.ArchiveAsyncJob{
-			File:   file,
-			Result: archiveErrorChan,
+	pkgManifests := make([]ocispec.Descriptor, 0, numPkgs)
+	for i, pkgRootManifest := range pkgRootManifests {
+		if len(pkgRootManifest.Layers) != 1 {
+			return nil, nil, fmt.Errorf("expected 1 package manifest for package %s, got %d", bundle.Packages[i].Name, len(pkgRootManifest.Layers))
 		}
-		jobs <- archiveJob
+		pkgManifests = append(pkgManifests, pkgRootManifest.Layers[0])
 	}
+	return pkgManifests, pkgDescs, nil
+}
 
-	close(jobs)
-
-	archiveErrGroup, ctx := errgroup.WithContext(context.TODO())
-
-	archiveBar := message.NewProgressBar(int64(len(jobs)), "Creating bundle archive")
-
-	defer archiveBar.Close()
+// printBaseReuse reports which of the bundle's packages were copied from the base bundle and why the others were fetched
+func printBaseReuse(bundle *types.UDSBundle, base *fetcher.Base) {
+	rows := make([][]string, 0, len(bundle.Packages))
+	reused := 0
+	for _, pkg := range bundle.Packages {
+		status := "reused from the base bundle"
+		if _, ok := base.Manifest(pkg); ok {
+			reused++
+		} else if _, ok := fetcher.PackageKey(pkg); !ok {
+			status = "fetched, local packages are always rebuilt"
+		} else {
+			status = "fetched, changed since the base bundle"
+		}
+		rows = append(rows, []string{pkg.Name, status})
+	}
+	message.Table([]string{"Package", "Status"}, rows)
+	message.Infof("Reused %d of %d packages from the base bundle", reused, len(bundle.Packages))
+}
 
-	archiveErrGroup.Go(func() error {
-		return config.BundleArchiveFormat.ArchiveAsync(ctx, out, jobs)
-	})
+// printLayerSharing reports how many of each package's bytes are shared with other packages in the bundle, shared
+// layers are only stored in the bundle once
+func printLayerSharing(pkgLayers []boci.PackageLayers) {
+	pkgSizes, total := boci.PackageSizes(pkgLayers)
+	rows := make([][]string, 0, len(pkgSizes))
+	var sum int64
+	for _, pkgSize := range pkgSizes {
+		sum += pkgSize.Size
+		rows = append(rows, []string{
+			pkgSize.Name,
+			zarfUtils.ByteFormat(float64(pkgSize.Size), 2),
+			zarfUtils.ByteFormat(float64(pkgSize.Unique), 2),
+			zarfUtils.ByteFormat(float64(pkgSize.Shared), 2),
+		})
+	}
+	message.Table([]string{"Package", "Size", "Unique", "Shared"}, rows)
+	if sum > total {
+		message.Infof("Packages share %s of layers, which are only stored in the bundle once", zarfUtils.ByteFormat(float64(sum-total), 2))
+	}
+}
 
-jobLoop:
-	for len(jobs) != 0 {
-		select {
-		case err := <-archiveErrorChan:
-			if err != nil {
-				return err
-			} else {
-				archiveBar.Add(1)
-			}
-		case <-ctx.Done():
-			break jobLoop
+// dedupeArchivePaths ensures each path in the bundle tarball is only archived once, such as a blob shared between
+// packages or architectures, by keeping the first of the sorted source paths for each archive path
+func dedupeArchivePaths(artifactPathMap types.PathMap) types.PathMap {
+	srcs := slices.Sorted(maps.Keys(artifactPathMap))
+	archived := make(map[string]bool, len(srcs))
+	deduped := make(types.PathMap, len(srcs))
+	for _, src := range srcs {
+		dst := artifactPathMap[src]
+		if archived[dst] {
+			message.Debugf("%s is already in the bundle archive, skipping %s", dst, src)
+			continue
 		}
+		archived[dst] = true
+		deduped[src] = dst
 	}
+	return deduped
+}
 
-	if err := archiveErrGroup.Wait(); err != nil {
-		return err
+// writeTarball builds and writes a bundle tarball to disk based on a file map, returning the path of the tarball
+func writeTarball(bundle *types.UDSBundle, artifactPathMap types.PathMap, outputDir string) (string, error) {
+	filename := fmt.Sprintf("%s%s-%s-%s.tar.zst", config.BundlePrefix, bundle.Metadata.Name, bundle.Metadata.Architecture, bundle.Metadata.Version)
+
+	if !helpers.IsDir(outputDir) {
+		err := os.MkdirAll(outputDir, 0o755)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	archiveBar.Successf("Created bundle archive at: %s", dst)
-	return nil
+	dst := filepath.Join(outputDir, filename)
+	return dst, utils.WriteBundleArchive(dst, artifactPathMap)
 }
 
-func pushBundleSignature(store *ocistore.Store, signature []byte) (ocispec.Descriptor, error) {
+// pushBundleMetadataFile pushes one of the bundle's metadata files, such as a signature or the provenance statement, to
+// the OCI store
+func pushBundleMetadataFile(store *ocistore.Store, fileBytes []byte, title string) (ocispec.Descriptor, error) {
 	ctx := context.TODO()
-	signatureDesc := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, signature)
-	err := store.Push(ctx, signatureDesc, bytes.NewReader(signature))
+	fileDesc := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, fileBytes)
+	err := store.Push(ctx, fileDesc, bytes.NewReader(fileBytes))
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
-	signatureDesc.Annotations = map[string]string{
-		ocispec.AnnotationTitle: config.BundleYAMLSignature,
+	fileDesc.Annotations = map[string]string{
+		ocispec.AnnotationTitle: title,
 	}
-	return signatureDesc, err
+	return fileDesc, err
 }
 
 // rebuild index.json because copying remote Zarf pkgs adds unnecessary entries
@@ -331,3 +513,13 @@ func cleanIndexJSON(tmpDir string, bundleRootDesc ocispec.Descriptor) error {
 	}
 	return nil
 }
+
+// writeIndexJSON overwrites the index.json so that it only references the given bundle root manifests
+func writeIndexJSON(tmpDir string, rootManifestDescs []ocispec.Descriptor) error {
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: rootManifestDescs,
+	}
+	return utils.ToLocalFile(index, filepath.Join(tmpDir, "index.json"))
+}
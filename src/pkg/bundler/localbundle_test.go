@@ -0,0 +1,52 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundler defines behavior for bundling packages
+package bundler
+
+import (
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dedupeArchivePaths(t *testing.T) {
+	// the same blob fetched into the store for two packages is archived once
+	pathMap := types.PathMap{
+		"/tmp/b/blobs/sha256/abc": "blobs/sha256/abc",
+		"/tmp/a/blobs/sha256/abc": "blobs/sha256/abc",
+		"/tmp/a/blobs/sha256/def": "blobs/sha256/def",
+	}
+	require.Equal(t, types.PathMap{
+		"/tmp/a/blobs/sha256/abc": "blobs/sha256/abc",
+		"/tmp/a/blobs/sha256/def": "blobs/sha256/def",
+	}, dedupeArchivePaths(pathMap))
+}
+
+func Test_fetchConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		numPkgs     int
+		wantPkgs    int
+		wantLayers  int
+	}{
+		{name: "fewer packages than layers", concurrency: 6, numPkgs: 2, wantPkgs: 2, wantLayers: 3},
+		{name: "more packages than layers", concurrency: 3, numPkgs: 5, wantPkgs: 3, wantLayers: 1},
+		{name: "uneven split", concurrency: 7, numPkgs: 3, wantPkgs: 3, wantLayers: 2},
+		{name: "single package", concurrency: 3, numPkgs: 1, wantPkgs: 1, wantLayers: 3},
+		{name: "sequential", concurrency: 1, numPkgs: 4, wantPkgs: 1, wantLayers: 1},
+		{name: "unlimited", concurrency: 0, numPkgs: 4, wantPkgs: 0, wantLayers: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgs, layers := fetchConcurrency(tt.concurrency, tt.numPkgs)
+			require.Equal(t, tt.wantPkgs, pkgs)
+			require.Equal(t, tt.wantLayers, layers)
+			if tt.concurrency > 0 {
+				require.LessOrEqual(t, pkgs*layers, tt.concurrency)
+			}
+		})
+	}
+}
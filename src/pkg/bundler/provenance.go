@@ -0,0 +1,58 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundler defines behavior for bundling packages
+package bundler
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// completeProvenance adds each of the bundle's packages and the bundle's metadata to its provenance statement,
+// pkgManifestDescs are the descriptors of the package manifests in the bundle in the same order as the bundle's packages
+func completeProvenance(statement provenance.Statement, bundle *types.UDSBundle, pkgManifestDescs []ocispec.Descriptor, bundleYAMLDesc ocispec.Descriptor) ([]byte, error) {
+	if len(pkgManifestDescs) != len(bundle.Packages) {
+		return nil, fmt.Errorf("expected %d package manifests for the bundle's provenance, found %d", len(bundle.Packages), len(pkgManifestDescs))
+	}
+
+	// the root manifest can't be the subject because the statement is one of its layers, the bundle's metadata is
+	// the closest content-addressed stand-in and published bundles attach the statement to the root manifest
+	statement.Subject = []provenance.ResourceDescriptor{
+		{
+			Name:   config.BundleYAML,
+			Digest: map[string]string{"sha256": bundleYAMLDesc.Digest.Encoded()},
+		},
+	}
+
+	dependencies := slices.Clone(statement.Predicate.BuildDefinition.ResolvedDependencies)
+	for i, pkg := range bundle.Packages {
+		dependency := provenance.ResourceDescriptor{
+			Name:   pkg.Name,
+			Digest: map[string]string{"sha256": pkgManifestDescs[i].Digest.Encoded()},
+			Annotations: map[string]string{
+				"ref": pkg.Ref,
+			},
+		}
+		if utils.IsRemotePkg(pkg) {
+			dependency.URI = boci.EnsureOCIPrefix(fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref))
+		} else {
+			dependency.URI = pkg.Path
+		}
+		if pkg.Flavor != "" {
+			dependency.Annotations["flavor"] = pkg.Flavor
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	statement.Predicate.BuildDefinition.ResolvedDependencies = dependencies
+
+	return json.Marshal(statement)
+}
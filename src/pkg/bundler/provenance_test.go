@@ -0,0 +1,65 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package bundler defines behavior for bundling packages
+package bundler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+)
+
+func Test_completeProvenance(t *testing.T) {
+	bundle := &types.UDSBundle{
+		Packages: []types.Package{
+			{Name: "remote", Repository: "ghcr.io/defenseunicorns/packages/remote", Ref: "0.1.0", Flavor: "upstream"},
+			{Name: "local", Path: "../packages", Ref: "0.0.1"},
+		},
+	}
+	pkgManifestDescs := []ocispec.Descriptor{
+		content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("remote")),
+		content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("local")),
+	}
+	bundleYAMLDesc := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("kind: UDSBundle"))
+
+	sourceDependencies := make([]provenance.ResourceDescriptor, 1, 4)
+	sourceDependencies[0] = provenance.ResourceDescriptor{Name: config.BundleYAML}
+	statement := provenance.Statement{
+		Type: provenance.StatementType,
+		Predicate: provenance.Predicate{
+			BuildDefinition: provenance.BuildDefinition{ResolvedDependencies: sourceDependencies},
+		},
+	}
+
+	b, err := completeProvenance(statement, bundle, pkgManifestDescs, bundleYAMLDesc)
+	require.NoError(t, err)
+	var completed provenance.Statement
+	require.NoError(t, json.Unmarshal(b, &completed))
+
+	require.Equal(t, []provenance.ResourceDescriptor{{Name: config.BundleYAML, Digest: map[string]string{"sha256": bundleYAMLDesc.Digest.Encoded()}}}, completed.Subject)
+	dependencies := completed.Predicate.BuildDefinition.ResolvedDependencies
+	require.Len(t, dependencies, 3)
+	require.Equal(t, provenance.ResourceDescriptor{
+		Name:        "remote",
+		URI:         "oci://ghcr.io/defenseunicorns/packages/remote:0.1.0",
+		Digest:      map[string]string{"sha256": pkgManifestDescs[0].Digest.Encoded()},
+		Annotations: map[string]string{"ref": "0.1.0", "flavor": "upstream"},
+	}, dependencies[1])
+	require.Equal(t, "../packages", dependencies[2].URI)
+	require.Equal(t, pkgManifestDescs[1].Digest.Encoded(), dependencies[2].Digest["sha256"])
+
+	// the statement shared between architectures isn't modified
+	require.Len(t, statement.Predicate.BuildDefinition.ResolvedDependencies, 1)
+	require.Len(t, sourceDependencies[:2][1].Name, 0)
+
+	_, err = completeProvenance(statement, bundle, pkgManifestDescs[:1], bundleYAMLDesc)
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@
 package bundler
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/uds-cli/src/types/provenance"
 	goyaml "github.com/goccy/go-yaml"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zarf-dev/zarf/src/pkg/message"
@@ -44,16 +46,20 @@ func NewRemoteBundle(opts *RemoteBundleOpts) *RemoteBundle {
 	}
 }
 
-// create creates the bundle in a remote OCI registry publishes w/ optional signature to the remote repository.
-func (r *RemoteBundle) create(ctx context.Context, signature []byte) error {
+// create creates the bundle in a remote OCI registry publishes w/ optional signatures to the remote repository.
+func (r *RemoteBundle) create(ctx context.Context, signatures [][]byte, statement *provenance.Statement) error {
 	// set the bundle remote's reference from metadata
 	r.output = boci.EnsureOCIPrefix(r.output)
 	ref, err := referenceFromMetadata(r.output, &r.bundle.Metadata)
 	if err != nil {
 		return err
 	}
+	bundle := r.bundle
+	if bundle.Metadata.Architecture == "" {
+		return errors.New("architecture is required for bundling")
+	}
 	platform := ocispec.Platform{
-		Architecture: config.GetArch(),
+		Architecture: bundle.Metadata.Architecture,
 		OS:           oci.MultiOS,
 	}
 
@@ -62,10 +68,6 @@ func (r *RemoteBundle) create(ctx context.Context, signature []byte) error {
 	if err != nil {
 		return err
 	}
-	bundle := r.bundle
-	if bundle.Metadata.Architecture == "" {
-		return errors.New("architecture is required for bundling")
-	}
 	dstRef := bundleRemote.Repo().Reference
 	message.Debug("Bundling", bundle.Metadata.Name, "to", dstRef)
 
@@ -79,7 +81,10 @@ func (r *RemoteBundle) create(ctx context.Context, signature []byte) error {
 	for i, pkg := range bundle.Packages {
 		// todo: can leave this block here or move to pusher.NewPkgPusher (would be closer to NewPkgFetcher pattern)
 		pkgURL := fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
-		src, err := zoci.NewRemote(ctx, pkgURL, platform)
+		src, err := zoci.NewRemote(ctx, pkgURL, ocispec.Platform{
+			Architecture: cmp.Or(pkg.Architecture, platform.Architecture),
+			OS:           platform.OS,
+		})
 		if err != nil {
 			return err
 		}
@@ -119,21 +124,40 @@ func (r *RemoteBundle) create(ctx context.Context, signature []byte) error {
 	message.Debug("Pushed", config.BundleYAML+":", jsonValue)
 	rootManifest.Layers = append(rootManifest.Layers, *bundleYamlDesc)
 
-	// push the bundle's signature
-	if len(signature) > 0 {
+	// push the bundle's provenance, the bundle's package manifests are the first layers of the root manifest
+	var provenanceBytes []byte
+	if statement != nil {
+		provenanceBytes, err = completeProvenance(*statement, bundle, rootManifest.Layers[:len(bundle.Packages)], *bundleYamlDesc)
+		if err != nil {
+			return err
+		}
+		provenanceDesc, err := bundleRemote.PushLayer(ctx, provenanceBytes, zoci.ZarfLayerMediaTypeBlob)
+		if err != nil {
+			return err
+		}
+		provenanceDesc.Annotations = map[string]string{
+			ocispec.AnnotationTitle: config.BundleProvenance,
+		}
+		rootManifest.Layers = append(rootManifest.Layers, *provenanceDesc)
+		message.Debug("Pushed", config.BundleProvenance)
+	}
+
+	// push the bundle's signatures
+	for i, signature := range signatures {
+		signatureName := config.BundleSignatureName(i)
 		bundleYamlSigDesc, err := bundleRemote.PushLayer(ctx, signature, zoci.ZarfLayerMediaTypeBlob)
 		if err != nil {
 			return err
 		}
 		bundleYamlSigDesc.Annotations = map[string]string{
-			ocispec.AnnotationTitle: config.BundleYAMLSignature,
+			ocispec.AnnotationTitle: signatureName,
 		}
 		rootManifest.Layers = append(rootManifest.Layers, *bundleYamlSigDesc)
 		jsonValue, err := utils.JSONValue(bundleYamlSigDesc)
 		if err != nil {
 			return err
 		}
-		message.Debug("Pushed", config.BundleYAMLSignature+":", jsonValue)
+		message.Debug("Pushed", signatureName+":", jsonValue)
 	}
 
 	// push the bundle manifest config
@@ -157,18 +181,25 @@ func (r *RemoteBundle) create(ctx context.Context, signature []byte) error {
 	// push bundle root manifest
 	rootManifest.Config = configDesc
 	rootManifest.SchemaVersion = 2
-	rootManifest.Annotations = manifestAnnotationsFromMetadata(&bundle.Metadata) // maps to registry UI
+	rootManifest.Annotations = manifestAnnotationsFromMetadata(&bundle.Metadata, &bundle.Build) // maps to registry UI
 	rootManifestDesc, err := boci.ToOCIRemote(rootManifest, ocispec.MediaTypeImageManifest, bundleRemote.OrasRemote)
 	if err != nil {
 		return err
 	}
 
 	// create or update, then push index.json
-	err = boci.UpdateIndex(index, bundleRemote.OrasRemote, bundle, *rootManifestDesc)
+	err = boci.UpdateIndex(index, bundleRemote.OrasRemote, bundle, *rootManifestDesc, bundle.Metadata.Version)
 	if err != nil {
 		return err
 	}
 
+	// attach the provenance to the bundle root manifest so that it can be discovered with the referrers API
+	if provenanceBytes != nil {
+		if _, err := boci.AttachReferrer(ctx, bundleRemote.Repo(), *rootManifestDesc, config.BundleProvenanceArtifactType, config.BundleProvenanceArtifactType, provenanceBytes); err != nil {
+			return fmt.Errorf("unable to attach provenance to %s: %w", dstRef, err)
+		}
+	}
+
 	message.HorizontalRule()
 	flags := ""
 	if config.CommonOptions.Insecure {
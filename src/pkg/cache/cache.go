@@ -5,17 +5,22 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/message"
 )
 
 func expandTilde(cachePath string) string {
-	if cachePath[:2] == "~/" {
+	if strings.HasPrefix(cachePath, "~/") {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			fmt.Printf("Error in cache dir: %v\n", err)
@@ -26,63 +31,170 @@ func expandTilde(cachePath string) string {
 	return cachePath
 }
 
-// Add adds a file to the cache
-func Add(filePathToAdd string) error {
-	// ensure cache dir exists
-	cacheDir := config.CommonOptions.CachePath
-	if err := os.MkdirAll(filepath.Join(cacheDir, config.UDSCacheLayers), 0o755); err != nil {
-		return err
-	}
+// layersDir returns the directory holding the cached layers
+func layersDir() string {
+	return filepath.Join(expandTilde(config.CommonOptions.CachePath), config.UDSCacheLayers)
+}
 
+// PullDir returns the directory in the cache that stages the blobs of a pull of the bundle with the given root digest
+func PullDir(rootDigest string) string {
+	return filepath.Join(expandTilde(config.CommonOptions.CachePath), config.UDSCachePulls, rootDigest)
+}
+
+// errDigestMismatch is returned when a layer's contents don't match its digest
+var errDigestMismatch = errors.New("contents do not match the digest")
+
+// Add adds a file to the cache, the file is verified against the digest in its filename before it is added
+func Add(filePathToAdd string) error {
 	// if file already in cache, return
-	filename := strings.Split(filePathToAdd, config.BlobsDir)[1]
-	if Exists(filename) {
+	layerDigest := filepath.Base(filePathToAdd)
+	if Exists(layerDigest) {
 		return nil
 	}
 
-	srcFile, err := os.Open(filePathToAdd)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
+	return withLock(false, func() error {
+		// ensure cache dir exists
+		if err := os.MkdirAll(layersDir(), 0o755); err != nil {
+			return err
+		}
+		if err := copyLayer(filePathToAdd, filepath.Join(layersDir(), layerDigest), layerDigest); err != nil {
+			return fmt.Errorf("unable to cache layer %s: %w", layerDigest, err)
+		}
+		return nil
+	})
+}
 
-	dstFile, err := os.Create(filepath.Join(cacheDir, config.UDSCacheLayers, filename))
-	if err != nil {
-		return err
+// AddFrom adds a layer read from r to the cache, the contents are verified against layerDigest before they are added
+func AddFrom(r io.Reader, layerDigest string) error {
+	if Exists(layerDigest) {
+		return nil
 	}
-	defer dstFile.Close()
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+
+	return withLock(false, func() error {
+		if err := os.MkdirAll(layersDir(), 0o755); err != nil {
+			return err
+		}
+		if err := writeLayer(r, filepath.Join(layersDir(), layerDigest), layerDigest); err != nil {
+			return fmt.Errorf("unable to cache layer %s: %w", layerDigest, err)
+		}
+		return nil
+	})
 }
 
 // Exists checks if a layer exists in the cache
 func Exists(layerDigest string) bool {
-	cacheDir := config.CommonOptions.CachePath
-	layerCachePath := filepath.Join(expandTilde(cacheDir), config.UDSCacheLayers, layerDigest)
-	_, err := os.Stat(layerCachePath)
+	_, err := os.Stat(filepath.Join(layersDir(), layerDigest))
 	return !os.IsNotExist(err)
 }
 
-// Use copies a layer from the cache to the dst dir
+// Use copies a layer from the cache to the dst dir, recording when the layer was last used in its mod time. The layer
+// is verified against its digest as it is copied, and a corrupt layer is removed from the cache
 func Use(layerDigest, dstDir string) error {
-	cacheDir := config.CommonOptions.CachePath
-	layerCachePath := filepath.Join(expandTilde(cacheDir), config.UDSCacheLayers, layerDigest)
-	srcFile, err := os.Open(layerCachePath)
-	if err != nil {
+	layerCachePath := filepath.Join(layersDir(), layerDigest)
+	// ensure blobs/sha256 dir has been created
+	if err := os.MkdirAll(dstDir, 0o755); err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	return withLock(false, func() error {
+		err := copyLayer(layerCachePath, filepath.Join(dstDir, layerDigest), layerDigest)
+		if errors.Is(err, errDigestMismatch) {
+			message.Debugf("Removing corrupt layer %s from the cache", layerDigest)
+			_ = remove(layerDigest)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to use cached layer %s: %w", layerDigest, err)
+		}
+		// a layer whose mod time can't be updated is still usable, it is only pruned sooner
+		now := time.Now()
+		_ = os.Chtimes(layerCachePath, now, now)
+		return nil
+	})
+}
 
-	// ensure blobs/sha256 dir has been created
-	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+// Open opens a layer in the cache for reading, recording when the layer was last used in its mod time. The cache stays
+// locked against pruning until the returned reader is closed. The layer is verified against its digest as it is read,
+// reading a corrupt layer to the end returns an error and removes it from the cache
+func Open(layerDigest string) (io.ReadCloser, error) {
+	lock, err := lockCache(false)
+	if err != nil {
+		return nil, err
+	}
+	layerCachePath := filepath.Join(layersDir(), layerDigest)
+	f, err := os.Open(layerCachePath)
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	now := time.Now()
+	_ = os.Chtimes(layerCachePath, now, now)
+	return &verifiedLayer{file: f, lock: lock, hash: sha256.New(), digest: layerDigest}, nil
+}
+
+// verifiedLayer is a cached layer that is verified against its digest as it is read
+type verifiedLayer struct {
+	file    *os.File
+	lock    io.Closer
+	hash    hash.Hash
+	digest  string
+	corrupt bool
+}
+
+// Read reads from the layer, checking its digest once the whole layer has been read
+func (l *verifiedLayer) Read(p []byte) (int, error) {
+	n, err := l.file.Read(p)
+	l.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		if sum := fmt.Sprintf("%x", l.hash.Sum(nil)); sum != l.digest {
+			l.corrupt = true
+			return n, fmt.Errorf("cached layer %s %w, got %s", l.digest, errDigestMismatch, sum)
+		}
+	}
+	return n, err
+}
+
+// Close closes the layer and releases the cache lock, removing the layer from the cache if it was corrupt
+func (l *verifiedLayer) Close() error {
+	err := l.file.Close()
+	if l.corrupt {
+		message.Debugf("Removing corrupt layer %s from the cache", l.digest)
+		_ = remove(l.digest)
+	}
+	return errors.Join(err, l.lock.Close())
+}
+
+// copyLayer copies src to dst, see writeLayer
+func copyLayer(src, dst, layerDigest string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
 		return err
 	}
+	defer srcFile.Close()
+	if err := writeLayer(srcFile, dst, layerDigest); err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	return nil
+}
 
-	dstFile, err := os.Create(filepath.Join(dstDir, layerDigest))
+// writeLayer writes r to dst through a temp file next to dst, verifying the contents against the layer's digest
+// before renaming the temp file to dst. Readers of dst never see a partially written or corrupt layer, whether it is
+// written by a concurrently fetched package or another CLI process sharing the cache, and a failed write leaves any
+// existing dst untouched
+func writeLayer(r io.Reader, dst, layerDigest string) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+	sha := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, sha), r); err != nil {
+		return err
+	}
+	if sum := fmt.Sprintf("%x", sha.Sum(nil)); sum != layerDigest {
+		return fmt.Errorf("%w %s, got %s", errDigestMismatch, layerDigest, sum)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), dst)
 }
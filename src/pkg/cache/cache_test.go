@@ -0,0 +1,218 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/gofrs/flock"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBlob writes contents to a blobs/sha256 dir under dir, named by the given digest or the contents' digest
+func writeTestBlob(t *testing.T, dir, contents, digest string) string {
+	t.Helper()
+	if digest == "" {
+		digest = fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	}
+	path := filepath.Join(dir, config.BlobsDir, digest)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestAdd(t *testing.T) {
+	newTestCache(t)
+	src := t.TempDir()
+
+	layer := writeTestBlob(t, src, "layer", "")
+	require.NoError(t, Add(layer))
+	require.True(t, Exists(filepath.Base(layer)))
+
+	// a blob that doesn't match its digest isn't cached
+	corrupt := writeTestBlob(t, src, "corrupt", fmt.Sprintf("%x", sha256.Sum256([]byte("expected"))))
+	require.ErrorIs(t, Add(corrupt), errDigestMismatch)
+	require.False(t, Exists(filepath.Base(corrupt)))
+	entries, err := os.ReadDir(layersDir())
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the temp file of the corrupt blob is removed")
+}
+
+func TestWriteLayer(t *testing.T) {
+	dir := t.TempDir()
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte("layer")))
+	dst := filepath.Join(dir, digest)
+	require.NoError(t, writeLayer(strings.NewReader("layer"), dst, digest))
+
+	// failed writes leave the existing layer in place and no temp files behind
+	require.ErrorIs(t, writeLayer(strings.NewReader("corrupt"), dst, digest), errDigestMismatch)
+	require.Error(t, writeLayer(io.MultiReader(strings.NewReader("lay"), iotest.ErrReader(io.ErrUnexpectedEOF)), dst, digest))
+	b, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "layer", string(b))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestUse(t *testing.T) {
+	digests := newTestCache(t, "intact", "corrupted")
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir(), digests[1]), []byte("bit rot"), 0o644))
+	dst := t.TempDir()
+
+	require.NoError(t, Use(digests[0], dst))
+	b, err := os.ReadFile(filepath.Join(dst, digests[0]))
+	require.NoError(t, err)
+	require.Equal(t, "intact", string(b))
+	layers, err := List()
+	require.NoError(t, err)
+	require.Equal(t, digests[0], layers[0].Digest, "using a layer makes it the most recently used")
+
+	// a corrupt layer is removed from the cache instead of being used
+	require.ErrorIs(t, Use(digests[1], dst), errDigestMismatch)
+	require.NoFileExists(t, filepath.Join(dst, digests[1]))
+	require.False(t, Exists(digests[1]))
+}
+
+func TestConcurrentAddAndUse(t *testing.T) {
+	newTestCache(t)
+	src := t.TempDir()
+	layer := writeTestBlob(t, src, "shared layer", "")
+	digest := filepath.Base(layer)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- Add(layer)
+		}()
+		go func(dst string) {
+			defer wg.Done()
+			// the layer is either not cached yet or is used whole
+			if Exists(digest) {
+				errs <- Use(digest, dst)
+			}
+		}(t.TempDir())
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	layers, err := List()
+	require.NoError(t, err)
+	require.Equal(t, []string{digest}, digestsOf(layers))
+}
+
+func TestPruneWaitsForSharedLock(t *testing.T) {
+	newTestCache(t, "layer")
+	// another process using the cache holds a shared lock
+	lock := flock.New(filepath.Join(config.CommonOptions.CachePath, config.UDSCacheLock))
+	require.NoError(t, lock.RLock())
+
+	done := make(chan error)
+	go func() {
+		_, err := Prune(0, 1)
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("prune removed layers while the cache was in use")
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, lock.Unlock())
+	require.NoError(t, <-done)
+	layers, err := List()
+	require.NoError(t, err)
+	require.Empty(t, layers)
+}
+
+func TestLockPull(t *testing.T) {
+	cachePath := config.CommonOptions.CachePath
+	t.Cleanup(func() { config.CommonOptions.CachePath = cachePath })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	config.CommonOptions.CachePath = "~/.uds-cache"
+
+	// the staging dir is under the expanded cache path so that clearing the cache removes it
+	require.Equal(t, filepath.Join(home, ".uds-cache", config.UDSCachePulls, "root"), PullDir("root"))
+
+	unlock, err := LockPull("root")
+	require.NoError(t, err)
+
+	// another pull of the same bundle waits for the first one to finish
+	done := make(chan error)
+	go func() {
+		unlock, err := LockPull("root")
+		if err == nil {
+			err = unlock()
+		}
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("a second pull of the bundle locked its staging dir while the first pull was running")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// pulls of other bundles don't wait
+	unlockOther, err := LockPull("other")
+	require.NoError(t, err)
+	require.NoError(t, unlockOther())
+
+	require.NoError(t, unlock())
+	require.NoError(t, <-done)
+}
+
+func TestAddFrom(t *testing.T) {
+	newTestCache(t)
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte("layer")))
+
+	require.NoError(t, AddFrom(strings.NewReader("layer"), digest))
+	require.True(t, Exists(digest))
+
+	// contents that don't match the digest aren't cached
+	expected := fmt.Sprintf("%x", sha256.Sum256([]byte("expected")))
+	require.ErrorIs(t, AddFrom(strings.NewReader("corrupt"), expected), errDigestMismatch)
+	require.False(t, Exists(expected))
+}
+
+func TestOpen(t *testing.T) {
+	digests := newTestCache(t, "intact", "corrupted")
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir(), digests[1]), []byte("bit rot"), 0o644))
+
+	layer, err := Open(digests[0])
+	require.NoError(t, err)
+	b, err := io.ReadAll(layer)
+	require.NoError(t, err)
+	require.NoError(t, layer.Close())
+	require.Equal(t, "intact", string(b))
+	layers, err := List()
+	require.NoError(t, err)
+	require.Equal(t, digests[0], layers[0].Digest, "opening a layer makes it the most recently used")
+
+	// reading a corrupt layer fails and removes it from the cache once it is closed
+	layer, err = Open(digests[1])
+	require.NoError(t, err)
+	_, err = io.ReadAll(layer)
+	require.ErrorIs(t, err, errDigestMismatch)
+	require.NoError(t, layer.Close())
+	require.False(t, Exists(digests[1]))
+
+	_, err = Open(digests[1])
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
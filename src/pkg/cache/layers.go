@@ -6,19 +6,39 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"strings"
 
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	ocistore "oras.land/oras-go/v2/content/oci"
 )
 
+// Cacheable returns whether a layer is cached under the cache policy
+func Cacheable(layer ocispec.Descriptor) bool {
+	switch config.CommonOptions.CachePolicy {
+	case config.CachePolicyNone:
+		return false
+	case config.CachePolicyImages:
+		// layers with blobs/sha256 in their title are image layers, as shown in the Zarf image manifest
+		return strings.Contains(layer.Annotations[ocispec.AnnotationTitle], config.BlobsDir)
+	default:
+		// manifests and indexes are small and are always fetched to resolve their layers, so only their layers are cached
+		switch layer.MediaType {
+		case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex:
+			return false
+		}
+		return layer.Digest.Algorithm() == digest.SHA256 && layer.Digest.Validate() == nil && layer.Size > 0
+	}
+}
+
 // CheckLayerExists checks if a layer already exists in the bundle store or the cache, copying it to the dstDir if it does
 func CheckLayerExists(ctx context.Context, layer ocispec.Descriptor, store *ocistore.Store, dstDir string) (bool, error) {
 	if exists, _ := store.Exists(ctx, layer); exists {
 		return true, nil
-	} else if Exists(layer.Digest.Encoded()) {
+	} else if Cacheable(layer) && Exists(layer.Digest.Encoded()) {
 		err := Use(layer.Digest.Encoded(), filepath.Join(dstDir, config.BlobsDir))
 		if err == nil {
 			return true, nil
@@ -27,16 +47,16 @@ func CheckLayerExists(ctx context.Context, layer ocispec.Descriptor, store *ocis
 	return false, nil
 }
 
-// AddPulledImgLayers caches the image layers that were just pulled
-func AddPulledImgLayers(pulledLayers []ocispec.Descriptor, dstDir string) (err error) {
+// AddPulledLayers caches the layers that were just pulled, as allowed by the cache policy. A layer that can't be
+// cached doesn't stop the others from being cached, and the errors of every such layer are returned
+func AddPulledLayers(pulledLayers []ocispec.Descriptor, dstDir string) error {
+	var errs []error
 	for _, layer := range pulledLayers {
-		// layers with blobs/sha256 in their title are image layers, as shown in the Zarf image manifest
-		if strings.Contains(layer.Annotations[ocispec.AnnotationTitle], config.BlobsDir) {
-			err = Add(filepath.Join(dstDir, config.BlobsDir, layer.Digest.Encoded()))
-			if err != nil {
-				return err
+		if Cacheable(layer) {
+			if err := Add(filepath.Join(dstDir, config.BlobsDir, layer.Digest.Encoded())); err != nil {
+				errs = append(errs, err)
 			}
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
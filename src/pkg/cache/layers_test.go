@@ -0,0 +1,74 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheable(t *testing.T) {
+	layer := func(mediaType, title string) ocispec.Descriptor {
+		return ocispec.Descriptor{
+			MediaType:   mediaType,
+			Digest:      digest.FromString(title),
+			Size:        int64(len(title)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		}
+	}
+	image := layer(ocispec.MediaTypeImageLayer, "images/blobs/sha256/abc")
+	component := layer("application/vnd.zarf.layer.v1.blob", "components/podinfo.tar")
+	manifest := layer(ocispec.MediaTypeImageManifest, "")
+	empty := layer(ocispec.MediaTypeImageLayer, "")
+	sha512 := component
+	sha512.Digest = digest.SHA512.FromString("components/podinfo.tar")
+
+	tests := []struct {
+		policy string
+		layer  ocispec.Descriptor
+		want   bool
+	}{
+		{config.CachePolicyAll, image, true},
+		{config.CachePolicyAll, component, true},
+		{config.CachePolicyAll, manifest, false},
+		{config.CachePolicyAll, empty, false},
+		{config.CachePolicyAll, sha512, false},
+		{"", component, true},
+		{config.CachePolicyImages, image, true},
+		{config.CachePolicyImages, component, false},
+		{config.CachePolicyNone, image, false},
+		{config.CachePolicyNone, component, false},
+	}
+	policy := config.CommonOptions.CachePolicy
+	t.Cleanup(func() { config.CommonOptions.CachePolicy = policy })
+	for _, tt := range tests {
+		config.CommonOptions.CachePolicy = tt.policy
+		require.Equal(t, tt.want, Cacheable(tt.layer), "policy %q, layer %s", tt.policy, tt.layer.Annotations[ocispec.AnnotationTitle])
+	}
+}
+
+func TestAddPulledLayers(t *testing.T) {
+	newTestCache(t)
+	dst := t.TempDir()
+	corrupt := writeTestBlob(t, dst, "corrupt", digest.FromString("expected").Encoded())
+	intact := writeTestBlob(t, dst, "intact", "")
+	layer := func(path string) ocispec.Descriptor {
+		return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: digest.NewDigestFromEncoded(digest.SHA256, filepath.Base(path)), Size: 7}
+	}
+	missing := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: digest.FromString("missing"), Size: 7}
+
+	// layers that can't be cached don't stop the others from being cached
+	err := AddPulledLayers([]ocispec.Descriptor{layer(corrupt), missing, layer(intact)}, dst)
+	require.ErrorIs(t, err, errDigestMismatch)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.True(t, Exists(filepath.Base(intact)))
+	require.False(t, Exists(filepath.Base(corrupt)))
+}
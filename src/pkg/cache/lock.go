@@ -0,0 +1,66 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/gofrs/flock"
+)
+
+// withLock runs fn while holding a file lock on the cache, so that CLI processes sharing a cache don't remove layers
+// out from under each other. Adding and using layers only needs a shared lock because layers are written atomically,
+// while removing layers takes an exclusive lock
+func withLock(exclusive bool, fn func() error) error {
+	lock, err := lockCache(exclusive)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	return fn()
+}
+
+// lockCache takes a file lock on the cache, the caller releases it with Close
+func lockCache(exclusive bool) (*flock.Flock, error) {
+	cacheDir := expandTilde(config.CommonOptions.CachePath)
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, err
+	}
+	lock := flock.New(filepath.Join(cacheDir, config.UDSCacheLock))
+	lockFn := lock.RLock
+	if exclusive {
+		lockFn = lock.Lock
+	}
+	if err := lockFn(); err != nil {
+		return nil, fmt.Errorf("unable to lock the cache: %w", err)
+	}
+	return lock, nil
+}
+
+// LockPull locks the staging directory of a pull of the bundle with the given root digest, waiting for any other pull
+// of the same bundle to finish so that pulls don't remove each other's blobs. It also holds a shared lock on the cache
+// so that clearing the cache waits for the pull. The caller releases the locks with the returned func
+func LockPull(rootDigest string) (func() error, error) {
+	cacheLock, err := lockCache(false)
+	if err != nil {
+		return nil, err
+	}
+	pullsDir := filepath.Dir(PullDir(rootDigest))
+	if err := os.MkdirAll(pullsDir, 0o700); err != nil {
+		return nil, errors.Join(err, cacheLock.Close())
+	}
+	// the lock file sits next to the staging directory so that starting a pull over doesn't remove it
+	pullLock := flock.New(filepath.Join(pullsDir, rootDigest+".lock"))
+	if err := pullLock.Lock(); err != nil {
+		return nil, errors.Join(fmt.Errorf("unable to lock the pull's staging directory: %w", err), cacheLock.Close())
+	}
+	return func() error {
+		return errors.Join(pullLock.Close(), cacheLock.Close())
+	}, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+)
+
+// Layer is a layer in the cache
+type Layer struct {
+	// Digest is the encoded sha256 digest of the layer, which is also its filename in the cache
+	Digest string
+	// Size is the size of the layer in bytes
+	Size int64
+	// LastUsed is when the layer was last added to or used from the cache
+	LastUsed time.Time
+}
+
+// List returns the layers in the cache, most recently used first
+func List() ([]Layer, error) {
+	entries, err := os.ReadDir(layersDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	layers := make([]Layer, 0, len(entries))
+	for _, entry := range entries {
+		// skip the temp files of layers that are still being copied into the cache
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, Layer{Digest: entry.Name(), Size: info.Size(), LastUsed: info.ModTime()})
+	}
+	slices.SortFunc(layers, func(a, b Layer) int {
+		if c := b.LastUsed.Compare(a.LastUsed); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Digest, b.Digest)
+	})
+	return layers, nil
+}
+
+// Size returns the number of layers in the cache and their total size in bytes
+func Size() (int, int64, error) {
+	layers, err := List()
+	if err != nil {
+		return 0, 0, err
+	}
+	var total int64
+	for _, layer := range layers {
+		total += layer.Size
+	}
+	return len(layers), total, nil
+}
+
+// Prune removes the layers that haven't been used for longer than olderThan, then removes the least recently used
+// layers until the cache is no larger than maxSize bytes. A zero olderThan or maxSize skips that step. It returns the
+// removed layers
+func Prune(olderThan time.Duration, maxSize int64) ([]Layer, error) {
+	var pruned []Layer
+	err := withLock(true, func() error {
+		layers, err := List()
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, layer := range layers {
+			total += layer.Size
+		}
+
+		cutoff := time.Now().Add(-olderThan)
+		// layers are sorted most recently used first, so prune from the end
+		for i := len(layers) - 1; i >= 0; i-- {
+			layer := layers[i]
+			expired := olderThan > 0 && layer.LastUsed.Before(cutoff)
+			oversized := maxSize > 0 && total > maxSize
+			if !expired && !oversized {
+				break
+			}
+			if err := remove(layer.Digest); err != nil {
+				return err
+			}
+			total -= layer.Size
+			pruned = append(pruned, layer)
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// Verify checks each layer in the cache against the digest in its filename, removing the layers that don't match. It
+// returns the removed layers
+func Verify() ([]Layer, error) {
+	var corrupt []Layer
+	err := withLock(true, func() error {
+		layers, err := List()
+		if err != nil {
+			return err
+		}
+		for _, layer := range layers {
+			if helpers.SHAsMatch(filepath.Join(layersDir(), layer.Digest), layer.Digest) == nil {
+				continue
+			}
+			if err := remove(layer.Digest); err != nil {
+				return err
+			}
+			corrupt = append(corrupt, layer)
+		}
+		return nil
+	})
+	return corrupt, err
+}
+
+// Clear removes every cached layer along with the staged blobs of partially pulled bundles
+func Clear() error {
+	return withLock(true, func() error {
+		cacheDir := expandTilde(config.CommonOptions.CachePath)
+		for _, dir := range []string{config.UDSCacheLayers, config.UDSCachePulls} {
+			if err := os.RemoveAll(filepath.Join(cacheDir, dir)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// remove removes a layer from the cache, a layer that is already gone is not an error
+func remove(layerDigest string) error {
+	err := os.Remove(filepath.Join(layersDir(), layerDigest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCache points the cache at a temp dir and adds a layer for each of contents, each layer last used an hour
+// before the next, returning the layers' digests in the order they were added
+func newTestCache(t *testing.T, contents ...string) []string {
+	t.Helper()
+	cachePath := config.CommonOptions.CachePath
+	t.Cleanup(func() { config.CommonOptions.CachePath = cachePath })
+	config.CommonOptions.CachePath = t.TempDir()
+	require.NoError(t, os.MkdirAll(layersDir(), 0o755))
+
+	digests := make([]string, 0, len(contents))
+	lastUsed := time.Now().Add(-time.Duration(len(contents)) * time.Hour)
+	for _, c := range contents {
+		digest := fmt.Sprintf("%x", sha256.Sum256([]byte(c)))
+		path := filepath.Join(layersDir(), digest)
+		require.NoError(t, os.WriteFile(path, []byte(c), 0o644))
+		lastUsed = lastUsed.Add(time.Hour)
+		require.NoError(t, os.Chtimes(path, lastUsed, lastUsed))
+		digests = append(digests, digest)
+	}
+	return digests
+}
+
+func digestsOf(layers []Layer) []string {
+	var digests []string
+	for _, layer := range layers {
+		digests = append(digests, layer.Digest)
+	}
+	return digests
+}
+
+func TestList(t *testing.T) {
+	digests := newTestCache(t, "oldest", "middle", "newest")
+	// an in-flight copy into the cache isn't listed
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir(), digests[0]+".123.tmp"), []byte("partial"), 0o644))
+
+	layers, err := List()
+	require.NoError(t, err)
+	require.Equal(t, []string{digests[2], digests[1], digests[0]}, digestsOf(layers))
+	require.Equal(t, int64(len("newest")), layers[0].Size)
+
+	count, size, err := Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, int64(len("oldest")+len("middle")+len("newest")), size)
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name      string
+		olderThan time.Duration
+		maxSize   int64
+		// wantPruned are the indexes of the pruned layers, least recently used first
+		wantPruned []int
+	}{
+		{name: "nothing to prune", olderThan: 24 * time.Hour, maxSize: 100},
+		{name: "older than", olderThan: 30 * time.Minute, wantPruned: []int{0, 1}},
+		{name: "max size", maxSize: 12, wantPruned: []int{0}},
+		{name: "older than and max size", olderThan: 90 * time.Minute, maxSize: 6, wantPruned: []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the layers were last used 2h, 1h and 0h ago
+			digests := newTestCache(t, "oldest", "middle", "newest")
+			pruned, err := Prune(tt.olderThan, tt.maxSize)
+			require.NoError(t, err)
+
+			var want []string
+			for _, i := range tt.wantPruned {
+				want = append(want, digests[i])
+			}
+			require.Equal(t, want, digestsOf(pruned))
+			for _, digest := range want {
+				require.False(t, Exists(digest))
+			}
+			remaining, err := List()
+			require.NoError(t, err)
+			require.Len(t, remaining, len(digests)-len(want))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	digests := newTestCache(t, "intact", "corrupted")
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir(), digests[1]), []byte("bit rot"), 0o644))
+
+	corrupt, err := Verify()
+	require.NoError(t, err)
+	require.Equal(t, []string{digests[1]}, digestsOf(corrupt))
+	require.True(t, Exists(digests[0]))
+	require.False(t, Exists(digests[1]))
+}
+
+func TestClear(t *testing.T) {
+	digests := newTestCache(t, "layer")
+	pullsDir := filepath.Join(config.CommonOptions.CachePath, config.UDSCachePulls, "root")
+	require.NoError(t, os.MkdirAll(pullsDir, 0o755))
+
+	require.NoError(t, Clear())
+	require.False(t, Exists(digests[0]))
+	require.NoDirExists(t, pullsDir)
+	layers, err := List()
+	require.NoError(t, err)
+	require.Empty(t, layers)
+}
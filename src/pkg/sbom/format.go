@@ -0,0 +1,240 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package sbom merges the Syft SBOMs of a bundle's Zarf packages into a single bundle SBOM
+package sbom
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	spdx "github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+const (
+	// toolName is the name of the tool recorded as the creator of merged SBOMs
+	toolName = "uds-cli"
+	// noAssertion is the SPDX value for information that wasn't determined
+	noAssertion = "NOASSERTION"
+)
+
+// Metadata describes the bundle a merged SBOM is for
+type Metadata struct {
+	Name        string
+	Version     string
+	ToolVersion string
+	Timestamp   time.Time
+}
+
+// FileName returns the name of the file to write a bundle's merged SBOM to in the given format
+func FileName(bundleName, format string) string {
+	ext := "cdx.json"
+	if format == FormatSPDX {
+		ext = "spdx.json"
+	}
+	return fmt.Sprintf("%s-bundle-sbom.%s", bundleName, ext)
+}
+
+// Write writes the collection as a single SBOM document in the given format
+func Write(w io.Writer, format string, c *Collection, metadata Metadata) error {
+	switch format {
+	case FormatCycloneDX:
+		return writeCycloneDX(w, c, metadata)
+	case FormatSPDX:
+		return writeSPDX(w, c, metadata)
+	default:
+		return fmt.Errorf("unsupported SBOM format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// writeCycloneDX writes the collection as a CycloneDX JSON document, where each component records the packages and
+// images it was found in as properties
+func writeCycloneDX(w io.Writer, c *Collection, metadata Metadata) error {
+	bom := cdx.NewBOM()
+	bom.SerialNumber = "urn:uuid:" + uuid.NewString()
+	bom.Metadata = &cdx.Metadata{
+		Timestamp: metadata.Timestamp.UTC().Format(time.RFC3339),
+		Tools: &cdx.ToolsChoice{
+			Components: &[]cdx.Component{{Type: cdx.ComponentTypeApplication, Name: toolName, Version: metadata.ToolVersion}},
+		},
+		Component: &cdx.Component{
+			BOMRef:  "bundle",
+			Type:    cdx.ComponentTypeApplication,
+			Name:    metadata.Name,
+			Version: metadata.Version,
+		},
+	}
+
+	components := c.Components()
+	cdxComponents := make([]cdx.Component, 0, len(components))
+	for i, component := range components {
+		cdxComponent := cdx.Component{
+			BOMRef:     fmt.Sprintf("component-%d", i+1),
+			Type:       cdx.ComponentTypeLibrary,
+			Name:       component.Name,
+			Version:    component.Version,
+			PackageURL: component.PURL,
+			CPE:        component.CPE,
+		}
+		if len(component.Licenses) > 0 {
+			licenses := make(cdx.Licenses, 0, len(component.Licenses))
+			for _, license := range component.Licenses {
+				licenses = append(licenses, cdx.LicenseChoice{License: &cdx.License{Name: license}})
+			}
+			cdxComponent.Licenses = &licenses
+		}
+		properties := []cdx.Property{}
+		if component.Type != "" {
+			properties = append(properties, cdx.Property{Name: "uds:syft:type", Value: component.Type})
+		}
+		for _, source := range component.Sources {
+			properties = append(properties, cdx.Property{Name: "uds:package", Value: source.Package})
+			if source.Image != "" {
+				properties = append(properties, cdx.Property{Name: "uds:image", Value: source.Package + ":" + source.Image})
+			}
+			if source.ZarfComponent != "" {
+				properties = append(properties, cdx.Property{Name: "uds:component", Value: source.Package + ":" + source.ZarfComponent})
+			}
+		}
+		cdxComponent.Properties = &properties
+		cdxComponents = append(cdxComponents, cdxComponent)
+	}
+	bom.Components = &cdxComponents
+
+	return cdx.NewBOMEncoder(w, cdx.BOMFileFormatJSON).SetPretty(true).EncodeVersion(bom, cdx.SpecVersion1_5)
+}
+
+// writeSPDX writes the collection as an SPDX JSON document, where the bundle contains its packages, each package
+// contains its images and each image contains the components found in it
+func writeSPDX(w io.Writer, c *Collection, metadata Metadata) error {
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      metadata.Name,
+		DocumentNamespace: fmt.Sprintf("https://github.com/defenseunicorns/uds-cli/sbom/%s-%s-%s", metadata.Name, metadata.Version, uuid.NewString()),
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{{CreatorType: "Tool", Creator: fmt.Sprintf("%s-%s", toolName, metadata.ToolVersion)}},
+			Created:  metadata.Timestamp.UTC().Format(time.RFC3339),
+		},
+	}
+
+	ids := 0
+	addPackage := func(name, version, comment string) common.ElementID {
+		ids++
+		id := common.ElementID(fmt.Sprintf("Package-%d", ids))
+		doc.Packages = append(doc.Packages, &spdx.Package{
+			PackageName:             name,
+			PackageSPDXIdentifier:   id,
+			PackageVersion:          version,
+			PackageDownloadLocation: noAssertion,
+			PackageLicenseConcluded: noAssertion,
+			PackageLicenseDeclared:  noAssertion,
+			PackageCopyrightText:    noAssertion,
+			PackageComment:          comment,
+		})
+		return id
+	}
+	relate := func(a, b common.ElementID, relationship string) {
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(a)),
+			RefB:         common.MakeDocElementID("", string(b)),
+			Relationship: relationship,
+		})
+	}
+
+	bundleID := addPackage(metadata.Name, metadata.Version, "UDS bundle")
+	relate(doc.SPDXIdentifier, bundleID, common.TypeRelationshipDescribe)
+
+	// the bundle contains its packages, which contain their images and Zarf components
+	sourceIDs := make(map[Source]common.ElementID)
+	for _, pkg := range c.Packages() {
+		pkgID := addPackage(pkg, "", "Zarf package")
+		relate(bundleID, pkgID, common.TypeRelationshipContains)
+		for _, image := range c.Images(pkg) {
+			imageID := addPackage(image, "", "Image")
+			relate(pkgID, imageID, common.TypeRelationshipContains)
+			sourceIDs[Source{Package: pkg, Image: image}] = imageID
+		}
+		for _, zarfComponent := range c.ZarfComponents(pkg) {
+			componentID := addPackage(zarfComponent, "", "Zarf component")
+			relate(pkgID, componentID, common.TypeRelationshipContains)
+			sourceIDs[Source{Package: pkg, ZarfComponent: zarfComponent}] = componentID
+		}
+	}
+
+	for _, component := range c.Components() {
+		id := addPackage(component.Name, component.Version, "")
+		spdxPkg := doc.Packages[len(doc.Packages)-1]
+		if expression, ok := spdxLicenseExpression(component.Licenses); ok {
+			spdxPkg.PackageLicenseDeclared = expression
+		} else if len(component.Licenses) > 0 {
+			spdxPkg.PackageLicenseComments = strings.Join(component.Licenses, "; ")
+		}
+		if component.PURL != "" {
+			spdxPkg.PackageExternalReferences = append(spdxPkg.PackageExternalReferences, &spdx.PackageExternalReference{
+				Category: common.CategoryPackageManager,
+				RefType:  common.TypePackageManagerPURL,
+				Locator:  component.PURL,
+			})
+		}
+		if component.CPE != "" {
+			spdxPkg.PackageExternalReferences = append(spdxPkg.PackageExternalReferences, &spdx.PackageExternalReference{
+				Category: common.CategorySecurity,
+				RefType:  common.TypeSecurityCPE23Type,
+				Locator:  component.CPE,
+			})
+		}
+		for _, source := range component.Sources {
+			relate(sourceIDs[source], id, common.TypeRelationshipContains)
+		}
+	}
+
+	return spdxjson.Write(doc, w, spdxjson.Indent("  "))
+}
+
+// spdxLicenseID matches a license ID of an SPDX license expression, including any surrounding parentheses
+var spdxLicenseID = regexp.MustCompile(`^\(*[A-Za-z0-9][A-Za-z0-9.+-]*\)*$`)
+
+// isSPDXOperator reports whether a token is an SPDX license expression operator
+func isSPDXOperator(token string) bool {
+	return token == "AND" || token == "OR" || token == "WITH"
+}
+
+// spdxLicenseExpression joins licenses into an SPDX license expression, returning false if any of the licenses isn't
+// made up of license IDs and operators
+func spdxLicenseExpression(licenses []string) (string, bool) {
+	if len(licenses) == 0 {
+		return "", false
+	}
+	for _, license := range licenses {
+		// license IDs and operators must alternate, which rules out free text such as "Custom License"
+		tokens := strings.Fields(license)
+		if len(tokens)%2 == 0 {
+			return "", false
+		}
+		for i, token := range tokens {
+			if i%2 == 1 && !isSPDXOperator(token) || i%2 == 0 && (isSPDXOperator(token) || !spdxLicenseID.MatchString(token)) {
+				return "", false
+			}
+		}
+	}
+	if len(licenses) == 1 {
+		return licenses[0], true
+	}
+	parts := make([]string, 0, len(licenses))
+	for _, license := range licenses {
+		if len(strings.Fields(license)) > 1 {
+			license = "(" + license + ")"
+		}
+		parts = append(parts, license)
+	}
+	return strings.Join(parts, " AND "), true
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package sbom merges the Syft SBOMs of a bundle's Zarf packages into a single bundle SBOM
+package sbom
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+const (
+	// LicenseFormatJSON is the JSON format for license reports
+	LicenseFormatJSON = "json"
+	// LicenseFormatCSV is the CSV format for license reports
+	LicenseFormatCSV = "csv"
+)
+
+// LicenseFormats are the formats a license report can be written in besides the default YAML
+var LicenseFormats = []string{LicenseFormatJSON, LicenseFormatCSV}
+
+// UnknownLicense groups the components whose SBOMs don't declare a license
+const UnknownLicense = "UNKNOWN"
+
+const (
+	// FlagUnknown marks a component without a known license
+	FlagUnknown = "unknown"
+	// FlagDenied marks a component with a license on the denylist
+	FlagDenied = "denied"
+)
+
+// LicenseReport is the license inventory of a bundle
+type LicenseReport struct {
+	// Licenses lists each license and the components that use it
+	Licenses []LicenseGroup `json:"licenses"`
+	// Packages lists the licenses found in each package and its images and Zarf components
+	Packages []PackageLicenses `json:"packages"`
+	// Flagged lists the components with unknown or denied licenses
+	Flagged []FlaggedComponent `json:"flagged,omitempty"`
+}
+
+// LicenseGroup is a license and the components that use it
+type LicenseGroup struct {
+	License    string   `json:"license"`
+	Components []string `json:"components"`
+	Packages   []string `json:"packages"`
+}
+
+// PackageLicenses are the licenses found in a Zarf package
+type PackageLicenses struct {
+	Name           string           `json:"name"`
+	Licenses       []string         `json:"licenses"`
+	Images         []SourceLicenses `json:"images,omitempty"`
+	ZarfComponents []SourceLicenses `json:"zarfComponents,omitempty"`
+}
+
+// SourceLicenses are the licenses found in an image or Zarf component
+type SourceLicenses struct {
+	Name     string   `json:"name"`
+	Licenses []string `json:"licenses"`
+}
+
+// FlaggedComponent is a component with an unknown or denied license
+type FlaggedComponent struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version,omitempty"`
+	PURL     string   `json:"purl,omitempty"`
+	Licenses []string `json:"licenses"`
+	Reason   string   `json:"reason"`
+	Packages []string `json:"packages"`
+}
+
+// NewLicenseReport summarizes the licenses of the collection's components, flagging components whose licenses are
+// unknown or match one of the denylist's patterns (case-insensitive globs such as "GPL-*")
+func NewLicenseReport(c *Collection, denylist []string) (*LicenseReport, error) {
+	for _, pattern := range denylist {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return nil, fmt.Errorf("invalid license denylist pattern %q: %w", pattern, err)
+		}
+	}
+
+	report := &LicenseReport{}
+	groups := make(map[string]*LicenseGroup)
+	sourceLicenses := make(map[Source][]string)
+
+	for _, component := range c.Components() {
+		licenses := knownLicenses(component.Licenses)
+		if len(licenses) == 0 {
+			licenses = []string{UnknownLicense}
+		}
+		componentName := component.Name
+		if component.Version != "" {
+			componentName += "@" + component.Version
+		}
+		var pkgs []string
+		for _, source := range component.Sources {
+			if !slices.Contains(pkgs, source.Package) {
+				pkgs = append(pkgs, source.Package)
+			}
+			for _, license := range licenses {
+				if !slices.Contains(sourceLicenses[source], license) {
+					sourceLicenses[source] = append(sourceLicenses[source], license)
+				}
+			}
+		}
+
+		for _, license := range licenses {
+			group, ok := groups[license]
+			if !ok {
+				group = &LicenseGroup{License: license}
+				groups[license] = group
+			}
+			if !slices.Contains(group.Components, componentName) {
+				group.Components = append(group.Components, componentName)
+			}
+			for _, pkg := range pkgs {
+				if !slices.Contains(group.Packages, pkg) {
+					group.Packages = append(group.Packages, pkg)
+				}
+			}
+		}
+
+		reason := ""
+		if licenses[0] == UnknownLicense {
+			reason = FlagUnknown
+		} else if isDenied(licenses, denylist) {
+			reason = FlagDenied
+		}
+		if reason != "" {
+			report.Flagged = append(report.Flagged, FlaggedComponent{
+				Name:     component.Name,
+				Version:  component.Version,
+				PURL:     component.PURL,
+				Licenses: licenses,
+				Reason:   reason,
+				Packages: pkgs,
+			})
+		}
+	}
+
+	for _, group := range groups {
+		slices.Sort(group.Components)
+		slices.Sort(group.Packages)
+		report.Licenses = append(report.Licenses, *group)
+	}
+	slices.SortFunc(report.Licenses, func(a, b LicenseGroup) int {
+		return strings.Compare(a.License, b.License)
+	})
+
+	for _, pkg := range c.Packages() {
+		pkgLicenses := PackageLicenses{Name: pkg, Licenses: []string{}}
+		addSource := func(source Source, name string) SourceLicenses {
+			licenses := slices.Sorted(slices.Values(sourceLicenses[source]))
+			for _, license := range licenses {
+				if !slices.Contains(pkgLicenses.Licenses, license) {
+					pkgLicenses.Licenses = append(pkgLicenses.Licenses, license)
+				}
+			}
+			if licenses == nil {
+				licenses = []string{}
+			}
+			return SourceLicenses{Name: name, Licenses: licenses}
+		}
+		for _, image := range c.Images(pkg) {
+			pkgLicenses.Images = append(pkgLicenses.Images, addSource(Source{Package: pkg, Image: image}, image))
+		}
+		for _, zarfComponent := range c.ZarfComponents(pkg) {
+			pkgLicenses.ZarfComponents = append(pkgLicenses.ZarfComponents, addSource(Source{Package: pkg, ZarfComponent: zarfComponent}, zarfComponent))
+		}
+		slices.Sort(pkgLicenses.Licenses)
+		report.Packages = append(report.Packages, pkgLicenses)
+	}
+
+	return report, nil
+}
+
+// WriteLicenseReport writes the report as JSON or as CSV with a row for each license of each component in each image
+// or Zarf component
+func WriteLicenseReport(w io.Writer, format string, c *Collection, report *LicenseReport) error {
+	switch format {
+	case LicenseFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case LicenseFormatCSV:
+		flags := make(map[string]string)
+		for _, flagged := range report.Flagged {
+			flags[flagged.PURL+"/"+flagged.Name+"/"+flagged.Version] = flagged.Reason
+		}
+		writer := csv.NewWriter(w)
+		rows := [][]string{{"package", "image", "zarf_component", "component", "version", "purl", "license", "flag"}}
+		for _, component := range c.Components() {
+			licenses := knownLicenses(component.Licenses)
+			if len(licenses) == 0 {
+				licenses = []string{UnknownLicense}
+			}
+			flag := flags[component.PURL+"/"+component.Name+"/"+component.Version]
+			for _, source := range component.Sources {
+				for _, license := range licenses {
+					rows = append(rows, []string{source.Package, source.Image, source.ZarfComponent, component.Name, component.Version, component.PURL, license, flag})
+				}
+			}
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported license report format %q, must be one of %s", format, strings.Join(LicenseFormats, ", "))
+	}
+}
+
+// knownLicenses drops the placeholder values Syft uses for licenses it couldn't determine
+func knownLicenses(licenses []string) []string {
+	var known []string
+	for _, license := range licenses {
+		switch strings.ToUpper(strings.TrimSpace(license)) {
+		case "", noAssertion, UnknownLicense:
+			continue
+		}
+		known = append(known, license)
+	}
+	return known
+}
+
+// isDenied reports whether any license, or any license ID within a license expression, matches a denylist pattern
+func isDenied(licenses []string, denylist []string) bool {
+	for _, license := range licenses {
+		candidates := []string{license}
+		for _, token := range strings.Fields(license) {
+			if token = strings.Trim(token, "()"); token != "" && !isSPDXOperator(token) {
+				candidates = append(candidates, token)
+			}
+		}
+		for _, candidate := range candidates {
+			for _, pattern := range denylist {
+				// patterns were validated when the report was created
+				if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(candidate)); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
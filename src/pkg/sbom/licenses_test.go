@@ -0,0 +1,103 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLicenseReport(t *testing.T) {
+	c := testCollection(t)
+	require.NoError(t, c.AddSyftJSON("podinfo", "podinfo_6.4.0.json", []byte(`{
+  "artifacts": [{"name": "musl", "version": "1.2.4", "type": "apk", "purl": "pkg:apk/alpine/musl@1.2.4", "licenses": ["NOASSERTION"]}],
+  "source": {"type": "image", "target": {"userInput": "ghcr.io/stefanprodan/podinfo:6.4.0"}}
+}`)))
+
+	report, err := NewLicenseReport(c, []string{"gpl-*"})
+	require.NoError(t, err)
+
+	licenses := map[string]LicenseGroup{}
+	for _, group := range report.Licenses {
+		licenses[group.License] = group
+	}
+	require.Equal(t, []string{"Apache-2.0", "Custom License", "GPL-2.0-only", UnknownLicense, "Zlib"}, func() []string {
+		var names []string
+		for _, group := range report.Licenses {
+			names = append(names, group.License)
+		}
+		return names
+	}())
+	require.Equal(t, []string{"openssl@3.1.4"}, licenses["Apache-2.0"].Components)
+	require.Equal(t, []string{"nginx", "podinfo"}, licenses["Apache-2.0"].Packages)
+	require.Equal(t, []string{"musl@1.2.4"}, licenses[UnknownLicense].Components)
+
+	require.Len(t, report.Packages, 2)
+	podinfo := report.Packages[1]
+	require.Equal(t, "podinfo", podinfo.Name)
+	require.Equal(t, []string{"Apache-2.0", "Custom License", "GPL-2.0-only", UnknownLicense, "Zlib"}, podinfo.Licenses)
+	require.Equal(t, []SourceLicenses{{Name: "files", Licenses: []string{"Zlib"}}}, podinfo.ZarfComponents)
+	require.Equal(t, []string{"Apache-2.0", "Custom License", "GPL-2.0-only", UnknownLicense}, podinfo.Images[0].Licenses)
+
+	require.Equal(t, []FlaggedComponent{
+		{Name: "busybox", Version: "1.36", Licenses: []string{"Custom License", "GPL-2.0-only"}, Reason: FlagDenied, Packages: []string{"podinfo"}},
+		{Name: "musl", Version: "1.2.4", PURL: "pkg:apk/alpine/musl@1.2.4", Licenses: []string{UnknownLicense}, Reason: FlagUnknown, Packages: []string{"podinfo"}},
+	}, report.Flagged)
+
+	_, err = NewLicenseReport(c, []string{"[GPL"})
+	require.Error(t, err)
+}
+
+func Test_isDenied(t *testing.T) {
+	tests := []struct {
+		name     string
+		licenses []string
+		denylist []string
+		want     bool
+	}{
+		{name: "empty denylist", licenses: []string{"GPL-3.0-only"}, want: false},
+		{name: "exact", licenses: []string{"AGPL-3.0-only"}, denylist: []string{"AGPL-3.0-only"}, want: true},
+		{name: "case-insensitive glob", licenses: []string{"MIT", "GPL-3.0-or-later"}, denylist: []string{"gpl-*"}, want: true},
+		{name: "within expression", licenses: []string{"(MIT OR GPL-2.0-only)"}, denylist: []string{"GPL-2.0-only"}, want: true},
+		{name: "operators are ignored", licenses: []string{"MIT AND Apache-2.0"}, denylist: []string{"AND"}, want: false},
+		{name: "no match", licenses: []string{"MIT"}, denylist: []string{"GPL-*"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, isDenied(tt.licenses, tt.denylist))
+		})
+	}
+}
+
+func TestWriteLicenseReport(t *testing.T) {
+	c := testCollection(t)
+	report, err := NewLicenseReport(c, []string{"GPL-*"})
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteLicenseReport(&buf, LicenseFormatJSON, c, report))
+		var decoded LicenseReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		require.Equal(t, *report, decoded)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteLicenseReport(&buf, LicenseFormatCSV, c, report))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, []string{"package", "image", "zarf_component", "component", "version", "purl", "license", "flag"}, rows[0])
+		// busybox has 2 licenses, openssl is in 2 images and zlib is in an image and a Zarf component
+		require.Len(t, rows, 7)
+		require.Contains(t, rows, []string{"podinfo", "ghcr.io/stefanprodan/podinfo:6.4.0", "", "busybox", "1.36", "", "GPL-2.0-only", FlagDenied})
+		require.Contains(t, rows, []string{"podinfo", "", "files", "zlib", "1.3", "pkg:apk/alpine/zlib@1.3", "Zlib", ""})
+	})
+
+	require.Error(t, WriteLicenseReport(&bytes.Buffer{}, "xml", c, report))
+}
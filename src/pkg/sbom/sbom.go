@@ -0,0 +1,293 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package sbom merges the Syft SBOMs of a bundle's Zarf packages into a single bundle SBOM
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mholt/archives"
+)
+
+const (
+	// FormatCycloneDX is the CycloneDX JSON format
+	FormatCycloneDX = "cyclonedx"
+	// FormatSPDX is the SPDX JSON format
+	FormatSPDX = "spdx"
+)
+
+// Formats are the formats a merged bundle SBOM can be written in
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// zarfComponentSBOMPrefix is the prefix of the SBOMs Zarf generates for a component's files and data injections
+const zarfComponentSBOMPrefix = "zarf-component-"
+
+// Source is where a component was found in a bundle
+type Source struct {
+	// Package is the name of the Zarf package
+	Package string
+	// Image is the image the component was found in, empty for components found in a Zarf component's files
+	Image string
+	// ZarfComponent is the Zarf component whose files the component was found in, empty for components found in images
+	ZarfComponent string
+}
+
+// Component is a software component found in one or more of a bundle's SBOMs
+type Component struct {
+	Name     string
+	Version  string
+	Type     string
+	PURL     string
+	CPE      string
+	Licenses []string
+	Sources  []Source
+}
+
+// Collection merges the components of many SBOMs, deduplicating components that appear in more than one package or image
+type Collection struct {
+	components map[string]*Component
+	// images and zarfComponents track every image and Zarf component by package, even those without any components
+	images         map[string][]string
+	zarfComponents map[string][]string
+	// documents are the original Syft JSON SBOMs of each image and Zarf component, used by scanners
+	documents map[Source][]byte
+}
+
+// NewCollection creates an empty collection
+func NewCollection() *Collection {
+	return &Collection{
+		components:     make(map[string]*Component),
+		images:         make(map[string][]string),
+		zarfComponents: make(map[string][]string),
+		documents:      make(map[Source][]byte),
+	}
+}
+
+// syftDocument holds the parts of a Syft JSON SBOM that are merged into the bundle SBOM
+type syftDocument struct {
+	Artifacts []struct {
+		Name     string          `json:"name"`
+		Version  string          `json:"version"`
+		Type     string          `json:"type"`
+		PURL     string          `json:"purl"`
+		CPEs     json.RawMessage `json:"cpes"`
+		Licenses json.RawMessage `json:"licenses"`
+	} `json:"artifacts"`
+	Source struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Metadata struct {
+			UserInput string `json:"userInput"`
+		} `json:"metadata"`
+		// Target is used by older versions of the Syft JSON schema
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+}
+
+// AddTar adds every Syft JSON SBOM in a Zarf package's sboms.tar to the collection
+func (c *Collection) AddTar(ctx context.Context, pkgName string, sbomsTar io.Reader) error {
+	return archives.Tar{}.Extract(ctx, sbomsTar, func(_ context.Context, f archives.FileInfo) error {
+		if f.IsDir() || path.Ext(f.NameInArchive) != ".json" {
+			return nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		return c.AddSyftJSON(pkgName, path.Base(f.NameInArchive), b)
+	})
+}
+
+// AddSyftJSON adds the components of a Syft JSON SBOM from a Zarf package to the collection
+func (c *Collection) AddSyftJSON(pkgName, fileName string, b []byte) error {
+	var doc syftDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("unable to read SBOM %s in package %s: %w", fileName, pkgName, err)
+	}
+
+	source := Source{Package: pkgName}
+	if strings.HasPrefix(fileName, zarfComponentSBOMPrefix) {
+		source.ZarfComponent = strings.TrimSuffix(strings.TrimPrefix(fileName, zarfComponentSBOMPrefix), ".json")
+		if !slices.Contains(c.zarfComponents[pkgName], source.ZarfComponent) {
+			c.zarfComponents[pkgName] = append(c.zarfComponents[pkgName], source.ZarfComponent)
+		}
+	} else {
+		source.Image = doc.imageName(fileName)
+		if !slices.Contains(c.images[pkgName], source.Image) {
+			c.images[pkgName] = append(c.images[pkgName], source.Image)
+		}
+	}
+
+	c.documents[source] = b
+
+	for _, artifact := range doc.Artifacts {
+		if artifact.Name == "" {
+			continue
+		}
+		key := artifact.PURL
+		if key == "" {
+			key = strings.Join([]string{artifact.Type, artifact.Name, artifact.Version}, "/")
+		}
+		component, ok := c.components[key]
+		if !ok {
+			component = &Component{
+				Name:    artifact.Name,
+				Version: artifact.Version,
+				Type:    artifact.Type,
+				PURL:    artifact.PURL,
+			}
+			c.components[key] = component
+		}
+		if component.CPE == "" {
+			component.CPE = firstCPE(artifact.CPEs)
+		}
+		for _, license := range licenseValues(artifact.Licenses) {
+			if !slices.Contains(component.Licenses, license) {
+				component.Licenses = append(component.Licenses, license)
+			}
+		}
+		if !slices.Contains(component.Sources, source) {
+			component.Sources = append(component.Sources, source)
+		}
+	}
+	return nil
+}
+
+// Components returns the collection's components sorted by name, version and purl
+func (c *Collection) Components() []Component {
+	components := make([]Component, 0, len(c.components))
+	for _, component := range c.components {
+		sorted := *component
+		sorted.Licenses = slices.Sorted(slices.Values(component.Licenses))
+		sorted.Sources = slices.Clone(component.Sources)
+		slices.SortFunc(sorted.Sources, compareSources)
+		components = append(components, sorted)
+	}
+	slices.SortFunc(components, func(a, b Component) int {
+		if n := strings.Compare(a.Name, b.Name); n != 0 {
+			return n
+		}
+		if n := strings.Compare(a.Version, b.Version); n != 0 {
+			return n
+		}
+		return strings.Compare(a.PURL, b.PURL)
+	})
+	return components
+}
+
+// Packages returns the names of the Zarf packages in the collection
+func (c *Collection) Packages() []string {
+	var pkgs []string
+	for pkg := range c.images {
+		pkgs = append(pkgs, pkg)
+	}
+	for pkg := range c.zarfComponents {
+		if !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs
+}
+
+// Images returns the images of a Zarf package in the collection
+func (c *Collection) Images(pkgName string) []string {
+	return slices.Sorted(slices.Values(c.images[pkgName]))
+}
+
+// ZarfComponents returns the Zarf components of a Zarf package in the collection that have their own SBOMs
+func (c *Collection) ZarfComponents(pkgName string) []string {
+	return slices.Sorted(slices.Values(c.zarfComponents[pkgName]))
+}
+
+// Document returns the original Syft JSON SBOM of an image or Zarf component
+func (c *Collection) Document(source Source) ([]byte, bool) {
+	b, ok := c.documents[source]
+	return b, ok
+}
+
+// imageName returns the image an SBOM was generated from, falling back to the SBOM's file name
+func (doc syftDocument) imageName(fileName string) string {
+	if doc.Source.Metadata.UserInput != "" {
+		return doc.Source.Metadata.UserInput
+	}
+	var target struct {
+		UserInput string `json:"userInput"`
+	}
+	if len(doc.Source.Target) > 0 && json.Unmarshal(doc.Source.Target, &target) == nil && target.UserInput != "" {
+		return target.UserInput
+	}
+	if doc.Source.Name != "" {
+		return doc.Source.Name
+	}
+	return strings.TrimSuffix(fileName, ".json")
+}
+
+// licenseValues reads the licenses of a Syft artifact, older versions of the Syft JSON schema list them as strings and
+// newer versions as objects
+func licenseValues(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var strs []string
+	if err := json.Unmarshal(raw, &strs); err == nil {
+		return strs
+	}
+	var values []string
+	var licenses []struct {
+		Value          string `json:"value"`
+		SPDXExpression string `json:"spdxExpression"`
+	}
+	if err := json.Unmarshal(raw, &licenses); err != nil {
+		return nil
+	}
+	for _, license := range licenses {
+		if license.SPDXExpression != "" {
+			values = append(values, license.SPDXExpression)
+		} else if license.Value != "" {
+			values = append(values, license.Value)
+		}
+	}
+	return values
+}
+
+// firstCPE reads the first CPE of a Syft artifact, older versions of the Syft JSON schema list them as strings and
+// newer versions as objects
+func firstCPE(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err == nil && len(values) > 0 {
+		return values[0]
+	}
+	var cpes []struct {
+		CPE string `json:"cpe"`
+	}
+	if err := json.Unmarshal(raw, &cpes); err == nil && len(cpes) > 0 {
+		return cpes[0].CPE
+	}
+	return ""
+}
+
+func compareSources(a, b Source) int {
+	if n := strings.Compare(a.Package, b.Package); n != 0 {
+		return n
+	}
+	if n := strings.Compare(a.Image, b.Image); n != 0 {
+		return n
+	}
+	return strings.Compare(a.ZarfComponent, b.ZarfComponent)
+}
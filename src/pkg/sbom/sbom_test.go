@@ -0,0 +1,223 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+package sbom
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// nginxSBOM uses the newer Syft JSON schema with license and CPE objects
+	nginxSBOM = `{
+  "artifacts": [
+    {"name": "openssl", "version": "3.1.4", "type": "apk", "purl": "pkg:apk/alpine/openssl@3.1.4", "cpes": [{"cpe": "cpe:2.3:a:openssl:openssl:3.1.4:*:*:*:*:*:*:*"}], "licenses": [{"value": "Apache-2.0", "spdxExpression": "Apache-2.0"}]},
+    {"name": "zlib", "version": "1.3", "type": "apk", "purl": "pkg:apk/alpine/zlib@1.3", "licenses": [{"value": "Zlib"}]}
+  ],
+  "source": {"type": "image", "name": "nginx", "metadata": {"userInput": "nginx:1.25"}}
+}`
+	// podinfoSBOM uses the older Syft JSON schema with license and CPE strings
+	podinfoSBOM = `{
+  "artifacts": [
+    {"name": "openssl", "version": "3.1.4", "type": "apk", "purl": "pkg:apk/alpine/openssl@3.1.4", "cpes": ["cpe:2.3:a:openssl:openssl:3.1.4:*:*:*:*:*:*:*"], "licenses": ["Apache-2.0"]},
+    {"name": "busybox", "version": "1.36", "type": "binary", "licenses": ["GPL-2.0-only", "Custom License"]}
+  ],
+  "source": {"type": "image", "target": {"userInput": "ghcr.io/stefanprodan/podinfo:6.4.0"}}
+}`
+	// filesSBOM is a Zarf component SBOM
+	filesSBOM = `{
+  "artifacts": [
+    {"name": "zlib", "version": "1.3", "type": "apk", "purl": "pkg:apk/alpine/zlib@1.3"}
+  ],
+  "source": {"type": "directory", "name": "/tmp/zarf-files"}
+}`
+)
+
+func testCollection(t *testing.T) *Collection {
+	t.Helper()
+	c := NewCollection()
+	require.NoError(t, c.AddSyftJSON("nginx", "nginx_1.25.json", []byte(nginxSBOM)))
+	require.NoError(t, c.AddSyftJSON("podinfo", "podinfo_6.4.0.json", []byte(podinfoSBOM)))
+	require.NoError(t, c.AddSyftJSON("podinfo", "zarf-component-files.json", []byte(filesSBOM)))
+	return c
+}
+
+func TestCollection(t *testing.T) {
+	c := testCollection(t)
+
+	require.Equal(t, []string{"nginx", "podinfo"}, c.Packages())
+	require.Equal(t, []string{"nginx:1.25"}, c.Images("nginx"))
+	require.Equal(t, []string{"ghcr.io/stefanprodan/podinfo:6.4.0"}, c.Images("podinfo"))
+	require.Equal(t, []string{"files"}, c.ZarfComponents("podinfo"))
+
+	components := c.Components()
+	require.Len(t, components, 3)
+
+	require.Equal(t, "busybox", components[0].Name)
+	require.Equal(t, []string{"Custom License", "GPL-2.0-only"}, components[0].Licenses)
+
+	openssl := components[1]
+	require.Equal(t, "openssl", openssl.Name)
+	require.Equal(t, "cpe:2.3:a:openssl:openssl:3.1.4:*:*:*:*:*:*:*", openssl.CPE)
+	require.Equal(t, []string{"Apache-2.0"}, openssl.Licenses)
+	require.Equal(t, []Source{
+		{Package: "nginx", Image: "nginx:1.25"},
+		{Package: "podinfo", Image: "ghcr.io/stefanprodan/podinfo:6.4.0"},
+	}, openssl.Sources)
+
+	zlib := components[2]
+	require.Equal(t, []Source{
+		{Package: "nginx", Image: "nginx:1.25"},
+		{Package: "podinfo", ZarfComponent: "files"},
+	}, zlib.Sources)
+
+	doc, ok := c.Document(Source{Package: "podinfo", ZarfComponent: "files"})
+	require.True(t, ok)
+	require.JSONEq(t, filesSBOM, string(doc))
+
+	require.Error(t, c.AddSyftJSON("nginx", "broken.json", []byte("{")))
+}
+
+func TestCollectionAddTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := map[string]string{
+		"nginx_1.25.json":         nginxSBOM,
+		"nginx_1.25.html":         "<html></html>",
+		"compare.html":            "<html></html>",
+		"zarf-component-web.json": filesSBOM,
+	}
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	c := NewCollection()
+	require.NoError(t, c.AddTar(context.Background(), "nginx", &buf))
+	require.Equal(t, []string{"nginx:1.25"}, c.Images("nginx"))
+	require.Equal(t, []string{"web"}, c.ZarfComponents("nginx"))
+	require.Len(t, c.Components(), 2)
+}
+
+func TestWrite(t *testing.T) {
+	c := testCollection(t)
+	metadata := Metadata{Name: "example", Version: "0.0.1", ToolVersion: "v0.0.0", Timestamp: time.Unix(0, 0)}
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatCycloneDX, c, metadata))
+
+		var bom struct {
+			BOMFormat   string `json:"bomFormat"`
+			SpecVersion string `json:"specVersion"`
+			Metadata    struct {
+				Component struct {
+					Name string `json:"name"`
+				} `json:"component"`
+			} `json:"metadata"`
+			Components []struct {
+				Name       string `json:"name"`
+				Properties []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"properties"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &bom))
+		require.Equal(t, "CycloneDX", bom.BOMFormat)
+		require.Equal(t, "1.5", bom.SpecVersion)
+		require.Equal(t, "example", bom.Metadata.Component.Name)
+		require.Len(t, bom.Components, 3)
+
+		properties := map[string][]string{}
+		for _, property := range bom.Components[1].Properties {
+			properties[property.Name] = append(properties[property.Name], property.Value)
+		}
+		require.Equal(t, "openssl", bom.Components[1].Name)
+		require.Equal(t, []string{"apk"}, properties["uds:syft:type"])
+		require.Equal(t, []string{"nginx", "podinfo"}, properties["uds:package"])
+		require.Equal(t, []string{"nginx:nginx:1.25", "podinfo:ghcr.io/stefanprodan/podinfo:6.4.0"}, properties["uds:image"])
+	})
+
+	t.Run("spdx", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatSPDX, c, metadata))
+
+		var doc struct {
+			SPDXVersion string `json:"spdxVersion"`
+			Packages    []struct {
+				SPDXID          string `json:"SPDXID"`
+				Name            string `json:"name"`
+				LicenseDeclared string `json:"licenseDeclared"`
+				LicenseComments string `json:"licenseComments"`
+				ExternalRefs    []any  `json:"externalRefs"`
+			} `json:"packages"`
+			Relationships []struct {
+				Element string `json:"spdxElementId"`
+				Related string `json:"relatedSpdxElement"`
+				Type    string `json:"relationshipType"`
+			} `json:"relationships"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+
+		ids := map[string]string{}
+		for _, pkg := range doc.Packages {
+			ids[pkg.Name] = pkg.SPDXID
+			switch pkg.Name {
+			case "openssl":
+				require.Equal(t, "Apache-2.0", pkg.LicenseDeclared)
+				require.Len(t, pkg.ExternalRefs, 2)
+			case "busybox":
+				require.Equal(t, noAssertion, pkg.LicenseDeclared)
+				require.Equal(t, "Custom License; GPL-2.0-only", pkg.LicenseComments)
+			}
+		}
+		// bundle + 2 packages + 2 images + 1 Zarf component + 3 components
+		require.Len(t, doc.Packages, 9)
+
+		relationships := map[[2]string]string{}
+		for _, r := range doc.Relationships {
+			relationships[[2]string{r.Element, r.Related}] = r.Type
+		}
+		require.Equal(t, "DESCRIBES", relationships[[2]string{"SPDXRef-DOCUMENT", ids["example"]}])
+		require.Equal(t, "CONTAINS", relationships[[2]string{ids["example"], ids["podinfo"]}])
+		require.Equal(t, "CONTAINS", relationships[[2]string{ids["podinfo"], ids["files"]}])
+		require.Equal(t, "CONTAINS", relationships[[2]string{ids["files"], ids["zlib"]}])
+		require.Equal(t, "CONTAINS", relationships[[2]string{ids["nginx:1.25"], ids["openssl"]}])
+	})
+
+	require.Error(t, Write(&bytes.Buffer{}, "swid", c, metadata))
+}
+
+func Test_spdxLicenseExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		licenses []string
+		want     string
+		ok       bool
+	}{
+		{name: "none", licenses: nil, ok: false},
+		{name: "single", licenses: []string{"MIT"}, want: "MIT", ok: true},
+		{name: "multiple", licenses: []string{"MIT", "Apache-2.0"}, want: "MIT AND Apache-2.0", ok: true},
+		{name: "compound", licenses: []string{"MIT OR Apache-2.0", "BSD-3-Clause"}, want: "(MIT OR Apache-2.0) AND BSD-3-Clause", ok: true},
+		{name: "free text", licenses: []string{"MIT", "see LICENSE, file"}, ok: false},
+		{name: "license name", licenses: []string{"Custom License"}, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := spdxLicenseExpression(tt.licenses)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
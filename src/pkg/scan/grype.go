@@ -0,0 +1,160 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package scan scans the SBOMs of a bundle's images and Zarf components for vulnerabilities
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ScannerGrype is the name of the Grype scanner
+const ScannerGrype = "grype"
+
+// Scanners are the names of the supported scanners
+var Scanners = []string{ScannerGrype}
+
+// New returns the named scanner, binary is the path to the scanner's executable (found on the PATH if empty) and
+// dbPath is a local vulnerability database so the scanner doesn't need network access
+func New(name, binary, dbPath string) (Scanner, error) {
+	switch name {
+	case ScannerGrype:
+		if binary == "" {
+			binary = ScannerGrype
+		}
+		return &GrypeScanner{Binary: binary, DBPath: dbPath}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scanner %q, must be one of %s", name, strings.Join(Scanners, ", "))
+	}
+}
+
+// GrypeScanner scans SBOMs with Grype or a Grype-compatible executable
+type GrypeScanner struct {
+	// Binary is the path to the executable
+	Binary string
+	// DBPath is a Grype database cache directory or a database archive to import, when set the database is never
+	// updated so scans work offline
+	DBPath string
+
+	// dbDir is the cache directory a database archive was imported into
+	dbDir string
+}
+
+// Name returns the name of the scanner
+func (g *GrypeScanner) Name() string {
+	return ScannerGrype
+}
+
+// grypeOutput holds the parts of Grype's JSON output that are reported
+type grypeOutput struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Type    string `json:"type"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// Scan scans the Syft JSON SBOM at sbomPath
+func (g *GrypeScanner) Scan(ctx context.Context, sbomPath string) ([]Vulnerability, error) {
+	env, err := g.env(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := g.run(ctx, env, "sbom:"+sbomPath, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var output grypeOutput
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return nil, fmt.Errorf("unable to read %s output: %w", g.Binary, err)
+	}
+	vulns := make([]Vulnerability, 0, len(output.Matches))
+	for _, match := range output.Matches {
+		vulns = append(vulns, Vulnerability{
+			ID:        match.Vulnerability.ID,
+			Severity:  ParseSeverity(match.Vulnerability.Severity),
+			Component: match.Artifact.Name,
+			Version:   match.Artifact.Version,
+			Type:      match.Artifact.Type,
+			FixedIn:   match.Vulnerability.Fix.Versions,
+		})
+	}
+	return vulns, nil
+}
+
+// env returns the environment that points Grype at the local database, importing the database first if it is an archive
+func (g *GrypeScanner) env(ctx context.Context) ([]string, error) {
+	if g.DBPath == "" {
+		return nil, nil
+	}
+	if g.dbDir == "" {
+		info, err := os.Stat(g.DBPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read vulnerability database: %w", err)
+		}
+		if info.IsDir() {
+			g.dbDir = g.DBPath
+		} else {
+			dbDir, err := os.MkdirTemp("", "uds-grype-db-")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := g.run(ctx, g.offlineEnv(dbDir), "db", "import", g.DBPath); err != nil {
+				os.RemoveAll(dbDir)
+				return nil, fmt.Errorf("unable to import vulnerability database %s: %w", g.DBPath, err)
+			}
+			g.dbDir = dbDir
+		}
+	}
+	return g.offlineEnv(g.dbDir), nil
+}
+
+// offlineEnv returns the environment that keeps Grype from reaching the network
+func (g *GrypeScanner) offlineEnv(dbDir string) []string {
+	return []string{
+		"GRYPE_DB_CACHE_DIR=" + dbDir,
+		"GRYPE_DB_AUTO_UPDATE=false",
+		"GRYPE_DB_VALIDATE_AGE=false",
+		"GRYPE_CHECK_FOR_APP_UPDATE=false",
+	}
+}
+
+// Close removes any database imported from an archive
+func (g *GrypeScanner) Close() error {
+	if g.dbDir != "" && g.dbDir != g.DBPath {
+		return os.RemoveAll(g.dbDir)
+	}
+	return nil
+}
+
+func (g *GrypeScanner) run(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, g.Binary, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s not found, install it or set its path with --scanner-path", g.Binary)
+		}
+		return nil, fmt.Errorf("%s %s failed: %w: %s", g.Binary, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
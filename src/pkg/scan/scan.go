@@ -0,0 +1,181 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package scan scans the SBOMs of a bundle's images and Zarf components for vulnerabilities
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
+)
+
+// FormatJSON is the JSON format for scan reports
+const FormatJSON = "json"
+
+// Severity is the severity of a vulnerability, ordered from least to most severe
+type Severity int
+
+// The severities reported by scanners
+const (
+	SeverityUnknown Severity = iota
+	SeverityNegligible
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = []string{"unknown", "negligible", "low", "medium", "high", "critical"}
+
+// Severities are the names of the severities, from least to most severe
+var Severities = severityNames[SeverityNegligible:]
+
+// ParseSeverity parses a severity name case-insensitively, returning SeverityUnknown for names it doesn't know
+func ParseSeverity(name string) Severity {
+	if i := slices.Index(severityNames, strings.ToLower(name)); i >= 0 {
+		return Severity(i)
+	}
+	return SeverityUnknown
+}
+
+// String returns the severity's name
+func (s Severity) String() string {
+	if s < SeverityUnknown || int(s) >= len(severityNames) {
+		return severityNames[SeverityUnknown]
+	}
+	return severityNames[s]
+}
+
+// MarshalText marshals the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText unmarshals the severity from its name
+func (s *Severity) UnmarshalText(b []byte) error {
+	*s = ParseSeverity(string(b))
+	return nil
+}
+
+// Vulnerability is a vulnerability a scanner matched to a component
+type Vulnerability struct {
+	ID        string   `json:"id"`
+	Severity  Severity `json:"severity"`
+	Component string   `json:"component"`
+	Version   string   `json:"version,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	FixedIn   []string `json:"fixedIn,omitempty"`
+}
+
+// Scanner scans a Syft JSON SBOM for vulnerabilities
+type Scanner interface {
+	// Name returns the name of the scanner
+	Name() string
+	// Scan scans the Syft JSON SBOM at sbomPath for vulnerabilities
+	Scan(ctx context.Context, sbomPath string) ([]Vulnerability, error)
+	// Close cleans up anything the scanner created
+	Close() error
+}
+
+// Target is the scan result of an image or Zarf component
+type Target struct {
+	Package         string          `json:"package"`
+	Image           string          `json:"image,omitempty"`
+	ZarfComponent   string          `json:"zarfComponent,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Name returns the image or Zarf component that was scanned
+func (t Target) Name() string {
+	if t.Image != "" {
+		return t.Image
+	}
+	return "component " + t.ZarfComponent
+}
+
+// Report is the scan result of a bundle
+type Report struct {
+	Scanner string   `json:"scanner"`
+	Targets []Target `json:"targets"`
+}
+
+// Run scans the SBOM of every image and Zarf component in the collection, writing the SBOMs to tmpDir for the scanner
+func Run(ctx context.Context, scanner Scanner, c *sbom.Collection, tmpDir string) (*Report, error) {
+	report := &Report{Scanner: scanner.Name(), Targets: []Target{}}
+
+	var sources []sbom.Source
+	for _, pkg := range c.Packages() {
+		for _, image := range c.Images(pkg) {
+			sources = append(sources, sbom.Source{Package: pkg, Image: image})
+		}
+		for _, zarfComponent := range c.ZarfComponents(pkg) {
+			sources = append(sources, sbom.Source{Package: pkg, ZarfComponent: zarfComponent})
+		}
+	}
+
+	for i, source := range sources {
+		doc, ok := c.Document(source)
+		if !ok {
+			continue
+		}
+		target := Target{Package: source.Package, Image: source.Image, ZarfComponent: source.ZarfComponent}
+		sbomPath := filepath.Join(tmpDir, fmt.Sprintf("scan-%d.json", i))
+		if err := os.WriteFile(sbomPath, doc, helpers.ReadWriteUser); err != nil {
+			return nil, err
+		}
+		vulns, err := scanner.Scan(ctx, sbomPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan %s in package %s: %w", target.Name(), target.Package, err)
+		}
+		slices.SortFunc(vulns, compareVulnerabilities)
+		target.Vulnerabilities = vulns
+		if target.Vulnerabilities == nil {
+			target.Vulnerabilities = []Vulnerability{}
+		}
+		report.Targets = append(report.Targets, target)
+	}
+	return report, nil
+}
+
+// Counts returns the number of vulnerabilities of each severity in the report
+func (r *Report) Counts() map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, target := range r.Targets {
+		for _, vuln := range target.Vulnerabilities {
+			counts[vuln.Severity]++
+		}
+	}
+	return counts
+}
+
+// Failures returns the number of vulnerabilities at or above the threshold
+func (r *Report) Failures(threshold Severity) int {
+	failures := 0
+	for severity, count := range r.Counts() {
+		if severity != SeverityUnknown && severity >= threshold {
+			failures += count
+		}
+	}
+	return failures
+}
+
+// compareVulnerabilities sorts the most severe vulnerabilities first, then by ID and component
+func compareVulnerabilities(a, b Vulnerability) int {
+	if a.Severity != b.Severity {
+		return int(b.Severity - a.Severity)
+	}
+	if n := strings.Compare(a.ID, b.ID); n != 0 {
+		return n
+	}
+	if n := strings.Compare(a.Component, b.Component); n != 0 {
+		return n
+	}
+	return strings.Compare(a.Version, b.Version)
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/sbom"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner reports the vulnerabilities listed in an SBOM's "vulnerabilities" field
+type fakeScanner struct {
+	scanned int
+}
+
+func (f *fakeScanner) Name() string { return "fake" }
+
+func (f *fakeScanner) Close() error { return nil }
+
+func (f *fakeScanner) Scan(_ context.Context, sbomPath string) ([]Vulnerability, error) {
+	f.scanned++
+	b, err := os.ReadFile(sbomPath)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Vulnerabilities, nil
+}
+
+func TestRun(t *testing.T) {
+	c := sbom.NewCollection()
+	require.NoError(t, c.AddSyftJSON("nginx", "nginx.json", []byte(`{
+  "artifacts": [{"name": "openssl", "version": "3.1.4"}],
+  "source": {"metadata": {"userInput": "nginx:1.25"}},
+  "vulnerabilities": [
+    {"id": "CVE-2024-0002", "severity": "medium", "component": "openssl"},
+    {"id": "CVE-2024-0001", "severity": "critical", "component": "openssl"},
+    {"id": "GHSA-0000", "severity": "unknown", "component": "openssl"}
+  ]
+}`)))
+	require.NoError(t, c.AddSyftJSON("podinfo", "zarf-component-files.json", []byte(`{
+  "artifacts": [{"name": "zlib", "version": "1.3"}],
+  "vulnerabilities": [{"id": "CVE-2024-0003", "severity": "low", "component": "zlib"}]
+}`)))
+	require.NoError(t, c.AddSyftJSON("podinfo", "podinfo.json", []byte(`{
+  "artifacts": [],
+  "source": {"metadata": {"userInput": "podinfo:6.4.0"}}
+}`)))
+
+	scanner := &fakeScanner{}
+	report, err := Run(context.Background(), scanner, c, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, 3, scanner.scanned)
+	require.Equal(t, "fake", report.Scanner)
+
+	require.Len(t, report.Targets, 3)
+	require.Equal(t, "nginx:1.25", report.Targets[0].Name())
+	require.Equal(t, []string{"CVE-2024-0001", "CVE-2024-0002", "GHSA-0000"}, func() []string {
+		var ids []string
+		for _, vuln := range report.Targets[0].Vulnerabilities {
+			ids = append(ids, vuln.ID)
+		}
+		return ids
+	}())
+	require.Equal(t, "podinfo:6.4.0", report.Targets[1].Name())
+	require.Empty(t, report.Targets[1].Vulnerabilities)
+	require.Equal(t, "component files", report.Targets[2].Name())
+
+	require.Equal(t, map[Severity]int{SeverityCritical: 1, SeverityMedium: 1, SeverityLow: 1, SeverityUnknown: 1}, report.Counts())
+	require.Equal(t, 1, report.Failures(SeverityCritical))
+	require.Equal(t, 1, report.Failures(SeverityHigh))
+	require.Equal(t, 3, report.Failures(SeverityLow))
+	require.Equal(t, 3, report.Failures(SeverityNegligible))
+}
+
+func TestParseSeverity(t *testing.T) {
+	require.Equal(t, SeverityCritical, ParseSeverity("Critical"))
+	require.Equal(t, SeverityNegligible, ParseSeverity("negligible"))
+	require.Equal(t, SeverityUnknown, ParseSeverity("severe"))
+	require.Equal(t, "high", SeverityHigh.String())
+	require.Equal(t, []string{"negligible", "low", "medium", "high", "critical"}, Severities)
+}
+
+func TestGrypeScanner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake grype executable is a shell script")
+	}
+	dir := t.TempDir()
+	dbDir := filepath.Join(dir, "db")
+	require.NoError(t, os.Mkdir(dbDir, 0o700))
+
+	// the fake grype checks that it was pointed at the offline database and prints a single match
+	binary := filepath.Join(dir, "grype")
+	script := `#!/bin/sh
+[ "$GRYPE_DB_CACHE_DIR" = "` + dbDir + `" ] || { echo "wrong db: $GRYPE_DB_CACHE_DIR" >&2; exit 1; }
+[ "$GRYPE_DB_AUTO_UPDATE" = "false" ] || { echo "db updates enabled" >&2; exit 1; }
+[ "$1" = "sbom:/tmp/sbom.json" ] || { echo "unexpected target $1" >&2; exit 1; }
+echo '{"matches":[{"vulnerability":{"id":"CVE-2024-0001","severity":"High","fix":{"versions":["3.1.5"]}},"artifact":{"name":"openssl","version":"3.1.4","type":"apk"}}]}'
+`
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0o700))
+
+	scanner, err := New(ScannerGrype, binary, dbDir)
+	require.NoError(t, err)
+	defer scanner.Close()
+	vulns, err := scanner.Scan(context.Background(), "/tmp/sbom.json")
+	require.NoError(t, err)
+	require.Equal(t, []Vulnerability{{ID: "CVE-2024-0001", Severity: SeverityHigh, Component: "openssl", Version: "3.1.4", Type: "apk", FixedIn: []string{"3.1.5"}}}, vulns)
+
+	_, err = scanner.Scan(context.Background(), "/tmp/other.json")
+	require.ErrorContains(t, err, "unexpected target")
+
+	missing, err := New(ScannerGrype, filepath.Join(dir, "missing"), "")
+	require.NoError(t, err)
+	_, err = missing.Scan(context.Background(), "/tmp/sbom.json")
+	require.Error(t, err)
+
+	_, err = New("trivy", "", "")
+	require.Error(t, err)
+}
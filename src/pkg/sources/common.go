@@ -5,8 +5,18 @@
 package sources
 
 import (
+	"context"
+	"errors"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/layout"
+	"github.com/zarf-dev/zarf/src/pkg/message"
 	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
+	"github.com/zarf-dev/zarf/src/pkg/packager/sources"
+	zarfTypes "github.com/zarf-dev/zarf/src/types"
 )
 
 // addNamespaceOverrides checks if pkg components have charts with namespace overrides and adds them
@@ -44,3 +54,67 @@ func handleFilter(pkg v1alpha1.ZarfPackage, filter filters.ComponentFilterStrate
 	}
 	return filteredComps, numComponents > len(filteredComps), nil
 }
+
+// loadBundledPackage loads a Zarf package whose files were copied out of a local bundle into dst
+func loadBundledPackage(ctx context.Context, dst *layout.PackagePaths, files []string, filter filters.ComponentFilterStrategy, unarchiveAll bool, bundledPkg types.Package, pkgOpts *zarfTypes.ZarfPackageOptions, nsOverrides NamespaceOverrideMap, packageSpinner *message.Spinner) (v1alpha1.ZarfPackage, []string, error) {
+	var pkg v1alpha1.ZarfPackage
+	if err := utils.ReadYAMLStrict(dst.ZarfYAML, &pkg); err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+
+	// if in dev mode and package is a zarf init config, return an empty package
+	if config.Dev && pkg.Kind == v1alpha1.ZarfInitConfig {
+		return v1alpha1.ZarfPackage{}, nil, nil
+	}
+
+	// filter pkg components and determine if its a partial pkg
+	filteredComps, isPartialPkg, err := handleFilter(pkg, filter)
+	if err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+	pkg.Components = filteredComps
+
+	dst.SetFromPaths(ctx, files)
+
+	// verify the package's signature when the bundle provides a public key for it
+	if pkgOpts.PublicKeyPath != "" {
+		if err := sources.ValidatePackageSignature(ctx, dst, pkgOpts.PublicKeyPath); err != nil {
+			return v1alpha1.ZarfPackage{}, nil, err
+		}
+	}
+
+	if err := sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, isPartialPkg); err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+
+	if unarchiveAll {
+		for _, component := range pkg.Components {
+			if err := dst.Components.Unarchive(ctx, component); err != nil {
+				if errors.Is(err, layout.ErrNotLoaded) {
+					_, err := dst.Components.Create(component)
+					if err != nil {
+						return v1alpha1.ZarfPackage{}, nil, err
+					}
+				} else {
+					return v1alpha1.ZarfPackage{}, nil, err
+				}
+			}
+		}
+
+		if dst.SBOMs.Path != "" {
+			if err := dst.SBOMs.Unarchive(); err != nil {
+				return v1alpha1.ZarfPackage{}, nil, err
+			}
+		}
+	}
+	addNamespaceOverrides(&pkg, nsOverrides)
+
+	if config.Dev {
+		setAsYOLO(&pkg)
+	}
+
+	packageSpinner.Successf("Loaded bundled Zarf package: %s", bundledPkg.Name)
+	// ensure we're using the correct package name as specified by the bundle
+	pkg.Metadata.Name = bundledPkg.Name
+	return pkg, nil, nil
+}
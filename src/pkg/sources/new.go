@@ -11,6 +11,7 @@ import (
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	zarfSources "github.com/zarf-dev/zarf/src/pkg/packager/sources"
@@ -32,7 +33,16 @@ func NewFromLocation(bundleCfg types.BundleConfig, pkg types.Package, opts zarfT
 		return nil, fmt.Errorf("no source provided for package %s", pkg.Name)
 	}
 
-	if strings.Contains(pkgLocation, "tar.zst") {
+	if utils.IsValidBundleDir(pkgLocation) {
+		source = &OCIDirBundle{
+			Pkg:            pkg,
+			PkgOpts:        &opts,
+			PkgManifestSHA: sha,
+			TmpDir:         opts.PackageSource,
+			BundleLocation: pkgLocation,
+			nsOverrides:    nsOverrides,
+		}
+	} else if strings.Contains(pkgLocation, "tar.zst") {
 		source = &TarballBundle{
 			Pkg:            pkg,
 			PkgOpts:        &opts,
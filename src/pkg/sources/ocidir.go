@@ -0,0 +1,157 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package sources contains Zarf packager sources
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/layout"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
+	"github.com/zarf-dev/zarf/src/pkg/packager/sources"
+	zarfTypes "github.com/zarf-dev/zarf/src/types"
+)
+
+// OCIDirBundle is a package source for bundles in a local OCI image layout dir that implements Zarf's packager.PackageSource
+type OCIDirBundle struct {
+	PkgOpts        *zarfTypes.ZarfPackageOptions
+	PkgManifestSHA string
+	TmpDir         string
+	BundleLocation string
+	Pkg            types.Package
+	nsOverrides    NamespaceOverrideMap
+}
+
+// LoadPackage loads a Zarf package from a local OCI layout dir bundle
+func (d *OCIDirBundle) LoadPackage(ctx context.Context, dst *layout.PackagePaths, filter filters.ComponentFilterStrategy, unarchiveAll bool) (v1alpha1.ZarfPackage, []string, error) {
+	packageSpinner := message.NewProgressSpinner("Loading bundled Zarf package: %s", d.Pkg.Name)
+	defer packageSpinner.Stop()
+
+	files, err := d.copyPkgFromBundle()
+	if err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+	return loadBundledPackage(ctx, dst, files, filter, unarchiveAll, d.Pkg, d.PkgOpts, d.nsOverrides, packageSpinner)
+}
+
+// LoadPackageMetadata loads a Zarf package's metadata from a local OCI layout dir bundle
+func (d *OCIDirBundle) LoadPackageMetadata(ctx context.Context, dst *layout.PackagePaths, _ bool, _ bool) (v1alpha1.ZarfPackage, []string, error) {
+	imageManifest, err := d.readPkgManifest()
+	if err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+
+	zarfYAMLDesc := imageManifest.Locate(config.ZarfYAML)
+	if oci.IsEmptyDescriptor(zarfYAMLDesc) {
+		return v1alpha1.ZarfPackage{}, nil, fmt.Errorf("zarf.yaml not found in package %s", d.Pkg.Name)
+	}
+	checksumsDesc := imageManifest.Locate(config.ChecksumsTxt)
+
+	// grab zarf.yaml and checksums.txt
+	filePaths := []string{filepath.Join(config.BlobsDir, zarfYAMLDesc.Digest.Encoded()), filepath.Join(config.BlobsDir, checksumsDesc.Digest.Encoded())}
+	if err := helpers.CreatePathAndCopy(d.blobPath(zarfYAMLDesc), filepath.Join(dst.Base, config.ZarfYAML)); err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+	if !oci.IsEmptyDescriptor(checksumsDesc) {
+		if err := helpers.CreatePathAndCopy(d.blobPath(checksumsDesc), filepath.Join(dst.Base, config.ChecksumsTxt)); err != nil {
+			return v1alpha1.ZarfPackage{}, nil, err
+		}
+	}
+
+	// deserialize zarf.yaml to grab checksum for validating pkg integrity
+	var pkg v1alpha1.ZarfPackage
+	if err := utils.ReadYAMLStrict(dst.ZarfYAML, &pkg); err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+
+	dst.SetFromPaths(ctx, filePaths)
+	if err := sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, true); err != nil {
+		return v1alpha1.ZarfPackage{}, nil, err
+	}
+
+	// ensure we're using the correct package name as specified by the bundle
+	pkg.Metadata.Name = d.Pkg.Name
+	return pkg, nil, nil
+}
+
+// Collect doesn't need to be implemented
+func (d *OCIDirBundle) Collect(_ context.Context, _ string) (string, error) {
+	return "", fmt.Errorf("not implemented in %T", d)
+}
+
+// blobPath returns the path of a blob in the bundle's OCI layout dir
+func (d *OCIDirBundle) blobPath(desc ocispec.Descriptor) string {
+	return filepath.Join(d.BundleLocation, config.BlobsDir, desc.Digest.Encoded())
+}
+
+// readPkgManifest reads the Zarf package's image manifest from the bundle's OCI layout dir, verifying it against the
+// digest the bundle references it by
+func (d *OCIDirBundle) readPkgManifest() (*oci.Manifest, error) {
+	pkgManifestDigest := digest.NewDigestFromEncoded(digest.SHA256, d.PkgManifestSHA)
+	if err := pkgManifestDigest.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(d.BundleLocation, config.BlobsDir, d.PkgManifestSHA))
+	if err != nil {
+		return nil, err
+	}
+	if digest.FromBytes(b) != pkgManifestDigest {
+		return nil, fmt.Errorf("manifest of package %s in %s failed verification", d.Pkg.Name, d.BundleLocation)
+	}
+	var manifest oci.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// copyPkgFromBundle copies a Zarf package's layers out of a local OCI layout dir bundle, layers of components that
+// weren't bundled aren't in the dir and are skipped
+func (d *OCIDirBundle) copyPkgFromBundle() ([]string, error) {
+	manifest, err := d.readPkgManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, layer := range manifest.Layers {
+		src := d.blobPath(layer)
+		info, err := os.Stat(src)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		path := layer.Annotations[ocispec.AnnotationTitle]
+		cleanPath := filepath.Clean(path)
+		if strings.Contains(cleanPath, "..") {
+			// throw an error for dangerous looking paths
+			return nil, fmt.Errorf("invalid path detected: %s", path)
+		}
+		if info.Size() != layer.Size {
+			return nil, fmt.Errorf("expected %s to be %d bytes, found %d", path, layer.Size, info.Size())
+		}
+		layerDst := filepath.Join(d.TmpDir, cleanPath)
+		if err := helpers.CreatePathAndCopy(src, layerDst); err != nil {
+			return nil, err
+		}
+		files = append(files, strings.ReplaceAll(layerDst, d.TmpDir+"/", ""))
+	}
+	return files, nil
+}
@@ -10,11 +10,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
-	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -88,6 +86,13 @@ func (r *RemoteBundle) LoadPackage(ctx context.Context, dst *layout.PackagePaths
 
 	dst.SetFromLayers(ctx, layers)
 
+	// verify the package's signature when the bundle provides a public key for it
+	if r.PkgOpts.PublicKeyPath != "" {
+		if err := sources.ValidatePackageSignature(ctx, dst, r.PkgOpts.PublicKeyPath); err != nil {
+			return v1alpha1.ZarfPackage{}, nil, err
+		}
+	}
+
 	err = sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, isPartialPkg)
 	if err != nil {
 		return v1alpha1.ZarfPackage{}, nil, err
@@ -230,20 +235,9 @@ func (r *RemoteBundle) downloadPkgFromRemoteBundle() ([]ocispec.Descriptor, erro
 		for _, pkgLayer := range pkgLayers {
 			if pkgLayer.Digest.Encoded() == manifestLayer.Digest.Encoded() {
 				layersInBundle = append(layersInBundle, manifestLayer)
-				digest := manifestLayer.Digest.Encoded()
-
-				// if it's an image layer and is in the cache, use it
-				if strings.Contains(manifestLayer.Annotations[ocispec.AnnotationTitle], config.BlobsDir) && cache.Exists(digest) {
-					dst := filepath.Join(r.TmpDir, "images", config.BlobsDir)
-					err = cache.Use(digest, dst)
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					// not in cache, so pull
-					layersToPull = append(layersToPull, manifestLayer)
-					estimatedBytes += manifestLayer.Size
-				}
+				// layers that are in the cache are copied from it by CopyLayers instead of being pulled
+				layersToPull = append(layersToPull, manifestLayer)
+				estimatedBytes += manifestLayer.Size
 				break // if layer is found, break out of inner loop
 			}
 		}
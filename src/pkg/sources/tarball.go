@@ -6,7 +6,6 @@ package sources
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -53,59 +52,7 @@ func (t *TarballBundle) LoadPackage(ctx context.Context, dst *layout.PackagePath
 		return v1alpha1.ZarfPackage{}, nil, err
 	}
 
-	var pkg v1alpha1.ZarfPackage
-	if err = utils.ReadYAMLStrict(dst.ZarfYAML, &pkg); err != nil {
-		return v1alpha1.ZarfPackage{}, nil, err
-	}
-
-	// if in dev mode and package is a zarf init config, return an empty package
-	if config.Dev && pkg.Kind == v1alpha1.ZarfInitConfig {
-		return v1alpha1.ZarfPackage{}, nil, nil
-	}
-
-	// filter pkg components and determine if its a partial pkg
-	filteredComps, isPartialPkg, err := handleFilter(pkg, filter)
-	if err != nil {
-		return v1alpha1.ZarfPackage{}, nil, err
-	}
-	pkg.Components = filteredComps
-
-	dst.SetFromPaths(ctx, files)
-
-	if err := sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, isPartialPkg); err != nil {
-		return v1alpha1.ZarfPackage{}, nil, err
-	}
-
-	if unarchiveAll {
-		for _, component := range pkg.Components {
-			if err := dst.Components.Unarchive(ctx, component); err != nil {
-				if errors.Is(err, layout.ErrNotLoaded) {
-					_, err := dst.Components.Create(component)
-					if err != nil {
-						return v1alpha1.ZarfPackage{}, nil, err
-					}
-				} else {
-					return v1alpha1.ZarfPackage{}, nil, err
-				}
-			}
-		}
-
-		if dst.SBOMs.Path != "" {
-			if err := dst.SBOMs.Unarchive(); err != nil {
-				return v1alpha1.ZarfPackage{}, nil, err
-			}
-		}
-	}
-	addNamespaceOverrides(&pkg, t.nsOverrides)
-
-	if config.Dev {
-		setAsYOLO(&pkg)
-	}
-
-	packageSpinner.Successf("Loaded bundled Zarf package: %s", t.Pkg.Name)
-	// ensure we're using the correct package name as specified by the bundle
-	pkg.Metadata.Name = t.Pkg.Name
-	return pkg, nil, err
+	return loadBundledPackage(ctx, dst, files, filter, unarchiveAll, t.Pkg, t.PkgOpts, t.nsOverrides, packageSpinner)
 }
 
 // LoadPackageMetadata loads a Zarf package's metadata from a local tarball bundle
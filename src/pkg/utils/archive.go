@@ -0,0 +1,111 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/mholt/archives"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	"golang.org/x/sync/errgroup"
+)
+
+// normalizedFileInfo hides the timestamps, permissions and owner of a file on disk so that archiving the same files
+// always produces the same tarball
+type normalizedFileInfo struct {
+	fs.FileInfo
+}
+
+// ModTime returns the Unix epoch
+func (normalizedFileInfo) ModTime() time.Time {
+	return time.Unix(0, 0)
+}
+
+// Mode returns fixed permissions for directories and files
+func (fi normalizedFileInfo) Mode() fs.FileMode {
+	if fi.FileInfo.IsDir() {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// Sys returns nil so that the owner of the file isn't recorded
+func (normalizedFileInfo) Sys() any {
+	return nil
+}
+
+// WriteBundleArchive writes the files in pathMap to a bundle tarball at dst. Files are written in order of their path
+// in the archive with normalized timestamps, permissions and owners, so the same files always produce the same tarball
+func WriteBundleArchive(dst string, pathMap types.PathMap) error {
+	_ = os.RemoveAll(dst)
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	files, err := archives.FilesFromDisk(context.TODO(), nil, pathMap)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(files, func(a, b archives.FileInfo) int {
+		return strings.Compare(a.NameInArchive, b.NameInArchive)
+	})
+
+	archiveErrorChan := make(chan error, len(files))
+	jobs := make(chan archives.ArchiveAsyncJob, len(files))
+
+	for _, file := range files {
+		file.FileInfo = normalizedFileInfo{file.FileInfo}
+		archiveJob := archives.ArchiveAsyncJob{
+			File:   file,
+			Result: archiveErrorChan,
+		}
+		jobs <- archiveJob
+	}
+
+	close(jobs)
+
+	archiveErrGroup, ctx := errgroup.WithContext(context.TODO())
+
+	archiveBar := message.NewProgressBar(int64(len(jobs)), "Creating bundle archive")
+
+	defer archiveBar.Close()
+
+	archiveErrGroup.Go(func() error {
+		return config.BundleArchiveFormat.ArchiveAsync(ctx, out, jobs)
+	})
+
+jobLoop:
+	for len(jobs) != 0 {
+		select {
+		case err := <-archiveErrorChan:
+			if err != nil {
+				return err
+			} else {
+				archiveBar.Add(1)
+			}
+		case <-ctx.Done():
+			break jobLoop
+		}
+	}
+
+	if err := archiveErrGroup.Wait(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	archiveBar.Successf("Created bundle archive at: %s", dst)
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBundleArchive(t *testing.T) {
+	files := map[string]string{
+		"index.json":             `{"schemaVersion":2}`,
+		"oci-layout":             `{"imageLayoutVersion":"1.0.0"}`,
+		"blobs/sha256/aaaa":      "first blob",
+		"blobs/sha256/bbbb":      "second blob",
+		"blobs/sha256/cccc/dddd": "nested blob",
+	}
+	// writes the files to a new dir with the given mod time and permissions, returning the archive's sha256sum
+	archive := func(modTime time.Time, perm os.FileMode) string {
+		dir := t.TempDir()
+		pathMap := make(types.PathMap)
+		for name, contents := range files {
+			path := filepath.Join(dir, "src", name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+			require.NoError(t, os.WriteFile(path, []byte(contents), perm))
+			require.NoError(t, os.Chtimes(path, modTime, modTime))
+			pathMap[path] = name
+		}
+		dst := filepath.Join(dir, "uds-bundle-example-amd64-0.0.1.tar.zst")
+		require.NoError(t, WriteBundleArchive(dst, pathMap))
+		sum, err := helpers.GetSHA256OfFile(dst)
+		require.NoError(t, err)
+		return sum
+	}
+
+	first := archive(time.Now(), 0o600)
+	second := archive(time.Now().Add(-time.Hour), 0o640)
+	require.Equal(t, first, second)
+}
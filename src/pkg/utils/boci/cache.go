@@ -0,0 +1,73 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"errors"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// WithCache adds hooks to copyOpts that copy layers from the cache to target instead of pulling them, and that cache
+// the layers that are pulled, as allowed by the cache policy. A layer that can't be copied from the cache is pulled
+// instead, and a layer that can't be cached is only logged
+func WithCache(copyOpts oras.CopyOptions, target oras.Target) oras.CopyOptions {
+	preCopy := copyOpts.PreCopy
+	copyOpts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		if preCopy != nil {
+			if err := preCopy(ctx, desc); err != nil {
+				return err
+			}
+		}
+		if !cache.Cacheable(desc) || !cache.Exists(desc.Digest.Encoded()) {
+			return nil
+		}
+		err := pushFromCache(ctx, desc, target)
+		if err == nil || errors.Is(err, errdef.ErrAlreadyExists) {
+			return oras.SkipNode
+		}
+		message.Debugf("Unable to copy layer %s from the cache, pulling it instead: %s", desc.Digest.Encoded(), err)
+		return nil
+	}
+
+	postCopy := copyOpts.PostCopy
+	copyOpts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		if cache.Cacheable(desc) && !cache.Exists(desc.Digest.Encoded()) {
+			if err := addToCache(ctx, desc, target); err != nil {
+				message.Debugf("Unable to cache layer %s: %s", desc.Digest.Encoded(), err)
+			}
+		}
+		if postCopy != nil {
+			return postCopy(ctx, desc)
+		}
+		return nil
+	}
+	return copyOpts
+}
+
+// pushFromCache pushes a cached layer to target, target verifies the layer against its digest
+func pushFromCache(ctx context.Context, desc ocispec.Descriptor, target oras.Target) error {
+	layer, err := cache.Open(desc.Digest.Encoded())
+	if err != nil {
+		return err
+	}
+	defer layer.Close()
+	return target.Push(ctx, desc, layer)
+}
+
+// addToCache reads a layer that was just copied back out of target and adds it to the cache
+func addToCache(ctx context.Context, desc ocispec.Descriptor, target oras.Target) error {
+	layer, err := target.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer layer.Close()
+	return cache.AddFrom(layer, desc.Digest.Encoded())
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// countingStore counts the blobs fetched from a store
+type countingStore struct {
+	*memory.Store
+	fetched map[string]int
+}
+
+func (s *countingStore) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	s.fetched[desc.Digest.Encoded()]++
+	return s.Store.Fetch(ctx, desc)
+}
+
+func TestWithCache(t *testing.T) {
+	ctx := context.Background()
+	cachePath, cachePolicy := config.CommonOptions.CachePath, config.CommonOptions.CachePolicy
+	t.Cleanup(func() { config.CommonOptions.CachePath, config.CommonOptions.CachePolicy = cachePath, cachePolicy })
+	config.CommonOptions.CachePath = t.TempDir()
+	config.CommonOptions.CachePolicy = config.CachePolicyAll
+
+	src := &countingStore{Store: memory.New(), fetched: make(map[string]int)}
+	cached := ocitest.PushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("cached layer"), map[string]string{ocispec.AnnotationTitle: "components/cached.tar"})
+	pulled := ocitest.PushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("pulled layer"), map[string]string{ocispec.AnnotationTitle: "components/pulled.tar"})
+	root, err := oras.PackManifest(ctx, src, oras.PackManifestVersion1_1, "application/vnd.test", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{cached, pulled},
+	})
+	require.NoError(t, err)
+	require.NoError(t, src.Tag(ctx, root, "test"))
+	require.NoError(t, cache.AddFrom(strings.NewReader("cached layer"), cached.Digest.Encoded()))
+
+	dst := memory.New()
+	_, err = oras.Copy(ctx, src, "test", dst, "", WithCache(oras.DefaultCopyOptions, dst))
+	require.NoError(t, err)
+
+	for _, layer := range []ocispec.Descriptor{cached, pulled} {
+		exists, err := dst.Exists(ctx, layer)
+		require.NoError(t, err)
+		require.True(t, exists, "%s is copied", layer.Annotations[ocispec.AnnotationTitle])
+	}
+	require.Zero(t, src.fetched[cached.Digest.Encoded()], "the cached layer is copied from the cache")
+	require.Equal(t, 1, src.fetched[pulled.Digest.Encoded()])
+	require.True(t, cache.Exists(pulled.Digest.Encoded()), "the pulled layer is cached")
+	require.False(t, cache.Exists(root.Digest.Encoded()), "manifests aren't cached")
+}
@@ -11,8 +11,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
@@ -27,10 +30,12 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	zarfUtils "github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	ocistore "oras.land/oras-go/v2/content/oci"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 )
 
@@ -44,7 +49,8 @@ func ToOCIStore(t any, mediaType string, store *ocistore.Store) (ocispec.Descrip
 	if exists, _ := store.Exists(context.Background(), desc); exists {
 		return desc, nil
 	}
-	if err := store.Push(context.TODO(), desc, bytes.NewReader(b)); err != nil {
+	// the store is shared by packages that are fetched concurrently, so the blob may have been pushed since it was checked
+	if err := store.Push(context.TODO(), desc, bytes.NewReader(b)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
 		return ocispec.Descriptor{}, err
 	}
 	return desc, nil
@@ -77,6 +83,28 @@ func ToOCIRemote(t any, mediaType string, remote *oci.OrasRemote) (*ocispec.Desc
 	return layerDesc, nil
 }
 
+// AttachReferrer pushes a blob to the target as the single layer of an OCI referrer of the subject
+func AttachReferrer(ctx context.Context, target oras.Target, subject ocispec.Descriptor, artifactType, mediaType string, blob []byte) (ocispec.Descriptor, error) {
+	blobDesc := content.NewDescriptorFromBytes(mediaType, blob)
+	exists, err := target.Exists(ctx, blobDesc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !exists {
+		if err := target.Push(ctx, blobDesc, bytes.NewReader(blob)); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	// only the subject's digest, size and media type are needed for the referrer
+	subject = ocispec.Descriptor{MediaType: subject.MediaType, Digest: subject.Digest, Size: subject.Size}
+	packOpts := oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []ocispec.Descriptor{blobDesc},
+	}
+	return oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, artifactType, packOpts)
+}
+
 // CreateCopyOpts creates the ORAS CopyOpts struct to use when copying OCI artifacts
 func CreateCopyOpts(layersToPull []ocispec.Descriptor, concurrency int) oras.CopyOptions {
 	var copyOpts oras.CopyOptions
@@ -199,10 +227,9 @@ func pushIndex(index *ocispec.Index, remote *oci.OrasRemote, ref string) error {
 	return nil
 }
 
-// UpdateIndex updates or creates a new OCI index based on the index arg, then pushes to the remote OCI repo
-func UpdateIndex(index *ocispec.Index, remote *oci.OrasRemote, bundle *types.UDSBundle, newManifestDesc ocispec.Descriptor) error {
+// UpdateIndex updates or creates a new OCI index based on the index arg, then pushes it to ref in the remote OCI repo
+func UpdateIndex(index *ocispec.Index, remote *oci.OrasRemote, bundle *types.UDSBundle, newManifestDesc ocispec.Descriptor, ref string) error {
 	var newIndex *ocispec.Index
-	ref := bundle.Metadata.Version
 	if index == nil {
 		newIndex = createIndex(bundle, newManifestDesc)
 	} else {
@@ -245,6 +272,28 @@ func GetIndex(remote *oci.OrasRemote, ref string) (*ocispec.Index, error) {
 	return index, nil
 }
 
+// SelectRootManifest selects the bundle root manifest for the given arch from a bundle's OCI index
+func SelectRootManifest(index ocispec.Index, arch string) (ocispec.Descriptor, error) {
+	if len(index.Manifests) == 0 {
+		return ocispec.Descriptor{}, errors.New("no bundle root manifests found in index")
+	}
+	// single-arch local bundles don't set a platform in their index.json
+	if len(index.Manifests) == 1 && index.Manifests[0].Platform == nil {
+		return index.Manifests[0], nil
+	}
+	var archs []string
+	for _, manifest := range index.Manifests {
+		if manifest.Platform == nil {
+			continue
+		}
+		if manifest.Platform.Architecture == arch {
+			return manifest, nil
+		}
+		archs = append(archs, manifest.Platform.Architecture)
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("bundle does not contain architecture %s, available architectures: %s", arch, strings.Join(archs, ", "))
+}
+
 // EnsureOCIPrefix ensures oci prefix is part of provided remote source path, and adds it if it's not
 func EnsureOCIPrefix(source string) string {
 	var ociPrefix = "oci://"
@@ -399,10 +448,11 @@ func FindBundledPkgLayers(ctx context.Context, pkg types.Package, rootManifest *
 	return layersToPull, estPkgBytes, nil
 }
 
-// CopyLayers uses ORAS to copy layers from a remote repo to a local OCI store
+// CopyLayers uses ORAS to copy layers from a remote repo to a local OCI store, copying cached layers from the cache
+// and caching the layers that are pulled
 func CopyLayers(layersToPull []ocispec.Descriptor, estimatedBytes int64, tmpDstDir string, repo *remote.Repository, target oras.Target, artifactName string) (ocispec.Descriptor, error) {
 	// copy Zarf pkg
-	copyOpts := CreateCopyOpts(layersToPull, config.CommonOptions.OCIConcurrency)
+	copyOpts := WithCache(CreateCopyOpts(layersToPull, config.CommonOptions.OCIConcurrency), target)
 	// Create a thread to update a progress bar as we save the package to disk
 	doneSaving := make(chan error)
 
@@ -429,6 +479,118 @@ func CopyLayers(layersToPull []ocispec.Descriptor, estimatedBytes int64, tmpDstD
 	return rootDesc, nil
 }
 
+// partialBlobSuffix is the suffix of blobs that haven't been completely pulled yet
+const partialBlobSuffix = ".partial"
+
+// PullLayers pulls layers into the blobs dir of dstDir, resuming any blobs that were partially pulled by an earlier
+// attempt. Blobs are only moved into the blobs dir once their digest is verified, so an interrupted pull never leaves
+// a corrupt blob behind and layers that are already in the blobs dir are skipped
+func PullLayers(ctx context.Context, layers []ocispec.Descriptor, dstDir string, repo *remote.Repository, artifactName string) error {
+	blobsDir := filepath.Join(dstDir, config.BlobsDir)
+	if err := helpers.CreateDirectory(blobsDir, 0o700); err != nil {
+		return err
+	}
+
+	// the progress bar is based on the size of dstDir, which already includes any partially pulled blobs
+	dstDirSize, err := helpers.GetDirSize(dstDir)
+	if err != nil {
+		return err
+	}
+	expectedTotalSize := dstDirSize
+	seen := make(map[string]bool)
+	var toPull []ocispec.Descriptor
+	for _, layer := range layers {
+		if seen[layer.Digest.String()] {
+			continue
+		}
+		seen[layer.Digest.String()] = true
+		toPull = append(toPull, layer)
+		expectedTotalSize += layer.Size
+		if info, err := os.Stat(filepath.Join(blobsDir, layer.Digest.Encoded()+partialBlobSuffix)); err == nil {
+			expectedTotalSize -= info.Size()
+		}
+	}
+
+	doneSaving := make(chan error)
+	go zarfUtils.RenderProgressBarForLocalDirWrite(dstDir, expectedTotalSize, doneSaving, "Pulling: "+artifactName, "Successfully pulled: "+artifactName)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	if config.CommonOptions.OCIConcurrency > 0 {
+		g.SetLimit(config.CommonOptions.OCIConcurrency)
+	}
+	for _, layer := range toPull {
+		g.Go(func() error {
+			return pullBlob(gCtx, repo, layer, blobsDir)
+		})
+	}
+	err = g.Wait()
+
+	doneSaving <- err
+	<-doneSaving
+	return err
+}
+
+// pullBlob pulls a blob into blobsDir, continuing from where a partially pulled blob left off if the registry supports
+// range requests and verifying the digest of the whole blob before moving it into place
+func pullBlob(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, blobsDir string) error {
+	dst := filepath.Join(blobsDir, desc.Digest.Encoded())
+	if info, err := os.Stat(dst); err == nil && info.Size() == desc.Size {
+		return nil
+	}
+
+	partial := dst + partialBlobSuffix
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_RDWR, helpers.ReadWriteUser)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// hash what was already pulled so that the whole blob is verified
+	verifier := desc.Digest.Verifier()
+	offset, err := io.Copy(verifier, f)
+	if err != nil {
+		return err
+	}
+
+	rc, err := repo.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if offset > 0 {
+		seeker, ok := rc.(io.Seeker)
+		if offset < desc.Size && ok {
+			_, err = seeker.Seek(offset, io.SeekStart)
+		}
+		if offset >= desc.Size || !ok || err != nil {
+			message.Debugf("Unable to resume pulling %s, starting over", desc.Digest)
+			if err := f.Truncate(0); err != nil {
+				return err
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			verifier = desc.Digest.Verifier()
+			offset = 0
+		}
+	}
+
+	n, err := io.Copy(io.MultiWriter(f, verifier), io.LimitReader(rc, desc.Size-offset))
+	if err != nil {
+		// keep the partial blob so that the next attempt can resume it
+		return fmt.Errorf("unable to pull %s: %w", desc.Digest, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if offset+n != desc.Size || !verifier.Verified() {
+		_ = os.Remove(partial)
+		return fmt.Errorf("%s failed verification after it was pulled", desc.Digest)
+	}
+	return os.Rename(partial, dst)
+}
+
 func getImgManifest(ctx context.Context, remote *oci.OrasRemote, desc ocispec.Descriptor) (ocispec.Manifest, error) {
 	imgManifestReader, err := remote.Repo().Blobs().Fetch(ctx, desc)
 	if err != nil {
@@ -488,3 +650,187 @@ func getFilteredComponents(ctx context.Context, remote *oci.OrasRemote, manifest
 	}
 	return filteredComponents, nil
 }
+
+// CopyBundle copies a bundle's index or root manifest, its Zarf package manifests and every blob the bundle contains
+// from one repository to another and tags it as dstRef, along with any signatures or other referrers of the bundle's
+// root manifests. Blobs are mounted instead of copied when both repositories are in the same registry
+func CopyBundle(ctx context.Context, src, dst *remote.Repository, srcRef, dstRef string, concurrency int, onCopied func(ocispec.Descriptor)) (ocispec.Descriptor, error) {
+	root, err := src.Resolve(ctx, srcRef)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// Zarf package manifests are pushed as blobs so the default graph walk doesn't descend into them, track the
+	// untitled layers of each root manifest so their config and layers are copied too
+	var pkgManifests sync.Map
+	var rootManifests []ocispec.Descriptor
+	var mu sync.Mutex
+
+	copyOpts := oras.CopyOptions{}
+	copyOpts.Concurrency = concurrency
+	copyOpts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if _, ok := pkgManifests.Load(desc.Digest); ok {
+			b, err := content.FetchAll(ctx, fetcher, desc)
+			if err != nil {
+				return nil, err
+			}
+			var manifest ocispec.Manifest
+			if err := json.Unmarshal(b, &manifest); err != nil {
+				return nil, err
+			}
+			// bundles only contain the layers of a package's selected components
+			successors := []ocispec.Descriptor{manifest.Config}
+			for _, layer := range manifest.Layers {
+				exists, err := src.Exists(ctx, layer)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					successors = append(successors, layer)
+				}
+			}
+			return successors, nil
+		}
+
+		successors, err := content.Successors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType == ocispec.MediaTypeImageManifest {
+			mu.Lock()
+			rootManifests = append(rootManifests, desc)
+			mu.Unlock()
+			b, err := content.FetchAll(ctx, fetcher, desc)
+			if err != nil {
+				return nil, err
+			}
+			var manifest ocispec.Manifest
+			if err := json.Unmarshal(b, &manifest); err != nil {
+				return nil, err
+			}
+			for _, layer := range manifest.Layers {
+				if _, ok := layer.Annotations[ocispec.AnnotationTitle]; !ok {
+					pkgManifests.Store(layer.Digest, struct{}{})
+				}
+			}
+		}
+		return successors, nil
+	}
+	if src.Reference.Registry == dst.Reference.Registry && src.Reference.Repository != dst.Reference.Repository {
+		copyOpts.MountFrom = func(_ context.Context, _ ocispec.Descriptor) ([]string, error) {
+			return []string{src.Reference.Repository}, nil
+		}
+	}
+	if onCopied != nil {
+		copyOpts.PostCopy = func(_ context.Context, desc ocispec.Descriptor) error {
+			onCopied(desc)
+			return nil
+		}
+		copyOpts.OnMounted = copyOpts.PostCopy
+		copyOpts.OnCopySkipped = copyOpts.PostCopy
+	}
+
+	desc, err := oras.Copy(ctx, src, root.Digest.String(), dst, dstRef, copyOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// copy the referrers of each root manifest, their subject has already been copied so only they are pushed
+	referrerOpts := copyOpts.CopyGraphOptions
+	referrerOpts.FindSuccessors = nil
+	for _, rootManifest := range rootManifests {
+		referrers, err := registry.Referrers(ctx, src, rootManifest, "")
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("unable to list referrers of %s: %w", rootManifest.Digest, err)
+		}
+		for _, referrer := range referrers {
+			if err := oras.CopyGraph(ctx, src, dst, referrer, referrerOpts); err != nil {
+				return ocispec.Descriptor{}, fmt.Errorf("unable to copy referrer %s: %w", referrer.Digest, err)
+			}
+		}
+	}
+	return desc, nil
+}
+
+// ErrTagConflict is returned when a tag already points to a different bundle and overwriting it isn't allowed
+var ErrTagConflict = errors.New("tag already points to a different digest")
+
+// CheckOverwrite returns ErrTagConflict if the tag already points to content other than desc. When desc is a bundle
+// root manifest and the tag points to an index, the index is only a conflict if it holds a different root manifest
+// for desc's architecture, so that other architectures can still be added to a multi-arch bundle
+func CheckOverwrite(ctx context.Context, repo *remote.Repository, tag string, desc ocispec.Descriptor) error {
+	existing, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.Digest == desc.Digest {
+		return nil
+	}
+	if existing.MediaType == ocispec.MediaTypeImageIndex && desc.MediaType != ocispec.MediaTypeImageIndex && desc.Platform != nil {
+		indexBytes, err := content.FetchAll(ctx, repo, existing)
+		if err != nil {
+			return err
+		}
+		var index ocispec.Index
+		if err := json.Unmarshal(indexBytes, &index); err != nil {
+			return err
+		}
+		for _, manifest := range index.Manifests {
+			if manifest.Platform != nil && manifest.Platform.Architecture == desc.Platform.Architecture && manifest.Digest != desc.Digest {
+				return fmt.Errorf("%w: %s:%s holds %s root manifest %s", ErrTagConflict, repo.Reference.Repository, tag, desc.Platform.Architecture, manifest.Digest)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s:%s points to %s", ErrTagConflict, repo.Reference.Repository, tag, existing.Digest)
+}
+
+// CheckOverwriteTags returns ErrTagConflict if publishing desc to the tags would overwrite something. The first tag is
+// the bundle's tag and is checked with CheckOverwrite, since desc is merged into each tag's index the other tags may
+// only already point to desc or to whatever the bundle's tag points to
+func CheckOverwriteTags(ctx context.Context, repo *remote.Repository, tags []string, desc ocispec.Descriptor) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := CheckOverwrite(ctx, repo, tags[0], desc); err != nil {
+		return err
+	}
+	bundleDesc, err := repo.Resolve(ctx, tags[0])
+	if err != nil && !errors.Is(err, errdef.ErrNotFound) {
+		return err
+	}
+	for _, tag := range tags[1:] {
+		existing, err := repo.Resolve(ctx, tag)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if existing.Digest == desc.Digest || existing.Digest == bundleDesc.Digest {
+			continue
+		}
+		return fmt.Errorf("%w: %s:%s points to %s, a different index than %s", ErrTagConflict, repo.Reference.Repository, tag, existing.Digest, tags[0])
+	}
+	return nil
+}
+
+// TagAll tags whatever the repo's reference points to with each of the tags
+func TagAll(ctx context.Context, repo *remote.Repository, tags []string) error {
+	desc, err := repo.Resolve(ctx, repo.Reference.Reference)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if tag == repo.Reference.Reference {
+			continue
+		}
+		if err := repo.Tag(ctx, desc, tag); err != nil {
+			return fmt.Errorf("unable to tag %s as %s: %w", repo.Reference, tag, err)
+		}
+	}
+	return nil
+}
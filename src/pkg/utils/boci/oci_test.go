@@ -0,0 +1,258 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/ocitest"
+	"github.com/google/go-containerregistry/pkg/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2/content"
+	orasRegistry "oras.land/oras-go/v2/registry"
+)
+
+func TestCopyBundle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// sameRegistry copies between two repos of the same registry so that blobs are mounted
+		sameRegistry bool
+	}{
+		{name: "between registries"},
+		{name: "within a registry", sameRegistry: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcHost := ocitest.NewRegistry(t)
+			dstHost := srcHost
+			if !tt.sameRegistry {
+				dstHost = ocitest.NewRegistry(t)
+			}
+			src := ocitest.NewRepo(t, srcHost, "ci/bundle")
+			dst := ocitest.NewRepo(t, dstHost, "release/bundle")
+			b := ocitest.PushBundle(t, src, ocitest.BundleOptions{})
+			indexDesc := ocitest.PushIndex(t, src, "0.0.1", b.Root)
+			_, err := AttachReferrer(ctx, src, b.Root, "application/vnd.test.signature", "application/vnd.test.signature", []byte("signature"))
+			require.NoError(t, err)
+
+			copied := 0
+			desc, err := CopyBundle(ctx, src, dst, "0.0.1", "0.0.1", 3, func(ocispec.Descriptor) { copied++ })
+			require.NoError(t, err)
+			require.Equal(t, indexDesc.Digest, desc.Digest)
+			require.Positive(t, copied)
+
+			// the index is copied as is so the bundle keeps its digest
+			resolved, err := dst.Resolve(ctx, "0.0.1")
+			require.NoError(t, err)
+			require.Equal(t, indexDesc.Digest, resolved.Digest)
+
+			for _, blob := range b.Blobs() {
+				exists, err := dst.Exists(ctx, blob)
+				require.NoError(t, err)
+				require.True(t, exists, "blob %s wasn't copied", blob.Digest)
+			}
+			exists, err := dst.Exists(ctx, b.Optional)
+			require.NoError(t, err)
+			require.False(t, exists)
+
+			// the signature is copied as a referrer of the root manifest
+			indexBytes, err := content.FetchAll(ctx, dst, resolved)
+			require.NoError(t, err)
+			var index ocispec.Index
+			require.NoError(t, json.Unmarshal(indexBytes, &index))
+			referrers, err := orasRegistry.Referrers(ctx, dst, index.Manifests[0], "application/vnd.test.signature")
+			require.NoError(t, err)
+			require.Len(t, referrers, 1)
+
+			// the bundle can be re-tagged in the destination
+			desc, err = CopyBundle(ctx, src, dst, "0.0.1", "latest", 3, nil)
+			require.NoError(t, err)
+			require.Equal(t, indexDesc.Digest, desc.Digest)
+			resolved, err = dst.Resolve(ctx, "latest")
+			require.NoError(t, err)
+			require.Equal(t, indexDesc.Digest, resolved.Digest)
+		})
+	}
+
+	t.Run("missing source", func(t *testing.T) {
+		host := ocitest.NewRegistry(t)
+		_, err := CopyBundle(ctx, ocitest.NewRepo(t, host, "ci/bundle"), ocitest.NewRepo(t, host, "release/bundle"), "0.0.1", "0.0.1", 3, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestCheckOverwrite(t *testing.T) {
+	ctx := context.Background()
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "release/bundle")
+
+	pushRoot := func(arch string) ocispec.Descriptor {
+		return ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: arch}).Root
+	}
+	amd64 := pushRoot("amd64")
+	arm64 := pushRoot("arm64")
+	rebuiltAMD64 := pushRoot("amd64-rebuilt")
+	rebuiltAMD64.Platform.Architecture = "amd64"
+
+	indexDesc := ocitest.PushIndex(t, repo, "0.0.1", amd64)
+	require.NoError(t, repo.Tag(ctx, amd64, "manifest"))
+
+	tests := []struct {
+		name     string
+		tag      string
+		desc     ocispec.Descriptor
+		conflict bool
+	}{
+		{name: "new tag", tag: "0.0.2", desc: amd64},
+		{name: "same index", tag: "0.0.1", desc: indexDesc},
+		{name: "same root manifest in index", tag: "0.0.1", desc: amd64},
+		{name: "new architecture in index", tag: "0.0.1", desc: arm64},
+		{name: "different root manifest in index", tag: "0.0.1", desc: rebuiltAMD64, conflict: true},
+		{name: "different index", tag: "0.0.1", desc: content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, []byte("{}")), conflict: true},
+		{name: "same manifest", tag: "manifest", desc: amd64},
+		{name: "different manifest", tag: "manifest", desc: arm64, conflict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOverwrite(ctx, repo, tt.tag, tt.desc)
+			if tt.conflict {
+				require.ErrorIs(t, err, ErrTagConflict)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckOverwriteTags(t *testing.T) {
+	ctx := context.Background()
+	repo := ocitest.NewRepo(t, ocitest.NewRegistry(t), "release/bundle")
+
+	pushRoot := func(arch string) ocispec.Descriptor {
+		return ocitest.PushBundle(t, repo, ocitest.BundleOptions{Arch: arch}).Root
+	}
+	amd64 := pushRoot("amd64")
+	arm64 := pushRoot("arm64")
+	oldAMD64 := pushRoot("amd64-old")
+	oldAMD64.Platform.Architecture = "amd64"
+	ocitest.PushIndex(t, repo, "0.0.2", amd64)
+	ocitest.PushIndex(t, repo, "latest", amd64)
+	ocitest.PushIndex(t, repo, "0.0.1", oldAMD64)
+	require.NoError(t, repo.Tag(ctx, arm64, "arm64"))
+
+	tests := []struct {
+		name     string
+		tags     []string
+		conflict bool
+	}{
+		{name: "new tags", tags: []string{"0.0.3", "stable"}},
+		{name: "new architecture with a new tag", tags: []string{"0.0.2", "stable"}},
+		// 0.0.1 holds a different index than 0.0.2, even though both would accept an arm64 root manifest
+		{name: "tag holds a different index", tags: []string{"0.0.2", "0.0.1"}, conflict: true},
+		{name: "new bundle tag with an existing tag", tags: []string{"0.0.3", "latest"}, conflict: true},
+		{name: "tag already points to the root manifest", tags: []string{"0.0.3", "arm64"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOverwriteTags(ctx, repo, tt.tags, arm64)
+			if tt.conflict {
+				require.ErrorIs(t, err, ErrTagConflict)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTagAll(t *testing.T) {
+	ctx := context.Background()
+	host := ocitest.NewRegistry(t)
+	repo := ocitest.NewRepo(t, host, "release/bundle")
+	indexDesc := ocitest.PushIndex(t, repo, "0.0.1", ocitest.PushBundle(t, repo, ocitest.BundleOptions{}).Root)
+
+	repo = ocitest.NewRepo(t, host, "release/bundle:0.0.1")
+	require.NoError(t, TagAll(ctx, repo, []string{"0.0.1", "latest", "0.0"}))
+	for _, tag := range []string{"latest", "0.0"} {
+		desc, err := repo.Resolve(ctx, tag)
+		require.NoError(t, err)
+		require.Equal(t, indexDesc.Digest, desc.Digest)
+	}
+}
+
+func TestPullLayers(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// rangeRequests advertises range request support so that partially pulled blobs are resumed instead of
+		// started over
+		rangeRequests bool
+	}{
+		{name: "resume partial blobs", rangeRequests: true},
+		{name: "start partial blobs over"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.rangeRequests {
+					w.Header().Set("Accept-Ranges", "bytes")
+				}
+				handler.ServeHTTP(w, r)
+			}))
+			t.Cleanup(server.Close)
+			repo := ocitest.NewRepo(t, strings.TrimPrefix(server.URL, "http://"), "release/bundle")
+			b := ocitest.PushBundle(t, repo, ocitest.BundleOptions{})
+			blobs := b.Blobs()
+
+			dstDir := t.TempDir()
+			blobsDir := filepath.Join(dstDir, config.BlobsDir)
+			require.NoError(t, os.MkdirAll(blobsDir, 0o700))
+			readBlob := func(desc ocispec.Descriptor) []byte {
+				b, err := content.FetchAll(ctx, repo, desc)
+				require.NoError(t, err)
+				return b
+			}
+			pkgConfig, zarfYAML, component := b.PkgConfig, b.ZarfYAML, b.Component
+
+			// blobs that were already pulled and verified are skipped
+			require.NoError(t, os.WriteFile(filepath.Join(blobsDir, pkgConfig.Digest.Encoded()), readBlob(pkgConfig), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(blobsDir, component.Digest.Encoded()+partialBlobSuffix), readBlob(component)[:3], 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(blobsDir, zarfYAML.Digest.Encoded()+partialBlobSuffix), []byte("corrupt"), 0o600))
+
+			err := PullLayers(ctx, blobs, dstDir, repo, "bundle")
+			if tt.rangeRequests {
+				// resuming the corrupt partial blob fails verification, it's removed so the next attempt starts it over
+				require.ErrorContains(t, err, zarfYAML.Digest.String()+" failed verification")
+				require.NoFileExists(t, filepath.Join(blobsDir, zarfYAML.Digest.Encoded()+partialBlobSuffix))
+				err = PullLayers(ctx, blobs, dstDir, repo, "bundle")
+			}
+			require.NoError(t, err)
+
+			for _, blob := range blobs {
+				got, err := os.ReadFile(filepath.Join(blobsDir, blob.Digest.Encoded()))
+				require.NoError(t, err)
+				require.Equal(t, readBlob(blob), got)
+				require.NoFileExists(t, filepath.Join(blobsDir, blob.Digest.Encoded()+partialBlobSuffix))
+			}
+
+			missing := content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("missing"))
+			require.Error(t, PullLayers(ctx, []ocispec.Descriptor{missing}, dstDir, repo, "bundle"))
+		})
+	}
+}
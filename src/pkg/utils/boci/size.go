@@ -0,0 +1,107 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package boci contains common functions used by local and remote bundles for interacting with OCI
+package boci
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// PackageLayers are the layers a Zarf package has in a bundle
+type PackageLayers struct {
+	Name   string
+	Layers []ocispec.Descriptor
+}
+
+// PackageSize is how much of a bundle's size a Zarf package accounts for
+type PackageSize struct {
+	Name string
+	// Size is the size of all of the package's layers
+	Size int64
+	// Unique is the size of the package's layers that no other package in the bundle has
+	Unique int64
+	// Shared is the size of the package's layers that other packages in the bundle also have
+	Shared int64
+}
+
+// PackageSizes returns the size of each package's layers split into the bytes it contributes uniquely and the bytes it
+// shares with other packages, along with the total size of the packages' layers once each digest is stored only once
+func PackageSizes(pkgs []PackageLayers) ([]PackageSize, int64) {
+	// count the packages that have each digest, a package listing a digest more than once only counts once
+	pkgCounts := make(map[string]int)
+	sizes := make(map[string]int64)
+	pkgDigests := make([]map[string]bool, len(pkgs))
+	for i, pkg := range pkgs {
+		pkgDigests[i] = make(map[string]bool)
+		for _, layer := range pkg.Layers {
+			digest := layer.Digest.String()
+			if pkgDigests[i][digest] {
+				continue
+			}
+			pkgDigests[i][digest] = true
+			pkgCounts[digest]++
+			sizes[digest] = layer.Size
+		}
+	}
+
+	pkgSizes := make([]PackageSize, 0, len(pkgs))
+	for i, pkg := range pkgs {
+		pkgSize := PackageSize{Name: pkg.Name}
+		for digest := range pkgDigests[i] {
+			pkgSize.Size += sizes[digest]
+			if pkgCounts[digest] > 1 {
+				pkgSize.Shared += sizes[digest]
+			} else {
+				pkgSize.Unique += sizes[digest]
+			}
+		}
+		pkgSizes = append(pkgSizes, pkgSize)
+	}
+
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	return pkgSizes, total
+}
+
+// UncompressedSize returns the size of a blob's contents once decompressed, blobs that aren't gzip or zstd compressed,
+// such as Zarf component tarballs, are returned as is
+func UncompressedSize(r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	var decompressed io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, err
+		}
+		defer gr.Close()
+		decompressed = gr
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return 0, err
+		}
+		defer zr.Close()
+		decompressed = zr
+	}
+	return io.Copy(io.Discard, decompressed)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package boci contains common functions used by local and remote bundles for interacting with OCI
+package boci
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageSizes(t *testing.T) {
+	layer := func(s string, size int64) ocispec.Descriptor {
+		return ocispec.Descriptor{Digest: digest.FromString(s), Size: size}
+	}
+	pkgs := []PackageLayers{
+		// a package listing the same layer twice only counts it once
+		{Name: "a", Layers: []ocispec.Descriptor{layer("a", 10), layer("shared", 100), layer("shared", 100)}},
+		{Name: "b", Layers: []ocispec.Descriptor{layer("b", 20), layer("shared", 100)}},
+		{Name: "c", Layers: []ocispec.Descriptor{layer("c", 30)}},
+	}
+
+	sizes, total := PackageSizes(pkgs)
+	require.Equal(t, []PackageSize{
+		{Name: "a", Size: 110, Unique: 10, Shared: 100},
+		{Name: "b", Size: 120, Unique: 20, Shared: 100},
+		{Name: "c", Size: 30, Unique: 30},
+	}, sizes)
+	require.Equal(t, int64(160), total)
+}
+
+func TestUncompressedSize(t *testing.T) {
+	data := bytes.Repeat([]byte("uds"), 1000)
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zst := zw.EncodeAll(data, nil)
+	require.NoError(t, zw.Close())
+
+	tests := []struct {
+		name string
+		blob []byte
+		want int64
+	}{
+		{name: "gzip", blob: gz.Bytes(), want: int64(len(data))},
+		{name: "zstd", blob: zst, want: int64(len(data))},
+		{name: "uncompressed", blob: data, want: int64(len(data))},
+		{name: "shorter than the magic bytes", blob: []byte("a"), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := UncompressedSize(bytes.NewReader(tt.blob))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, size)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package ocitest provides OCI registries and bundle fixtures for unit tests
+package ocitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/google/go-containerregistry/pkg/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/zoci"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// NewRegistry starts an in-memory OCI registry that is closed when the test ends, and returns its host
+func NewRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// NewRepo returns a client for a repository of a registry started by NewRegistry
+func NewRepo(t *testing.T, host, name string) *remote.Repository {
+	t.Helper()
+	repo, err := remote.NewRepository(host + "/" + name)
+	require.NoError(t, err)
+	repo.PlainHTTP = true
+	return repo
+}
+
+// PushBlob pushes b to target and returns its descriptor along with the given annotations
+func PushBlob(t *testing.T, target content.Pusher, mediaType string, b []byte, annotations map[string]string) ocispec.Descriptor {
+	t.Helper()
+	desc := content.NewDescriptorFromBytes(mediaType, b)
+	require.NoError(t, target.Push(context.Background(), desc, bytes.NewReader(b)))
+	desc.Annotations = annotations
+	return desc
+}
+
+// PushIndex pushes an index of manifests to target and tags it
+func PushIndex(t *testing.T, target oras.Target, tag string, manifests ...ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	index := ocispec.Index{MediaType: ocispec.MediaTypeImageIndex, Manifests: manifests}
+	index.SchemaVersion = 2
+	indexBytes, err := json.Marshal(index)
+	require.NoError(t, err)
+	desc := PushBlob(t, target, ocispec.MediaTypeImageIndex, indexBytes, nil)
+	require.NoError(t, target.Tag(context.Background(), desc, tag))
+	return desc
+}
+
+// BundleOptions configures the bundle pushed by PushBundle
+type BundleOptions struct {
+	// Arch is the bundle's architecture, amd64 if empty
+	Arch string
+	// BundleYAML makes the bundle's uds-bundle.yaml from the descriptor of its package's manifest, if nil the
+	// uds-bundle.yaml names the bundle example at version 0.0.1
+	BundleYAML func(pkgManifest ocispec.Descriptor) []byte
+}
+
+// Bundle is a bundle root manifest pushed by PushBundle. It holds a single Zarf package with a required component and
+// an optional component that wasn't bundled
+type Bundle struct {
+	// Root is the root manifest's descriptor, with its platform set as it is in a bundle's index
+	Root        ocispec.Descriptor
+	Config      ocispec.Descriptor
+	BundleYAML  ocispec.Descriptor
+	PkgManifest ocispec.Descriptor
+	PkgConfig   ocispec.Descriptor
+	ZarfYAML    ocispec.Descriptor
+	Component   ocispec.Descriptor
+	// Optional is the layer of the optional component, it isn't pushed
+	Optional ocispec.Descriptor
+}
+
+// Blobs returns the blobs of the bundle that were pushed, other than its root manifest
+func (b Bundle) Blobs() []ocispec.Descriptor {
+	return []ocispec.Descriptor{b.Config, b.BundleYAML, b.PkgManifest, b.PkgConfig, b.ZarfYAML, b.Component}
+}
+
+// Size returns the size of the bundle's root manifest and of the blobs that were pushed
+func (b Bundle) Size() int64 {
+	size := b.Root.Size
+	for _, blob := range b.Blobs() {
+		size += blob.Size
+	}
+	return size
+}
+
+// PushBundle pushes a bundle root manifest and its blobs to target, the blobs of bundles of different architectures
+// are distinct
+func PushBundle(t *testing.T, target oras.Target, opts BundleOptions) Bundle {
+	t.Helper()
+	ctx := context.Background()
+	arch := opts.Arch
+	if arch == "" {
+		arch = "amd64"
+	}
+	var b Bundle
+
+	b.PkgConfig = PushBlob(t, target, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"`+arch+`"}`), nil)
+	b.ZarfYAML = PushBlob(t, target, zoci.ZarfLayerMediaTypeBlob, []byte("kind: ZarfPackageConfig\nmetadata:\n  name: nginx\n  architecture: "+arch+"\n"), map[string]string{ocispec.AnnotationTitle: config.ZarfYAML})
+	b.Component = PushBlob(t, target, zoci.ZarfLayerMediaTypeBlob, []byte("component-"+arch), map[string]string{ocispec.AnnotationTitle: "components/required.tar"})
+	b.Optional = content.NewDescriptorFromBytes(zoci.ZarfLayerMediaTypeBlob, []byte("optional-"+arch))
+	b.Optional.Annotations = map[string]string{ocispec.AnnotationTitle: "components/optional.tar"}
+	pkgManifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    b.PkgConfig,
+		Layers:    []ocispec.Descriptor{b.ZarfYAML, b.Component, b.Optional},
+	}
+	pkgManifest.SchemaVersion = 2
+	pkgManifestBytes, err := json.Marshal(pkgManifest)
+	require.NoError(t, err)
+	b.PkgManifest = PushBlob(t, target, zoci.ZarfLayerMediaTypeBlob, pkgManifestBytes, nil)
+
+	bundleYAML := []byte("kind: UDSBundle\nmetadata:\n  name: example\n  version: 0.0.1\n  architecture: " + arch + "\n")
+	if opts.BundleYAML != nil {
+		bundleYAML = opts.BundleYAML(b.PkgManifest)
+	}
+	b.BundleYAML = PushBlob(t, target, zoci.ZarfLayerMediaTypeBlob, bundleYAML, map[string]string{ocispec.AnnotationTitle: config.BundleYAML})
+	b.Config = PushBlob(t, target, zoci.ZarfLayerMediaTypeBlob, []byte(`{"architecture":"`+arch+`","ociVersion":"1.0.1"}`), nil)
+	b.Root, err = oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, "application/vnd.test.bundle", oras.PackManifestOptions{
+		ConfigDescriptor: &b.Config,
+		Layers:           []ocispec.Descriptor{b.PkgManifest, b.BundleYAML},
+		ManifestAnnotations: map[string]string{
+			ocispec.AnnotationVersion: "0.0.1",
+			ocispec.AnnotationCreated: "2024-06-01T12:00:00Z",
+		},
+	})
+	require.NoError(t, err)
+	b.Root.Annotations = nil
+	b.Root.Platform = &ocispec.Platform{Architecture: arch, OS: "multi"}
+	return b
+}
+
+// NewOCIDir writes a bundle to an OCI layout dir, tagged 0.0.1. Like the single-arch bundles made by create, its root
+// manifest has no platform in the dir's index.json
+func NewOCIDir(t *testing.T, opts BundleOptions) (string, Bundle) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := ocistore.NewWithContext(context.Background(), dir)
+	require.NoError(t, err)
+	b := PushBundle(t, store, opts)
+	b.Root.Platform = nil
+	require.NoError(t, store.Tag(context.Background(), b.Root, "0.0.1"))
+	return dir, b
+}
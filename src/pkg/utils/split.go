@@ -0,0 +1,197 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/message"
+)
+
+// SplitPartSuffix is the suffix of the first part of a split file, which holds the split's manifest instead of data
+const SplitPartSuffix = ".part000"
+
+// splitManifest describes a file split into parts, it is written to the file's .part000
+type splitManifest struct {
+	// Sha256Sum is the sha256sum of the whole file
+	Sha256Sum string `json:"sha256Sum"`
+	// Bytes is the size of the whole file in bytes
+	Bytes int64 `json:"bytes"`
+	// Parts are the file's data parts in order
+	Parts []splitPart `json:"parts"`
+}
+
+// splitPart is a single data part of a split file
+type splitPart struct {
+	Name      string `json:"name"`
+	Sha256Sum string `json:"sha256Sum"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// SplitBundleTarball splits a bundle tarball into parts when it is larger than maxPackageSizeMB, 0 disables splitting
+func SplitBundleTarball(path string, maxPackageSizeMB int) error {
+	if maxPackageSizeMB <= 0 {
+		return nil
+	}
+	parts, err := SplitFile(path, int64(maxPackageSizeMB)*config.MB)
+	if err != nil {
+		return fmt.Errorf("unable to split %s: %w", path, err)
+	}
+	if len(parts) > 1 {
+		message.Infof("Split the bundle tarball into %d parts, use %s in place of the tarball", len(parts)-1, parts[0])
+	}
+	return nil
+}
+
+// SplitFile splits a file into numbered parts of at most partSize bytes, replacing the file with a .part000 manifest of
+// the parts' digests followed by the .part001, .part002 etc. data parts. Files no larger than partSize are left as is.
+// It returns the paths of everything written
+func SplitFile(path string, partSize int64) ([]string, error) {
+	if partSize <= 0 {
+		return nil, errors.New("part size must be greater than 0")
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= partSize {
+		return []string{path}, nil
+	}
+
+	// parts left by an earlier split of the same file would otherwise be mixed in with the new ones
+	staleParts, err := filepath.Glob(path + ".part*")
+	if err != nil {
+		return nil, err
+	}
+	for _, stalePart := range staleParts {
+		if err := os.Remove(stalePart); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := splitManifest{Bytes: info.Size()}
+	fileHash := sha256.New()
+	paths := []string{path + SplitPartSuffix}
+	for i := 1; ; i++ {
+		partPath := fmt.Sprintf("%s.part%03d", path, i)
+		part, err := writePart(io.TeeReader(io.LimitReader(src, partSize), fileHash), partPath)
+		if err != nil {
+			return nil, err
+		}
+		if part.Bytes == 0 {
+			if err := os.Remove(partPath); err != nil {
+				return nil, err
+			}
+			break
+		}
+		manifest.Parts = append(manifest.Parts, part)
+		paths = append(paths, partPath)
+		if part.Bytes < partSize {
+			break
+		}
+	}
+	manifest.Sha256Sum = fmt.Sprintf("%x", fileHash.Sum(nil))
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(paths[0], b, helpers.ReadAllWriteUser); err != nil {
+		return nil, err
+	}
+	if err := src.Close(); err != nil {
+		return nil, err
+	}
+	return paths, os.Remove(path)
+}
+
+// writePart writes a single data part of a split file
+func writePart(r io.Reader, partPath string) (splitPart, error) {
+	out, err := os.OpenFile(partPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, helpers.ReadAllWriteUser)
+	if err != nil {
+		return splitPart{}, err
+	}
+	defer out.Close()
+	partHash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(out, partHash), r)
+	if err != nil {
+		return splitPart{}, err
+	}
+	return splitPart{Name: filepath.Base(partPath), Sha256Sum: fmt.Sprintf("%x", partHash.Sum(nil)), Bytes: written}, out.Close()
+}
+
+// IsSplitFile returns true if the path is the .part000 manifest of a split file
+func IsSplitFile(path string) bool {
+	return strings.HasSuffix(path, SplitPartSuffix) && !helpers.InvalidPath(path) && !helpers.IsDir(path)
+}
+
+// ReassembleSplitFile verifies the parts of a split file against the .part000 manifest at path and joins them into
+// dstDir, returning the path of the reassembled file. The parts are left in place
+func ReassembleSplitFile(path, dstDir string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var manifest splitManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return "", fmt.Errorf("unable to read the split manifest %s: %w", path, err)
+	}
+	if len(manifest.Parts) == 0 {
+		return "", fmt.Errorf("split manifest %s does not list any parts", path)
+	}
+
+	dst := filepath.Join(dstDir, strings.TrimSuffix(filepath.Base(path), SplitPartSuffix))
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	fileHash := sha256.New()
+	for _, part := range manifest.Parts {
+		if err := appendPart(io.MultiWriter(out, fileHash), filepath.Join(filepath.Dir(path), filepath.Base(part.Name)), part); err != nil {
+			return "", err
+		}
+	}
+	if sum := fmt.Sprintf("%x", fileHash.Sum(nil)); sum != manifest.Sha256Sum {
+		return "", fmt.Errorf("reassembled %s has sha256sum %s, expected %s", dst, sum, manifest.Sha256Sum)
+	}
+	return dst, out.Close()
+}
+
+// appendPart copies a single data part of a split file to w, verifying it against the split's manifest
+func appendPart(w io.Writer, partPath string, part splitPart) error {
+	f, err := os.Open(partPath)
+	if err != nil {
+		return fmt.Errorf("split file is missing part %s: %w", part.Name, err)
+	}
+	defer f.Close()
+	partHash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(w, partHash), f)
+	if err != nil {
+		return err
+	}
+	if written != part.Bytes {
+		return fmt.Errorf("part %s is %d bytes, expected %d", part.Name, written, part.Bytes)
+	}
+	if sum := fmt.Sprintf("%x", partHash.Sum(nil)); sum != part.Sha256Sum {
+		return fmt.Errorf("part %s has sha256sum %s, expected %s", part.Name, sum, part.Sha256Sum)
+	}
+	return nil
+}
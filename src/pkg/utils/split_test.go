@@ -0,0 +1,89 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		partSize  int64
+		wantParts int
+	}{
+		{name: "smaller than a part is left as is", size: 10, partSize: 10, wantParts: 0},
+		{name: "uneven final part", size: 25, partSize: 10, wantParts: 3},
+		{name: "exact multiple of the part size", size: 30, partSize: 10, wantParts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "uds-bundle-example-amd64-0.0.1.tar.zst")
+			data := bytes.Repeat([]byte("0123456789abcdef"), tt.size)[:tt.size]
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+			// a part left from an earlier split is removed
+			require.NoError(t, os.WriteFile(path+".part009", []byte("stale"), 0o600))
+
+			paths, err := SplitFile(path, tt.partSize)
+			require.NoError(t, err)
+			if tt.wantParts == 0 {
+				require.Equal(t, []string{path}, paths)
+				return
+			}
+			require.Len(t, paths, tt.wantParts+1)
+			require.Equal(t, path+SplitPartSuffix, paths[0])
+			require.NoFileExists(t, path)
+			require.NoFileExists(t, path+".part009")
+			require.True(t, IsSplitFile(paths[0]))
+			require.True(t, IsValidTarballPath(paths[0]))
+
+			reassembled, err := ReassembleSplitFile(paths[0], t.TempDir())
+			require.NoError(t, err)
+			require.Equal(t, filepath.Base(path), filepath.Base(reassembled))
+			b, err := os.ReadFile(reassembled)
+			require.NoError(t, err)
+			require.Equal(t, data, b)
+		})
+	}
+}
+
+func TestReassembleSplitFile(t *testing.T) {
+	split := func(t *testing.T) []string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "uds-bundle-example-amd64-0.0.1.tar.zst")
+		require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("a"), 25), 0o600))
+		paths, err := SplitFile(path, 10)
+		require.NoError(t, err)
+		return paths
+	}
+
+	t.Run("missing part", func(t *testing.T) {
+		paths := split(t)
+		require.NoError(t, os.Remove(paths[2]))
+		_, err := ReassembleSplitFile(paths[0], t.TempDir())
+		require.ErrorContains(t, err, "missing part")
+	})
+
+	t.Run("corrupt part", func(t *testing.T) {
+		paths := split(t)
+		require.NoError(t, os.WriteFile(paths[1], bytes.Repeat([]byte("b"), 10), 0o600))
+		_, err := ReassembleSplitFile(paths[0], t.TempDir())
+		require.ErrorContains(t, err, "has sha256sum")
+	})
+
+	t.Run("truncated part", func(t *testing.T) {
+		paths := split(t)
+		require.NoError(t, os.WriteFile(paths[3], []byte("a"), 0o600))
+		_, err := ReassembleSplitFile(paths[0], t.TempDir())
+		require.ErrorContains(t, err, "expected 5")
+	})
+}
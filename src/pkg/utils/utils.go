@@ -22,13 +22,14 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/types"
 	goyaml "github.com/goccy/go-yaml"
 	"github.com/mholt/archives"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/message"
 )
 
-// IsValidTarballPath returns true if the path is a valid tarball path to a bundle tarball
+// IsValidTarballPath returns true if the path is a valid tarball path to a bundle tarball, or the first part of a split one
 func IsValidTarballPath(path string) bool {
 	if helpers.InvalidPath(path) || helpers.IsDir(path) {
 		return false
@@ -40,10 +41,23 @@ func IsValidTarballPath(path string) bool {
 	if !strings.HasPrefix(name, config.BundlePrefix) {
 		return false
 	}
-	re := regexp.MustCompile(`^uds-bundle-.*-.*.tar(.zst)?$`)
+	re := regexp.MustCompile(`^uds-bundle-.*-.*.tar(.zst)?(\.part000)?$`)
 	return re.MatchString(name)
 }
 
+// IsValidBundleDir returns true if the path is a directory holding a bundle as an unpacked OCI image layout
+func IsValidBundleDir(path string) bool {
+	if !helpers.IsDir(path) {
+		return false
+	}
+	for _, name := range []string{ocispec.ImageLayoutFile, ocispec.ImageIndexFile} {
+		if helpers.InvalidPath(filepath.Join(path, name)) {
+			return false
+		}
+	}
+	return true
+}
+
 // IncludeComponent checks if a component has been specified in a a list of components (used for filtering optional components)
 func IncludeComponent(componentToCheck string, filteredComponents []v1alpha1.ZarfComponent) bool {
 	for _, component := range filteredComponents {
@@ -6,6 +6,7 @@ package types
 
 import (
 	"github.com/defenseunicorns/uds-cli/src/types/chartvariable"
+	"github.com/defenseunicorns/uds-cli/src/types/pkgsignature"
 	"github.com/defenseunicorns/uds-cli/src/types/valuesources"
 )
 
@@ -24,7 +25,8 @@ type Package struct {
 	Repository         string                                     `json:"repository,omitempty" jsonschema:"description=The repository to import the package from"`
 	Path               string                                     `json:"path,omitempty" jsonschema:"description=The local path to import the package from"`
 	Ref                string                                     `json:"ref" jsonschema:"description=Ref (tag) of the Zarf package"`
-	Flavor             string                                     `json:"flavor,omitempty" jsonschema:"description=Flavor of the Zarf package"`
+	Flavor             string                                     `json:"flavor,omitempty" jsonschema:"description=Flavor of the Zarf package; remote packages are resolved to the <ref>-<flavor> tag if it exists"`
+	Architecture       string                                     `json:"architecture,omitempty" jsonschema:"description=Architecture of the Zarf package to use, defaults to the bundle's architecture"`
 	OptionalComponents []string                                   `json:"optionalComponents,omitempty" jsonschema:"description=List of optional components to include from the package (required components are always included)"`
 	PublicKey          string                                     `json:"publicKey,omitempty" jsonschema:"description=The public key to use to verify the package"`
 	Imports            []BundleVariableImport                     `json:"imports,omitempty" jsonschema:"description=List of Zarf variables to import from another Zarf package"`
@@ -90,4 +92,28 @@ type UDSBuildData struct {
 	Architecture string `json:"architecture" jsonschema:"description=The architecture this package was created on"`
 	Timestamp    string `json:"timestamp" jsonschema:"description=The timestamp when this package was created"`
 	Version      string `json:"version" jsonschema:"description=The version of Zarf used to build this package"`
+	// PackageSignatures maps package names to the result of verifying their signatures during create
+	PackageSignatures map[string]pkgsignature.Status `json:"packageSignatures,omitempty" jsonschema:"description=The result of verifying each package's signature when this bundle was created (verified; unverified or unsigned)"`
+	// DerivedFrom is set on bundles that were pulled with only some of their packages
+	DerivedFrom *DerivedFrom `json:"derivedFrom,omitempty" jsonschema:"description=The bundle this bundle was derived from by pulling only some of its packages"`
+}
+
+// DerivedFrom identifies the bundle a partial bundle was derived from
+type DerivedFrom struct {
+	Source string `json:"source" jsonschema:"description=The OCI reference of the bundle this bundle was derived from"`
+	Digest string `json:"digest" jsonschema:"description=The digest of the root manifest of the bundle this bundle was derived from"`
+}
+
+// BundleDelta describes the bundle tarball a delta bundle rebuilds when applied to the older bundle it was created against
+type BundleDelta struct {
+	// Bundle is the filename of the rebuilt bundle tarball
+	Bundle string `json:"bundle"`
+	// Sha256Sum is the sha256sum of the rebuilt bundle tarball
+	Sha256Sum string `json:"sha256Sum"`
+	// Bytes is the size of the rebuilt bundle tarball in bytes
+	Bytes int64 `json:"bytes"`
+	// From is the filename of the older bundle the delta bundle was created against
+	From string `json:"from"`
+	// BaseBlobs are the digests of the blobs that are read from the older bundle instead of the delta bundle
+	BaseBlobs []string `json:"baseBlobs"`
 }
@@ -6,34 +6,43 @@ package types
 
 // BundleConfig is the main struct that the bundler uses to hold high-level options.
 type BundleConfig struct {
-	CreateOpts    BundleCreateOptions
-	DeployOpts    BundleDeployOptions
-	PublishOpts   BundlePublishOptions
-	PullOpts      BundlePullOptions
-	InspectOpts   BundleInspectOptions
-	RemoveOpts    BundleRemoveOptions
-	DevDeployOpts BundleDevDeployOptions
+	CreateOpts     BundleCreateOptions
+	DeployOpts     BundleDeployOptions
+	PublishOpts    BundlePublishOptions
+	PullOpts       BundlePullOptions
+	InspectOpts    BundleInspectOptions
+	RemoveOpts     BundleRemoveOptions
+	SignOpts       BundleSignOptions
+	VerifyOpts     BundleVerifyOptions
+	ScanOpts       BundleScanOptions
+	RegistryOpts   BundleRegistryOptions
+	DevDeployOpts  BundleDevDeployOptions
+	ApplyDeltaOpts BundleApplyDeltaOptions
 }
 
 // BundleCreateOptions is the options for the bundler.Create() function
 type BundleCreateOptions struct {
 	SourceDirectory    string
 	Output             string
-	SigningKeyPath     string
+	SigningKeyPaths    []string
 	SigningKeyPassword string
 	BundleFile         string
 	Version            string
 	Name               string
+	MaxPackageSizeMB   int
+	Base               string
+	DeltaFrom          string
 }
 
 // BundleDeployOptions is the options for the bundler.Deploy() function
 type BundleDeployOptions struct {
-	Resume        bool
-	Source        string
-	Config        string
-	Packages      []string
-	PublicKeyPath string
-	SetVariables  map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used by Zarf packages in a bundle"`
+	Resume                bool
+	Source                string
+	Config                string
+	Packages              []string
+	PublicKeyPaths        []string
+	RequireSignedPackages bool
+	SetVariables          map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used by Zarf packages in a bundle"`
 	// Variables and SharedVariables are read in from uds-config.yaml
 	Variables       map[string]map[string]interface{} `yaml:"variables,omitempty"`
 	SharedVariables map[string]interface{}            `yaml:"shared,omitempty"`
@@ -43,13 +52,19 @@ type BundleDeployOptions struct {
 
 // BundleInspectOptions is the options for the bundler.Inspect() function
 type BundleInspectOptions struct {
-	PublicKeyPath string
-	Source        string
-	IncludeSBOM   bool
-	ExtractSBOM   bool
-	ListImages    bool
-	ListVariables bool
-	IsYAMLFile    bool
+	PublicKeyPaths []string
+	Source         string
+	IncludeSBOM    bool
+	ExtractSBOM    bool
+	Format         string
+	Licenses       bool
+	DenyLicenses   []string
+	ListImages     bool
+	ListVariables  bool
+	Provenance     bool
+	Size           bool
+	Uncompressed   bool
+	IsYAMLFile     bool
 }
 
 // BundlePublishOptions is the options for the bundle.Publish() function
@@ -57,13 +72,19 @@ type BundlePublishOptions struct {
 	Source      string
 	Destination string
 	Version     string
+	Tags        []string
+	NoOverwrite bool
 }
 
 // BundlePullOptions is the options for the bundler.Pull() function
 type BundlePullOptions struct {
-	OutputDirectory string
-	PublicKeyPath   string
-	Source          string
+	OutputDirectory  string
+	PublicKeyPaths   []string
+	Source           string
+	Resume           bool
+	Packages         []string
+	Format           string
+	MaxPackageSizeMB int
 }
 
 // BundleRemoveOptions is the options for the bundler.Remove() function
@@ -72,13 +93,52 @@ type BundleRemoveOptions struct {
 	Packages []string
 }
 
+// BundleSignOptions is the options for the bundle.Sign() function
+type BundleSignOptions struct {
+	Source             string
+	SigningKeyPaths    []string
+	SigningKeyPassword string
+}
+
+// BundleVerifyOptions is the options for the bundle.Verify() function
+type BundleVerifyOptions struct {
+	Source         string
+	PublicKeyPaths []string
+}
+
+// BundleScanOptions is the options for the bundle.Scan() function
+type BundleScanOptions struct {
+	Source      string
+	Scanner     string
+	ScannerPath string
+	DBPath      string
+	FailOn      string
+	Format      string
+}
+
+// BundleApplyDeltaOptions is the options for the bundle.ApplyDelta() function
+type BundleApplyDeltaOptions struct {
+	Base   string
+	Delta  string
+	Output string
+}
+
+// BundleRegistryOptions are the options for the bundle.RegistryList() and bundle.RegistryRemove() functions
+type BundleRegistryOptions struct {
+	Source string
+	Arch   string
+}
+
 // BundleCommonOptions tracks the user-defined preferences used across commands.
 type BundleCommonOptions struct {
-	Confirm        bool   `json:"confirm" jsonschema:"description=Verify that Zarf should perform an action"`
-	Insecure       bool   `json:"insecure" jsonschema:"description=Allow insecure connections for remote packages"`
-	CachePath      string `json:"cachePath" jsonschema:"description=Path to use to cache images and git repos on package create"`
-	TempDirectory  string `json:"tempDirectory" jsonschema:"description=Location Zarf should use as a staging ground when managing files and images for package creation and deployment"`
-	OCIConcurrency int    `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package"`
+	Confirm        bool     `json:"confirm" jsonschema:"description=Verify that Zarf should perform an action"`
+	Insecure       bool     `json:"insecure" jsonschema:"description=Allow insecure connections for remote packages"`
+	CachePath      string   `json:"cachePath" jsonschema:"description=Path to use to cache images and git repos on package create"`
+	CachePolicy    string   `json:"cachePolicy" jsonschema:"description=Which layers to cache: all; images or none"`
+	TempDirectory  string   `json:"tempDirectory" jsonschema:"description=Location Zarf should use as a staging ground when managing files and images for package creation and deployment"`
+	OCIConcurrency int      `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package"`
+	TrustedKeys    []string `json:"trustedKeys" jsonschema:"description=Public key files; directories of keys or keyrings that are trusted to verify signed bundles"`
+	RequireSigned  bool     `json:"requireSigned" jsonschema:"description=Fail if a bundle is not signed; unsigned bundles are otherwise allowed when only trusted keys are configured"`
 }
 
 // BundleDevDeployOptions are the options for when doing a dev deploy
@@ -0,0 +1,16 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+package pkgsignature
+
+// Status is the result of verifying a Zarf package's signature when a bundle was created
+type Status string
+
+const (
+	// Verified means the package's signature was verified against its public key or a trusted key
+	Verified Status = "verified"
+	// Unverified means the package is signed but no key verified its signature
+	Unverified Status = "unverified"
+	// Unsigned means the package is not signed
+	Unsigned Status = "unsigned"
+)
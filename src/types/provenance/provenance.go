@@ -0,0 +1,78 @@
+// Copyright 2024 Defense Unicorns
+// SPDX-License-Identifier: AGPL-3.0-or-later OR LicenseRef-Defense-Unicorns-Commercial
+
+package provenance
+
+const (
+	// StatementType is the type of an in-toto v1 attestation statement
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of a SLSA v1 provenance predicate
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType describes how uds create builds a bundle from its inputs
+	BuildType = "https://github.com/defenseunicorns/uds-cli/create/v1"
+	// BuilderID identifies UDS CLI as the builder of a bundle
+	BuilderID = "https://github.com/defenseunicorns/uds-cli"
+)
+
+// Statement is an in-toto attestation statement holding a SLSA provenance predicate
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     Predicate            `json:"predicate"`
+}
+
+// ResourceDescriptor describes an artifact by its name, location and digests
+type ResourceDescriptor struct {
+	Name        string            `json:"name,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Predicate is a SLSA v1 provenance predicate
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs that were used to create a bundle
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters"`
+	InternalParameters   InternalParameters   `json:"internalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters are the inputs given to uds create, uds-config.yaml options don't change the bundle so they aren't recorded
+type ExternalParameters struct {
+	BundleFile   string `json:"bundleFile"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Architecture string `json:"architecture"`
+}
+
+// InternalParameters describe the environment uds create ran in
+type InternalParameters struct {
+	Terminal string `json:"terminal,omitempty"`
+	User     string `json:"user,omitempty"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+}
+
+// RunDetails describe the builder that created a bundle and when it ran
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Builder identifies the tool that created a bundle
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// Metadata holds the time a bundle was created
+type Metadata struct {
+	StartedOn string `json:"startedOn,omitempty"`
+}